@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/ekalinin/present2pdf/internal/converter"
 )
@@ -14,12 +16,52 @@ var version = "dev"
 
 func main() {
 	inputFile := flag.String("input", "", "Path to .slide file (required)")
-	outputFile := flag.String("output", "", "Path to output PDF file (optional, defaults to input filename with .pdf extension)")
+	outputFile := flag.String("output", "", "Path to output PDF file (optional, defaults to input filename with .pdf extension); with -input-dir, the output directory")
+	inputDir := flag.String("input-dir", "", "Convert every .slide file in this directory, in parallel, instead of a single -input file")
 	codeTheme := flag.String("code-theme", "monokai", "Code syntax highlighting theme (use -list-code-themes to see available options)")
-	pdfTheme := flag.String("theme", "light", "PDF color theme: light or dark (use -list-themes to see available options)")
+	pdfTheme := flag.String("theme", "light", "PDF color theme: light, dark, or auto to match the code theme (use -list-themes to see available options)")
+	themeFile := flag.String("theme-file", "", "JSON file with custom PDF theme colors; takes precedence over -theme")
+	pageSize := flag.String("page-size", "A4", "Slide page size: A4, 16:9 (widescreen) or 4:3")
 	listCodeThemes := flag.Bool("list-code-themes", false, "List available code syntax highlighting themes and exit")
 	listThemes := flag.Bool("list-themes", false, "List available PDF themes and exit")
+	bodyFont := flag.String("body-font", "helvetica", "Font family of the slide text (use -list-fonts to see available options)")
+	listFonts := flag.Bool("list-fonts", false, "List available body fonts and exit")
 	quiet := flag.Bool("quiet", false, "Suppress diagnostic warnings (slide overflow, code truncation)")
+	verbose := flag.Bool("verbose", false, "Print layout decisions for every slide element (type, height, code language, image scaling)")
+	maxOverflow := flag.Int("max-slide-overflow", -1, "Fail if a slide overflows and more than N elements are cut off (-1 disables the check)")
+	warnThreshold := flag.Float64("overflow-warn-threshold", 0, "Warn about slides whose content uses more than this fraction of the slide height, e.g. 0.8 (0 disables)")
+	subsetFonts := flag.Bool("subset-fonts", false, "Embed only the glyphs used in the document (smaller PDF)")
+	handout := flag.Int("handout", 0, "Print N slides per page for handouts: 2 or 4 (0 = one slide per page)")
+	audience := flag.String("audience", "", "Render only content for this audience: present or handout (see <!-- only: ... --> markers)")
+	autoFit := flag.Bool("auto-fit", false, "Shrink text and code of slides that do not fit, down to about 12pt")
+	continuation := flag.Bool("continuation", false, "Continue slides that do not fit on extra \"(cont.)\" pages instead of cutting them off")
+	listAutoFit := flag.Bool("list-auto-fit", false, "Shrink list fonts (down to 10pt) so long lists fit on their slide")
+	autoFontScale := flag.Bool("auto-font-scale", false, "Shrink text and code by one factor on all slides so the fullest slide fits")
+	dateFormat := flag.String("date-format", "", "Go layout of the title slide date, e.g. \"2 January 2006\" or \"2006-01-02\", or a locale (en, ru) for its long form; default \"January 2, 2006\"")
+	footer := flag.String("footer", "", "Footer template for content slides, e.g. \"{title}|{date}|{n}/{total}\" (left|center|right)")
+	codeFontSize := flag.Float64("code-font-size", 11, "Code block font size in points; line height and block height follow it")
+	lineNumbers := flag.Bool("line-numbers", false, "Number the lines of code blocks")
+	pageNumbers := flag.Bool("page-numbers", false, "Show \"n / total\" at the bottom right of content slides")
+	cornerNumber := flag.Bool("corner-number", false, "Draw a large, muted slide number in a bottom corner of content slides")
+	bookmarks := flag.Bool("bookmarks", true, "Add a PDF outline with the slide titles and subsections (-bookmarks=false to disable)")
+	navDots := flag.Bool("nav-dots", false, "Draw a strip of clickable dots, one per slide, at the bottom of content slides")
+	stamp := flag.Bool("stamp", false, "Print the generation date and the present2pdf version at the bottom of every slide")
+	timingSummary := flag.Bool("timing-summary", false, "Append a slide totaling the <!-- time: 2m --> annotations of each slide")
+	maxContentWidth := flag.Float64("max-content-width", 0, "Cap the content column at this width in mm and center it, e.g. 180 (0 = full width)")
+	logo := flag.String("logo", "", "Image placed in a corner of every content slide (PNG, JPEG or GIF)")
+	logoCorner := flag.String("logo-corner", "bottom-right", "Corner for -logo: top-left, top-right, bottom-left or bottom-right")
+	linkFootnotes := flag.Bool("link-footnotes", false, "Number links and list their URLs at the bottom of each slide (for printed decks)")
+	smartPunctuation := flag.Bool("smart-punctuation", false, "Curl straight quotes and turn -- and --- into en and em dashes in slide text")
+	preserveLineBreaks := flag.Bool("preserve-line-breaks", false, "Keep the line breaks of text paragraphs (addresses, poems) instead of reflowing them")
+	notes := flag.String("notes", "ignore", "Speaker notes: ignore, append (small text at the bottom of each slide) or pages (a notes page after each slide)")
+	notesPages := flag.Bool("notes-pages", false, "Follow every slide with a page showing its thumbnail and speaker notes")
+	allowRemoteImages := flag.Bool("allow-remote-images", false, "Download images referenced by http(s) URL instead of skipping them")
+	checkImages := flag.Bool("check-images", false, "List referenced image files that do not exist and exit (non-zero if any are missing)")
+	closingSlide := flag.String("closing-slide", "", "Append a closing slide with this title (e.g. \"Thank you\") and the authors' contact details")
+	closingSubtitle := flag.String("closing-subtitle", "", "Subtitle of the -closing-slide, e.g. \"Questions?\"")
+	debugDir := flag.String("debug-dir", "", "Write the preprocessed source and per-slide element dumps into this directory")
+	grayscale := flag.Bool("grayscale", false, "Draw theme and code colors as grays for black-and-white printing")
+	rtl := flag.Bool("rtl", false, "Lay out text right-to-left (Hebrew, Arabic)")
 	showVersion := flag.Bool("version", false, "Show version information and exit")
 	flag.Parse()
 
@@ -39,6 +81,15 @@ func main() {
 		os.Exit(0)
 	}
 
+	// If list-fonts flag is set, print available fonts and exit
+	if *listFonts {
+		fmt.Println("Available body fonts:")
+		for _, font := range converter.GetAvailableFonts() {
+			fmt.Printf("  - %s\n", font)
+		}
+		os.Exit(0)
+	}
+
 	// If list-code-themes flag is set, print available themes and exit
 	if *listCodeThemes {
 		themes := converter.GetAvailableStyles()
@@ -49,31 +100,131 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *inputFile == "" {
+	if *inputFile == "" && *inputDir == "" {
 		fmt.Fprintf(os.Stderr, "Error: input file is required\n")
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	// Check if input file exists
-	if _, err := os.Stat(*inputFile); os.IsNotExist(err) {
+	if *inputDir != "" {
+		if info, err := os.Stat(*inputDir); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: input directory does not exist: %s\n", *inputDir)
+			os.Exit(1)
+		}
+	} else if _, err := os.Stat(*inputFile); os.IsNotExist(err) {
 		fmt.Fprintf(os.Stderr, "Error: input file does not exist: %s\n", *inputFile)
 		os.Exit(1)
 	}
 
+	if *audience != "" && !slices.Contains(converter.GetAvailableAudiences(), strings.ToLower(*audience)) {
+		fmt.Fprintf(os.Stderr, "Error: unknown audience %q (available: %s)\n", *audience, strings.Join(converter.GetAvailableAudiences(), ", "))
+		os.Exit(1)
+	}
+	if *checkImages && *inputDir != "" {
+		fmt.Fprintf(os.Stderr, "Error: -check-images needs a single -input file\n")
+		os.Exit(1)
+	}
+	if *checkImages {
+		missing, err := converter.CheckImages(*inputFile, converter.WithAudience(*audience))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, path := range missing {
+			fmt.Fprintf(os.Stderr, "Missing image: %s\n", path)
+		}
+		if len(missing) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("All images found")
+		os.Exit(0)
+	}
+
 	// Default output file
 	output := *outputFile
-	if output == "" {
+	if output == "" && *inputDir != "" {
+		output = *inputDir
+	} else if output == "" {
 		ext := filepath.Ext(*inputFile)
 		output = (*inputFile)[:len(*inputFile)-len(ext)] + ".pdf"
 	}
 
+	// Flags given on the command line take precedence over the slide's front matter
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
 	// Convert slide to PDF
-	conv := converter.NewConverter(
-		converter.WithCodeTheme(*codeTheme),
-		converter.WithTheme(*pdfTheme),
+	opts := []converter.Option{
 		converter.WithQuiet(*quiet),
-	)
+		converter.WithVerbose(*verbose),
+		converter.WithFontSubsetting(*subsetFonts),
+		converter.WithMaxSlideOverflow(*maxOverflow),
+		converter.WithRTL(*rtl),
+		converter.WithHandout(*handout),
+		converter.WithAudience(*audience),
+		converter.WithAutoFit(*autoFit),
+		converter.WithContinuation(*continuation),
+		converter.WithListAutoFit(*listAutoFit),
+		converter.WithAutoGlobalFontScale(*autoFontScale),
+		converter.WithFooter(*footer),
+		converter.WithDateFormat(*dateFormat),
+		converter.WithPageNumbers(*pageNumbers),
+		converter.WithCodeFontSize(*codeFontSize),
+		converter.WithCodeLineNumbers(*lineNumbers),
+		converter.WithCornerSlideNumber(*cornerNumber),
+		converter.WithNavDots(*navDots),
+		converter.WithBookmarks(*bookmarks),
+		converter.WithGenerationStamp(*stamp),
+		converter.WithToolVersion(version),
+		converter.WithTimingSummary(*timingSummary),
+		converter.WithMaxContentWidth(*maxContentWidth),
+		converter.WithLinksAsFootnotes(*linkFootnotes),
+		converter.WithNotesPages(*notesPages),
+		converter.WithAllowRemoteImages(*allowRemoteImages),
+		converter.WithSmartPunctuation(*smartPunctuation),
+		converter.WithPreserveLineBreaks(*preserveLineBreaks),
+		converter.WithDebugDir(*debugDir),
+		converter.WithGrayscale(*grayscale),
+		converter.WithThemeFile(*themeFile),
+		converter.WithClosingSlide(*closingSlide, *closingSubtitle),
+		converter.WithOverflowWarnThreshold(*warnThreshold),
+	}
+	if *logo != "" {
+		opts = append(opts, converter.WithCornerLogo(*logo, *logoCorner))
+	}
+	if setFlags["code-theme"] {
+		opts = append(opts, converter.WithCodeTheme(*codeTheme))
+	}
+	if setFlags["theme"] {
+		opts = append(opts, converter.WithTheme(*pdfTheme))
+	}
+	if setFlags["page-size"] {
+		if !slices.Contains(converter.GetAvailablePageSizes(), strings.ToUpper(*pageSize)) {
+			fmt.Fprintf(os.Stderr, "Error: unknown page size %q (available: %s)\n", *pageSize, strings.Join(converter.GetAvailablePageSizes(), ", "))
+			os.Exit(1)
+		}
+		opts = append(opts, converter.WithPageSize(*pageSize))
+	}
+	if setFlags["body-font"] {
+		if !slices.Contains(converter.GetAvailableFonts(), strings.ToLower(*bodyFont)) {
+			fmt.Fprintf(os.Stderr, "Error: unknown body font %q (available: %s)\n", *bodyFont, strings.Join(converter.GetAvailableFonts(), ", "))
+			os.Exit(1)
+		}
+		opts = append(opts, converter.WithBodyFont(*bodyFont))
+	}
+	if setFlags["notes"] {
+		if !slices.Contains(converter.GetAvailableNotesModes(), *notes) {
+			fmt.Fprintf(os.Stderr, "Error: unknown notes mode %q (available: %s)\n", *notes, strings.Join(converter.GetAvailableNotesModes(), ", "))
+			os.Exit(1)
+		}
+		opts = append(opts, converter.WithNotes(*notes))
+	}
+	if *inputDir != "" {
+		os.Exit(convertDir(*inputDir, output, opts))
+	}
+
+	conv := converter.NewConverter(opts...)
 	if err := conv.Convert(*inputFile, output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error converting file: %v\n", err)
 		os.Exit(1)
@@ -81,3 +232,28 @@ func main() {
 
 	fmt.Printf("Successfully converted %s to %s\n", *inputFile, output)
 }
+
+// convertDir converts the .slide files of inDir into outDir, prints how each
+// file went and returns the exit code: non-zero if any conversion failed
+func convertDir(inDir, outDir string, opts []converter.Option) int {
+	results, err := converter.ConvertDir(inDir, outDir, opts...)
+	if len(results) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("FAILED %s: %v\n", r.Input, r.Err)
+		} else {
+			fmt.Printf("ok     %s -> %s\n", r.Input, r.Output)
+		}
+	}
+	fmt.Printf("Converted %d of %d files\n", len(results)-failed, len(results))
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
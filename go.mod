@@ -5,10 +5,15 @@ go 1.22
 require (
 	github.com/alecthomas/chroma/v2 v2.23.1
 	github.com/jung-kurt/gofpdf v1.16.2
-	golang.org/x/tools v0.18.0
+	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c
+	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef
+	golang.org/x/image v0.18.0
+	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d
 )
 
 require (
 	github.com/dlclark/regexp2 v1.11.5 // indirect
 	github.com/yuin/goldmark v1.4.13 // indirect
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 )
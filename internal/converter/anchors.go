@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/present"
+)
+
+// registerAnchors creates an internal link for every slide that can be the
+// target of a "#anchor" link: sections with a {#custom-id} and, for every
+// slide, the deterministic anchor "slide-N" (N is the slide number, the
+// title slide being slide-1). A link jumps to the first page of the slide.
+func (c *Converter) registerAnchors(doc *present.Doc) {
+	c.anchors = make(map[string]int)
+	c.anchorPages = make(map[int][]int)
+	c.badAnchors = make(map[string]bool)
+
+	add := func(name string, slide int) {
+		if _, ok := c.anchors[name]; ok {
+			c.warnf("duplicate anchor %q, slide %d ignored", name, slide)
+			return
+		}
+		id := c.pdf.AddLink()
+		c.anchors[name] = id
+		c.anchorPages[slide] = append(c.anchorPages[slide], id)
+	}
+
+	add("slide-1", 1)
+	for i, section := range doc.Sections {
+		slide := i + 2
+		add(fmt.Sprintf("slide-%d", slide), slide)
+		if section.ID != "" {
+			add(section.ID, slide)
+		}
+	}
+
+	for slide := len(doc.Sections) + 2; slide < len(doc.Sections)+2+c.extraSlides(); slide++ {
+		add(fmt.Sprintf("slide-%d", slide), slide)
+	}
+}
+
+// extraSlides returns the number of slides following the sections: the
+// timing summary and closing slides
+func (c *Converter) extraSlides() int {
+	extra := 0
+	if c.timingSummary {
+		extra++
+	}
+	if c.closingTitle != "" {
+		extra++
+	}
+	return extra
+}
+
+// placeAnchors points the internal links of the current slide at its page
+func (c *Converter) placeAnchors() {
+	for _, id := range c.anchorPages[c.currentSlideNumber] {
+		c.pdf.SetLink(id, 0, -1)
+	}
+}
+
+// internalLink resolves a "#anchor" URL to an internal link ID. ok is false
+// for external URLs; unknown anchors are reported once and resolve to 0.
+func (c *Converter) internalLink(url string) (id int, ok bool) {
+	name, isAnchor := strings.CutPrefix(url, "#")
+	if !isAnchor {
+		return 0, false
+	}
+	if id, found := c.anchors[name]; found {
+		return id, true
+	}
+	if !c.badAnchors[name] {
+		if c.badAnchors == nil {
+			c.badAnchors = make(map[string]bool)
+		}
+		c.badAnchors[name] = true
+		c.warnf("link to unknown anchor %q", url)
+	}
+	return 0, true
+}
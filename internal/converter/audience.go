@@ -0,0 +1,210 @@
+package converter
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/present"
+)
+
+// audiences are the WithAudience audiences
+var audiences = []string{"present", "handout"}
+
+// GetAvailableAudiences returns the audiences of WithAudience
+func GetAvailableAudiences() []string {
+	return slices.Clone(audiences)
+}
+
+// checkAudience reports an unknown WithAudience audience
+func (c *Converter) checkAudience() error {
+	if c.audience != "" && !slices.Contains(audiences, strings.ToLower(c.audience)) {
+		return fmt.Errorf("unknown audience %q (available: %s)", c.audience, strings.Join(audiences, ", "))
+	}
+	return nil
+}
+
+var (
+	// htmlOnlyMarkerRe matches an audience marker in the HTML of a
+	// Markdown section
+	htmlOnlyMarkerRe = regexp.MustCompile(`<!--\s*only:\s*(\S+?)\s*-->`)
+
+	// htmlTagRe matches an HTML start or end tag
+	htmlTagRe = regexp.MustCompile(`(?i)<(/?)([a-z][a-z0-9]*)\b[^>]*>`)
+)
+
+// filterAudience drops the sections and blocks of doc marked for another
+// audience than c.audience and strips the markers. A marker before the first
+// element of a section applies to the whole section; elsewhere it applies to
+// the element (in Markdown, the HTML block) that follows it. Timings are
+// re-indexed to the sections kept.
+func (c *Converter) filterAudience(doc *present.Doc) {
+	var sections []present.Section
+	var timings map[int]time.Duration
+	for i, section := range doc.Sections {
+		if !filterSection(&section, c.audience) {
+			continue
+		}
+		if d, ok := c.timings[i]; ok {
+			if timings == nil {
+				timings = make(map[int]time.Duration)
+			}
+			timings[len(sections)] = d
+		}
+		sections = append(sections, section)
+	}
+	doc.Sections = sections
+	c.timings = timings
+}
+
+// filterSection filters the elements of section for audience and reports
+// whether the section itself is for audience
+func filterSection(section *present.Section, audience string) bool {
+	marker, elems := leadingMarker(section.Elem)
+	section.Elem = filterElems(elems, audience)
+	return marker == "" || forAudience(marker, audience)
+}
+
+// forAudience reports whether content marked for marker is rendered for
+// audience; an empty audience takes everything
+func forAudience(marker, audience string) bool {
+	return audience == "" || strings.EqualFold(marker, audience)
+}
+
+// leadingMarker returns the audience of the marker that starts elems, if
+// any, and elems without it
+func leadingMarker(elems []present.Elem) (string, []present.Elem) {
+	if len(elems) == 0 {
+		return "", elems
+	}
+	switch e := elems[0].(type) {
+	case present.Text:
+		if m := textMarker(e); m != "" {
+			return m, elems[1:]
+		}
+	case present.HTML:
+		html := strings.TrimLeft(string(e.HTML), " \t\r\n")
+		if loc := htmlOnlyMarkerRe.FindStringSubmatchIndex(html); loc != nil && loc[0] == 0 {
+			rest := html[loc[1]:]
+			if strings.TrimSpace(rest) == "" {
+				return html[loc[2]:loc[3]], elems[1:]
+			}
+			return html[loc[2]:loc[3]], append([]present.Elem{present.HTML{HTML: template.HTML(rest)}}, elems[1:]...)
+		}
+	}
+	return "", elems
+}
+
+// textMarker returns the audience of a legacy paragraph that is a marker
+// line alone, as isolateAudienceMarkers leaves them
+func textMarker(text present.Text) string {
+	if text.Pre || len(text.Lines) != 1 {
+		return ""
+	}
+	if m := onlyMarkerRe.FindStringSubmatch(text.Lines[0]); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// filterElems drops the elements marked for another audience and the
+// markers, recursing into subsections
+func filterElems(elems []present.Elem, audience string) []present.Elem {
+	var out []present.Elem
+	pending := "" // marker waiting for the element it applies to
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case present.Text:
+			if m := textMarker(e); m != "" {
+				pending = m
+				continue
+			}
+		case present.HTML:
+			var html string
+			html, pending = filterHTML(string(e.HTML), pending, audience)
+			if strings.TrimSpace(html) != "" {
+				e.HTML = template.HTML(html)
+				out = append(out, e)
+			}
+			continue
+		case present.Section:
+			if filterSection(&e, audience) && (pending == "" || forAudience(pending, audience)) {
+				out = append(out, e)
+			}
+			pending = ""
+			continue
+		}
+		if pending == "" || forAudience(pending, audience) {
+			out = append(out, elem)
+		}
+		pending = ""
+	}
+	return out
+}
+
+// filterHTML drops the HTML blocks of a Markdown section marked for another
+// audience and the markers. pending is a marker applying to the first block;
+// a marker left without a block to apply to is returned for the next element.
+func filterHTML(html, pending, audience string) (string, string) {
+	var b strings.Builder
+	for {
+		if pending != "" {
+			end := htmlBlockEnd(html)
+			if end == 0 {
+				break
+			}
+			if forAudience(pending, audience) {
+				b.WriteString(html[:end])
+			}
+			html, pending = html[end:], ""
+			continue
+		}
+		loc := htmlOnlyMarkerRe.FindStringSubmatchIndex(html)
+		if loc == nil {
+			b.WriteString(html)
+			break
+		}
+		b.WriteString(html[:loc[0]])
+		pending, html = html[loc[2]:loc[3]], html[loc[1]:]
+	}
+	return b.String(), pending
+}
+
+// htmlBlockEnd returns the end of the first block of html: the element
+// starting it up to its matching end tag, or its first line if it does not
+// start with a tag. It returns 0 if html is blank.
+func htmlBlockEnd(html string) int {
+	start := len(html) - len(strings.TrimLeft(html, " \t\r\n"))
+	if start == len(html) {
+		return 0
+	}
+	loc := htmlTagRe.FindStringSubmatchIndex(html[start:])
+	if loc == nil || loc[0] != 0 || loc[3] > loc[2] {
+		if i := strings.IndexByte(html[start:], '\n'); i >= 0 {
+			return start + i
+		}
+		return len(html)
+	}
+	name := strings.ToLower(html[start+loc[4] : start+loc[5]])
+	if name == "hr" || name == "br" || name == "img" || strings.HasSuffix(html[start:start+loc[1]], "/>") {
+		return start + loc[1]
+	}
+	depth := 0
+	for _, m := range htmlTagRe.FindAllStringSubmatchIndex(html[start:], -1) {
+		if !strings.EqualFold(html[start+m[4]:start+m[5]], name) {
+			continue
+		}
+		if m[3] > m[2] {
+			depth--
+		} else {
+			depth++
+		}
+		if depth == 0 {
+			return start + m[1]
+		}
+	}
+	return len(html) // unclosed block
+}
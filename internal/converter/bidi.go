@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isRTLRune reports whether r belongs to a right-to-left script (Hebrew,
+// Arabic, Syriac, Thaana and their presentation forms)
+func isRTLRune(r rune) bool {
+	switch {
+	case r >= 0x0590 && r <= 0x08FF,
+		r >= 0xFB1D && r <= 0xFDFF,
+		r >= 0xFE70 && r <= 0xFEFF:
+		return true
+	}
+	return false
+}
+
+// mirroredRunes maps paired punctuation to its mirror image, used for
+// characters shown inside right-to-left runs
+var mirroredRunes = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+}
+
+// visualOrder converts a single line of right-to-left text from logical
+// (typed) order to the left-to-right order in which it must be drawn.
+//
+// This is a simplified form of the Unicode bidirectional algorithm: the line
+// direction is right-to-left, runs of left-to-right letters and digits keep
+// their order, and neutral characters (spaces, punctuation) between two such
+// runs stay with them. There is no contextual shaping, so Arabic letters are
+// drawn in their isolated forms.
+func visualOrder(s string) string {
+	const (
+		ltr     = 'L'
+		rtl     = 'R'
+		neutral = 'N'
+	)
+
+	runes := []rune(s)
+	classes := make([]byte, len(runes))
+	for i, r := range runes {
+		switch {
+		case isRTLRune(r):
+			classes[i] = rtl
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			classes[i] = ltr
+		default:
+			classes[i] = neutral
+		}
+	}
+
+	// Resolve neutrals: left-to-right only when surrounded by left-to-right
+	// characters, otherwise they follow the line direction
+	strongBefore := func(i int) byte {
+		for j := i - 1; j >= 0; j-- {
+			if classes[j] != neutral {
+				return classes[j]
+			}
+		}
+		return rtl
+	}
+	resolved := make([]byte, len(runes))
+	for i, class := range classes {
+		resolved[i] = class
+		if class != neutral {
+			continue
+		}
+		resolved[i] = rtl
+		if strongBefore(i) == ltr {
+			for j := i + 1; j < len(runes); j++ {
+				if classes[j] != neutral {
+					if classes[j] == ltr {
+						resolved[i] = ltr
+					}
+					break
+				}
+			}
+		}
+	}
+
+	// Emit runs from the end of the line; right-to-left runs are reversed
+	var b strings.Builder
+	end := len(runes)
+	for end > 0 {
+		start := end - 1
+		for start > 0 && resolved[start-1] == resolved[end-1] {
+			start--
+		}
+		if resolved[start] == rtl {
+			for j := end - 1; j >= start; j-- {
+				r := runes[j]
+				if m, ok := mirroredRunes[r]; ok {
+					r = m
+				}
+				b.WriteRune(r)
+			}
+		} else {
+			b.WriteString(string(runes[start:end]))
+		}
+		end = start
+	}
+	return b.String()
+}
+
+// displayText returns s in the order it must be drawn: visual order for
+// right-to-left documents, unchanged otherwise
+func (c *Converter) displayText(s string) string {
+	if c.rtl {
+		return visualOrder(s)
+	}
+	return s
+}
+
+// textAlign returns the MultiCell alignment for left-aligned text, mirrored
+// for right-to-left documents
+func (c *Converter) textAlign() string {
+	if c.rtl {
+		return "R"
+	}
+	return "L"
+}
@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/tools/present"
+)
+
+// addBookmarks adds the outline entries of a slide: its title at the top
+// level and its subsections (### headings) nested below it
+func (c *Converter) addBookmarks(section present.Section) {
+	if !c.bookmarks || c.thumbnail != nil {
+		return
+	}
+	y := c.region.Y
+	if c.transform != nil {
+		y = c.transform.Y
+	}
+	c.bookmark(section.Title, 0, y)
+	c.bookmarkSubsections(section.Elem, 1, y)
+}
+
+// bookmarkSubsections adds outline entries for the sections nested in elems
+func (c *Converter) bookmarkSubsections(elems []present.Elem, level int, y float64) {
+	for _, elem := range elems {
+		if sub, ok := elem.(present.Section); ok {
+			c.bookmark(sub.Title, level, y)
+			c.bookmarkSubsections(sub.Elem, level+1, y)
+		}
+	}
+}
+
+// bookmark adds an outline entry pointing at y on the current page. gofpdf
+// encodes the text as UTF-16 only while a UTF-8 font is selected, so with the
+// cp1251 fonts the text is encoded here, keeping Cyrillic titles readable.
+func (c *Converter) bookmark(title string, level int, y float64) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return
+	}
+	c.setTextFont("", 10)
+	if !c.utf8Fonts() {
+		title = pdfUTF16(title)
+	}
+	c.pdf.Bookmark(title, level, y)
+}
+
+// pdfUTF16 encodes s as a PDF text string: big-endian UTF-16 with a byte
+// order mark
+func pdfUTF16(s string) string {
+	var b strings.Builder
+	b.WriteString("\xFE\xFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		b.WriteByte(byte(u >> 8))
+		b.WriteByte(byte(u))
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Manifest records how a PDF was produced by ConvertBundle
+type Manifest struct {
+	Source       string    `json:"source"`        // Slide file name
+	SourceSHA256 string    `json:"source_sha256"` // Hex SHA-256 of the slide file
+	PDF          string    `json:"pdf"`           // PDF file name, next to the manifest
+	Title        string    `json:"title"`         // Presentation title
+	Theme        string    `json:"theme"`         // PDF theme ("custom" if overridden)
+	CodeTheme    string    `json:"code_theme"`    // Code highlighting style
+	Slides       int       `json:"slides"`        // Number of slides, including the title slide
+	Pages        int       `json:"pages"`         // Number of PDF pages
+	GeneratedAt  time.Time `json:"generated_at"`  // Conversion time (UTC)
+	Images       []string  `json:"images"`        // Embedded images, relative to the slide file
+}
+
+// ConvertBundle converts a .slide file into outputDir as NAME.pdf plus a
+// NAME.json Manifest describing the source and the settings used, for
+// archiving published decks. outputDir is created if needed.
+func (c *Converter) ConvertBundle(inputPath, outputDir string) (*Manifest, error) {
+	source, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	pdfPath := filepath.Join(outputDir, name+".pdf")
+	res, err := c.ConvertTo(inputPath, pdfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(source)
+	m := &Manifest{
+		Source:       filepath.Base(inputPath),
+		SourceSHA256: hex.EncodeToString(sum[:]),
+		PDF:          filepath.Base(pdfPath),
+		Title:        res.Title,
+		Theme:        themeName(c.theme),
+		CodeTheme:    c.codeTheme,
+		Slides:       res.Slides,
+		Pages:        res.Pages,
+		GeneratedAt:  c.generated.UTC().Truncate(time.Second),
+		Images:       []string{},
+	}
+	seen := make(map[string]bool)
+	for _, img := range res.Images {
+		path := img.Path
+		if rel, err := filepath.Rel(filepath.Dir(inputPath), path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+		if !seen[path] {
+			seen[path] = true
+			m.Images = append(m.Images, path)
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, name+".json"), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return m, nil
+}
+
+// themeName returns the name of a predefined theme, or "custom"
+func themeName(t Theme) string {
+	for name, theme := range availableThemes {
+		if theme == t {
+			return name
+		}
+	}
+	return "custom"
+}
@@ -0,0 +1,146 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// calloutRe matches a callout marker at the end of a code line, written as a
+// line comment holding <N> or <letter> and optional legend text:
+//
+//	conn, err := net.Dial("tcp", addr) // <1> Open the connection
+var calloutRe = regexp.MustCompile(`\s*(?://|#|--|;)\s*<([1-9][0-9]?|[a-z])>\s*(.*?)\s*$`)
+
+const calloutRadius = 2.3 // radius of the callout circle (mm)
+
+// codeCallout is an annotation attached to a line of a code block
+type codeCallout struct {
+	Line  int    // 0-based line index in the code block
+	Label string // "1", "2", ... or "a", "b", ...
+	Text  string // Legend text, "" if none
+}
+
+// extractCallouts removes callout markers from code and returns the cleaned
+// code together with the callouts in order of appearance
+func extractCallouts(code string) (string, []codeCallout) {
+	if !strings.Contains(code, "<") {
+		return code, nil
+	}
+
+	var callouts []codeCallout
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		m := calloutRe.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		callouts = append(callouts, codeCallout{
+			Line:  i,
+			Label: line[m[2]:m[3]],
+			Text:  line[m[4]:m[5]],
+		})
+		lines[i] = line[:m[0]]
+	}
+	if callouts == nil {
+		return code, nil
+	}
+	return strings.Join(lines, "\n"), callouts
+}
+
+// renderCodeText renders source code as a highlighted code block (plain if
+// highlighting fails), followed by a legend for any callouts
+func (c *Converter) renderCodeText(codeText, language string, y float64) float64 {
+	return c.renderCodeLines(c.foldCode(c.expandCodeTabs(codeText, language)), language, 1, y)
+}
+
+// renderCodeLines renders code whose first line is line number first of its
+// block, for the parts of a block split over continuation pages
+func (c *Converter) renderCodeLines(codeText, language string, first int, y float64) float64 {
+	// With WithContinuation, a block too long for the page goes on over
+	// continuation pages instead of being truncated
+	if c.continues() {
+		if head, rest, ok := c.splitCode(codeText, y); ok && (head != "" || y > c.contentTop()) {
+			if head != "" {
+				y = c.renderCodeLines(head, language, first, y)
+				first += strings.Count(normalizeCode(head), "\n") + 1
+			}
+			y = c.continueSlide(y)
+			return c.renderCodeLines(rest, language, first, y)
+		}
+	}
+
+	codeText, callouts := extractCallouts(codeText)
+	c.debugf("code: language %q, %d lines", language, strings.Count(normalizeCode(codeText), "\n")+1)
+
+	var endY float64
+	if tokens, err := c.highlightCode(codeText, language); err == nil {
+		endY = c.renderHighlightedCode(tokens, first, y)
+	} else {
+		c.warnf("syntax highlighting failed, rendering plain code: %v", err)
+		endY = c.renderCodePlain(codeText, first, y)
+	}
+
+	if len(callouts) == 0 {
+		return endY
+	}
+	c.renderCalloutMarkers(codeText, callouts, first, y)
+	return c.renderCalloutLegend(callouts, endY-5)
+}
+
+// renderCalloutMarkers draws each callout at the end of its line in a code
+// block that starts at y and is numbered from first
+func (c *Converter) renderCalloutMarkers(codeText string, callouts []codeCallout, first int, y float64) {
+	lines := strings.Split(normalizeCode(codeText), "\n")
+	visible := len(lines)
+	if limit := c.codeLineLimit(); visible > limit {
+		visible = limit - 1 // the last drawn line is "..."
+	}
+	textX := c.contentX() + c.codePadH + c.lineNumberGutter(first-1+visible)
+	c.setCodeFont("", c.codeFontSize)
+	for _, callout := range callouts {
+		if callout.Line >= visible {
+			continue // truncated
+		}
+		lineWidth := c.pdf.GetStringWidth(c.translator(lines[callout.Line]))
+		lineY := y + c.codePadV + float64(callout.Line)*c.codeLineHeight()
+		c.drawCallout(callout.Label, textX+lineWidth+calloutRadius+2, lineY+c.codeLineHeight()/2)
+		c.setCodeFont("", c.codeFontSize)
+	}
+}
+
+// renderCalloutLegend lists the callouts that have text below a code block
+// and returns the Y position after the legend
+func (c *Converter) renderCalloutLegend(callouts []codeCallout, y float64) float64 {
+	const lineHeight = 8.0
+
+	rendered := false
+	for _, callout := range callouts {
+		if callout.Text == "" {
+			continue
+		}
+		c.drawCallout(callout.Label, c.contentX()+5+calloutRadius, y+lineHeight/2)
+
+		c.setTextFont("", 14)
+		c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+		c.pdf.SetXY(c.contentX()+5+2*calloutRadius+3, y)
+		c.pdf.Cell(c.contentWidth()-15, lineHeight, c.translator(callout.Text))
+		y += lineHeight
+		rendered = true
+	}
+	if !rendered {
+		return y + 5
+	}
+	return y + 7
+}
+
+// drawCallout draws a filled circle with label centered at (cx, cy)
+func (c *Converter) drawCallout(label string, cx, cy float64) {
+	c.pdf.SetFillColor(c.theme.LinkColor.R, c.theme.LinkColor.G, c.theme.LinkColor.B)
+	c.pdf.Circle(cx, cy, calloutRadius, "F")
+
+	c.setCodeFont("B", 8)
+	c.pdf.SetTextColor(255, 255, 255)
+	c.pdf.SetXY(cx-calloutRadius, cy-calloutRadius)
+	c.pdf.CellFormat(2*calloutRadius, 2*calloutRadius, label, "", 0, "C", false, 0, "")
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"maps"
+	"strings"
+
+	"golang.org/x/tools/present"
+)
+
+// contTitleSuffix marks the title of a WithContinuation page
+const contTitleSuffix = " (cont.)"
+
+// minCodeSplitLines is the fewest code lines left at the bottom of a page
+// when a code block is split; with less room the block starts on the next page
+const minCodeSplitLines = 3
+
+// continues reports whether WithContinuation pages can be added: on plain
+// slide pages, not on handouts or notes page thumbnails
+func (c *Converter) continues() bool {
+	return c.continuation && c.handout == 0 && c.thumbnail == nil && c.continueSlide != nil
+}
+
+// prepareScratch creates the scratch PDF that elements are laid out in
+// before they are drawn, to find the ones that must go on a continuation
+// page (WithContinuation) or the scale a slide fits at (WithAutoFit)
+func (c *Converter) prepareScratch() (func(), error) {
+	if !c.continuation && !c.autoFit {
+		return func() {}, nil
+	}
+	pdf, translator := c.pdf, c.translator
+	cleanup, err := c.initPDF()
+	c.scratch, c.pdf, c.translator = c.pdf, pdf, translator
+	if err != nil {
+		c.scratch = nil
+		return nil, err
+	}
+	c.scratch.AddPage()
+	return func() {
+		cleanup()
+		c.scratch = nil
+	}, nil
+}
+
+// dryRender lays out elem at y in the scratch PDF and returns where it ends,
+// without drawing it or repeating its diagnostics
+func (c *Converter) dryRender(elem present.Elem, y float64) float64 {
+	pdf, continueSlide := c.pdf, c.continueSlide
+	warnings, images, footnotes := len(c.warnings), len(c.images), len(c.footnotes)
+	badAnchors := maps.Clone(c.badAnchors)
+	quiet, verbose := c.quiet, c.verbose
+	defer func() {
+		c.pdf, c.continueSlide = pdf, continueSlide
+		c.warnings, c.images, c.footnotes = c.warnings[:warnings], c.images[:images], c.footnotes[:footnotes]
+		c.badAnchors = badAnchors
+		c.quiet, c.verbose = quiet, verbose
+	}()
+	c.pdf, c.continueSlide = c.scratch, nil
+	c.quiet, c.verbose = true, false
+	return c.renderElement(elem, y)
+}
+
+// continuePage starts a continuation page of a slide: its links are listed
+// at the bottom of the page left, and the new page repeats the background
+// image and the slide chrome with the title marked "(cont.)" (an untitled
+// slide stays untitled). It returns where content starts.
+func (c *Converter) continuePage(title, background string, y float64) float64 {
+	c.renderFootnotes(y)
+	c.footnotes = nil
+	c.pdf.AddPage()
+	c.fillBackground(c.theme.SlideBackground)
+	c.renderBackgroundImage(background)
+	c.renderLogo()
+	if strings.TrimSpace(title) != "" {
+		title += contTitleSuffix
+	}
+	c.renderSlideChrome(title)
+	c.renderSlideNotes()
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+	c.debugf("continued on page %d", c.pdf.PageNo())
+	return c.contentTop()
+}
+
+// splitCode returns the first lines of code that fit on the page from y as a
+// block and the rest, for continuing a code block on the next page. ok is
+// false if the whole block fits.
+func (c *Converter) splitCode(code string, y float64) (head, rest string, ok bool) {
+	lines := strings.Split(normalizeCode(code), "\n")
+	fit := int((c.contentBottom() - y - 2*c.codePadV) / c.codeLineHeight())
+	if len(lines) <= fit {
+		return code, "", false
+	}
+	if fit < minCodeSplitLines {
+		return "", code, true
+	}
+	return strings.Join(lines[:fit], "\n"), strings.Join(lines[fit:], "\n"), true
+}
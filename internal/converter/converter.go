@@ -2,47 +2,129 @@ package converter
 
 import (
 	"bytes"
+	"compress/zlib"
 	_ "embed"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jung-kurt/gofpdf"
 	"golang.org/x/tools/present"
 )
 
-//go:embed font/cp1251.map
-var cp1251Map []byte
-
-//go:embed font/helvetica_1251.json
-var helvetica1251JSON []byte
-
-//go:embed font/helvetica_1251.z
-var helvetica1251Z []byte
-
-//go:embed font/jetbrainsmono_1251.json
-var jetbrainsmono1251JSON []byte
-
-//go:embed font/jetbrainsmono_1251.z
-var jetbrainsmono1251Z []byte
-
-//go:embed font/jetbrainsmono_bold_1251.json
-var jetbrainsmono1251BoldJSON []byte
-
-//go:embed font/jetbrainsmono_bold_1251.z
-var jetbrainsmono1251BoldZ []byte
+// Font families registered for custom TrueType fonts
+const (
+	headingFontFamily = "Heading"
+	bodyFontFamily    = "Body"
+)
 
 // Converter handles conversion from .slide to PDF
 type Converter struct {
 	pdf                *gofpdf.Fpdf
-	translator         func(string) string // UTF-8 translator
-	codeTheme          string              // Name of the syntax highlighting style
-	theme              Theme               // Color theme for the presentation
-	slideDir           string              // Directory of the source slide file (for resolving relative paths)
-	currentSlideTitle  string              // For diagnostic messages
-	currentSlideNumber int                 // For diagnostic messages
-	quiet              bool                // Suppress diagnostic warnings
+	translator         func(string) string   // UTF-8 translator
+	codeTheme          string                // Name of the syntax highlighting style
+	codeThemeFallback  string                // Style used when codeTheme is unknown
+	theme              Theme                 // Color theme for the presentation
+	slideDir           string                // Directory of the source slide file (for resolving relative paths)
+	currentSlideTitle  string                // For diagnostic messages
+	currentSlideNumber int                   // For diagnostic messages
+	quiet              bool                  // Suppress diagnostic warnings
+	pageWidth          float64               // Physical page width (mm)
+	pageHeight         float64               // Physical page height (mm)
+	contentAspect      float64               // Aspect ratio of the drawable region (0 = full page)
+	region             rect                  // Drawable slide region on the page
+	explicit           map[string]bool       // Settings set via options (not overridable by front matter)
+	iframePosters      map[string]string     // Poster image paths keyed by .iframe URL
+	codeLanguages      []string              // Explicit .code -lang=X language of each directive, in source order
+	defaultLanguage    string                // Language of code blocks nothing else identifies
+	tabWidths          map[string]int        // Tab width of code blocks by language
+	codeFolding        bool                  // Collapse FOLD ... ENDFOLD regions of code
+	cornerNumber       bool                  // Draw a large slide number in a corner
+	subsetFonts        bool                  // Embed only used glyphs (UTF-8 font mode)
+	warnings           []Warning             // Diagnostics collected during conversion
+	maxOverflow        int                   // Max elements a slide may drop before failing (-1 = no limit)
+	themeOverrides     []func(*Theme)        // Tweaks applied on top of the selected theme
+	autoTheme          bool                  // Pick the theme from the code theme background (WithTheme("auto"))
+	paragraphSpacing   float64               // Vertical gap after each Markdown paragraph (mm)
+	autoLink           bool                  // Turn bare URLs in text into clickable links
+	gradient           *[2]RGB               // Title slide gradient (top, bottom); nil = solid background
+	rtl                bool                  // Lay out text right-to-left
+	fillThreshold      float64               // Fraction of content height that triggers a "nearly full" warning (0 = off)
+	images             []ImageInfo           // Images placed during conversion
+	handout            int                   // Slides per handout page (0 = one slide per page)
+	transform          *slideTransform       // Active handout slot transform, nil outside handout slides
+	thumbnail          *slideTransform       // Notes page thumbnail slot while a slide is redrawn there
+	notesPages         bool                  // Follow every slide with a notes page
+	appendNotes        bool                  // Draw the speaker notes at the bottom of each slide
+	slideNotes         []string              // Appended note lines of the current slide, below its content area
+	subsectionIndent   float64               // Indent of the content of the subsections being rendered (mm)
+	codeFontSize       float64               // Code block font size (pt)
+	maxCodeLines       int                   // Lines shown before a code block is truncated (0 = as many as fit)
+	audience           string                // Audience to render for ("present", "handout"; "" = all content)
+	anchors            map[string]int        // Internal link IDs keyed by anchor name
+	anchorPages        map[int][]int         // Internal link IDs to place on each slide
+	badAnchors         map[string]bool       // Unknown anchors already reported
+	destNames          map[int][]string      // Named destinations of each slide
+	destPages          map[string]int        // Page of each named destination placed so far
+	listAutoFit        bool                  // Shrink list fonts so lists fit on the slide
+	textScale          float64               // Scale of formatted body text (1 = 18pt)
+	fontScale          float64               // Deck-wide scale of body text and code (WithAutoGlobalFontScale)
+	autoFontScale      bool                  // Pick one font scale at which every slide fits
+	verbose            bool                  // Print layout decisions
+	logWriter          io.Writer             // Destination of diagnostics (nil = stderr)
+	footer             string                // Footer template for content slides ("" = none)
+	deckTitle          string                // Presentation title (footer {title})
+	deckDate           string                // Formatted presentation date (footer {date})
+	totalSlides        int                   // Number of slides including the title slide
+	headingTTF         []byte                // Custom TrueType font for titles (nil = Helvetica)
+	bodyTTF            []byte                // Custom TrueType font for body text (nil = Helvetica)
+	bodyFont           string                // Embedded text font family (see textFonts)
+	timings            map[int]time.Duration // Intended duration of each section (<!-- time: 2m -->)
+	timingSummary      bool                  // Append a slide totaling the section timings
+	codePadH           float64               // Horizontal padding inside code blocks (mm)
+	codePadV           float64               // Vertical padding inside code blocks (mm)
+	maxContentWidth    float64               // Cap on the content column width (mm, 0 = full width)
+	logo               *cornerLogo           // Logo placed in a corner of every slide (nil = none)
+	logoOnTitle        bool                  // Also place the corner logo on the title slide
+	linksAsFootnotes   bool                  // Number links and list their URLs at the slide bottom
+	footnotes          []string              // URLs referenced on the current slide
+	listBullets        []string              // Bullet glyph per list nesting depth
+	listIndent         float64               // Indent per list nesting level (mm)
+	codeIndent         float64               // Extra left indent of code blocks, e.g. inside a blockquote (mm)
+	smartPunctuation   bool                  // Curl straight quotes and turn -- and --- into dashes
+	titleOverflow      float64               // Height the current slide title wraps beyond one line (mm)
+	untitled           bool                  // Current slide has no title, so content starts higher
+	configured         deckSettings          // Front-matter settings as set by the options
+	fontFamily         string                // Font family last selected with setFont
+	fontStyle          string                // Font style last selected with setFont
+	debugDir           string                // Directory receiving preprocessed source and element dumps ("" = none)
+	closingTitle       string                // Title of the appended closing slide ("" = none)
+	closingSubtitle    string                // Subtitle of the appended closing slide
+	icons              map[string]string     // Icon image paths keyed by shortcode name (:name:)
+	imagePlaceholder   bool                  // Draw a placeholder box for missing images
+	allowRemoteImages  bool                  // Download http(s) images
+	imageFiles         map[string]imageFile  // Downloaded and rasterized images by source
+	imageDir           string                // Temp directory of imageFiles ("" = none yet)
+	captionBelow       string                // Text of the .caption after the element being rendered
+	stamp              bool                  // Stamp every slide with the generation date and tool version
+	toolVersion        string                // Version of the tool using the converter (WithToolVersion)
+	generated          time.Time             // Start of the current conversion
+	preserveLineBreaks bool                  // Keep the source line breaks of text paragraphs
+	language           string                // Language of the deck, for month names in dates ("en", "ru")
+	dateFormat         string                // Go time layout of deck dates ("" = the language's default)
+	navDots            bool                  // Draw a strip of clickable slide dots on content slides
+	grayscale          bool                  // Draw all theme and code colors as grays, for printing
+	themeFile          string                // JSON theme file replacing the named theme ("" = none)
+	bookmarks          bool                  // Add an outline entry per slide title and subsection
+	pageNumbers        bool                  // Draw "n / total" at the bottom right of content slides
+	codeLineNumbers    bool                  // Number the lines of code blocks
+	continuation       bool                  // Continue overflowing slides on extra pages instead of truncating
+	continueSlide      func(float64) float64 // Starts a continuation page of the current slide, given the page's end Y
+	scratch            *gofpdf.Fpdf          // PDF that elements are laid out in before drawing (WithContinuation, WithAutoFit)
+	autoFit            bool                  // Shrink text and code of slides that overflow until they fit
 }
 
 // Option is a functional option for configuring the Converter
@@ -52,19 +134,80 @@ type Option func(*Converter)
 func WithCodeTheme(themeName string) Option {
 	return func(c *Converter) {
 		c.codeTheme = themeName
+		c.explicit["code-theme"] = true
 	}
 }
 
-// WithTheme sets the PDF color theme
+// WithCodeThemeFallback sets the code theme used, with a warning, when the
+// requested one does not exist (default: monokai)
+func WithCodeThemeFallback(themeName string) Option {
+	return func(c *Converter) {
+		c.codeThemeFallback = themeName
+	}
+}
+
+// WithTheme sets the PDF color theme. "auto" picks the light or dark theme
+// to match the background of the code theme.
 func WithTheme(themeName string) Option {
 	return func(c *Converter) {
+		c.autoTheme = themeName == autoThemeName
 		if theme, ok := availableThemes[themeName]; ok {
 			c.theme = theme
 		}
+		c.explicit["theme"] = true
 		// If theme not found, keep the default
 	}
 }
 
+// WithPageSize sets the slide page size: "A4" (the default, 297x210mm),
+// "16:9" for widescreen projectors or "4:3". Unknown sizes are ignored.
+func WithPageSize(name string) Option {
+	return func(c *Converter) {
+		if size, ok := lookupPageSize(name); ok {
+			c.pageWidth, c.pageHeight = size[0], size[1]
+		}
+		c.explicit["page-size"] = true
+	}
+}
+
+// WithThemeFile uses the colors of a JSON theme file (see LoadTheme) instead
+// of a named theme, taking precedence over WithTheme and the front matter.
+// A file that cannot be loaded makes the conversion fail.
+func WithThemeFile(path string) Option {
+	return func(c *Converter) {
+		c.themeFile = path
+	}
+}
+
+// WithThemeOverride adjusts individual fields of the selected theme, e.g.
+//
+//	WithTheme("dark"), WithThemeOverride(func(t *Theme) { t.LinkColor = RGB{255, 184, 108} })
+//
+// Overrides are applied after the base theme is chosen (including a theme set
+// in the slide's front matter), in the order given.
+func WithThemeOverride(override func(*Theme)) Option {
+	return func(c *Converter) {
+		c.themeOverrides = append(c.themeOverrides, override)
+	}
+}
+
+// WithVerbose prints layout decisions for every element: its type, the Y range
+// it occupies, the language chosen for code and how images were scaled.
+// WithQuiet suppresses this output as well.
+func WithVerbose(verbose bool) Option {
+	return func(c *Converter) {
+		c.verbose = verbose
+	}
+}
+
+// WithLogOutput sets where warnings and verbose messages are printed
+// (stderr by default)
+func WithLogOutput(w io.Writer) Option {
+	return func(c *Converter) {
+		c.logWriter = w
+	}
+}
+
 // WithQuiet suppresses diagnostic warnings (slide overflow, code truncation)
 func WithQuiet(quiet bool) Option {
 	return func(c *Converter) {
@@ -72,12 +215,531 @@ func WithQuiet(quiet bool) Option {
 	}
 }
 
+// WithContentAspect constrains the drawable slide region to the given aspect
+// ratio (width / height), centered on the page with letterbox margins.
+// A ratio of 0 (the default) uses the full page.
+func WithContentAspect(ratio float64) Option {
+	return func(c *Converter) {
+		if ratio > 0 {
+			c.contentAspect = ratio
+		}
+	}
+}
+
+// WithFontSubsetting embeds only the glyphs used in the document instead of
+// the full fonts, which greatly reduces the size of small decks
+func WithFontSubsetting(subset bool) Option {
+	return func(c *Converter) {
+		c.subsetFonts = subset
+	}
+}
+
+// WithParagraphSpacing sets the vertical gap (mm) left after each Markdown
+// paragraph. The default is 5mm; use a smaller value to pack short
+// consecutive paragraphs more tightly.
+func WithParagraphSpacing(mm float64) Option {
+	return func(c *Converter) {
+		if mm >= 0 {
+			c.paragraphSpacing = mm
+		}
+	}
+}
+
+// WithAutoLink controls whether bare URLs (https://...) in Markdown text are
+// rendered as clickable links. Enabled by default.
+func WithAutoLink(autoLink bool) Option {
+	return func(c *Converter) {
+		c.autoLink = autoLink
+	}
+}
+
+// WithGradientBackground fills the title slide with a vertical gradient from
+// the top color to the bottom color instead of the theme's solid background
+func WithGradientBackground(from, to RGB) Option {
+	return func(c *Converter) {
+		c.gradient = &[2]RGB{from, to}
+	}
+}
+
+// WithRTL lays out titles, paragraphs and lists right-to-left for Hebrew or
+// Arabic decks: text is right-aligned and right-to-left runs are reordered for
+// display. It implies UTF-8 fonts, as with WithFontSubsetting.
+func WithRTL(rtl bool) Option {
+	return func(c *Converter) {
+		c.rtl = rtl
+	}
+}
+
+// WithMaxSlideOverflow makes conversion fail when a slide overflows and more
+// than n of its elements are cut off. The error lists the dropped elements.
+// A negative n (the default) disables the check.
+func WithMaxSlideOverflow(n int) Option {
+	return func(c *Converter) {
+		c.maxOverflow = n
+	}
+}
+
+// WithOverflowWarnThreshold warns about slides whose content fits but uses
+// more than the given fraction of the content height (e.g. 0.8), so they can
+// be trimmed before they overflow. 0 (the default) disables the warning.
+func WithOverflowWarnThreshold(fraction float64) Option {
+	return func(c *Converter) {
+		if fraction >= 0 && fraction < 1 {
+			c.fillThreshold = fraction
+		}
+	}
+}
+
+// WithHandout tiles n slides on each page for printing: 2 slides stacked on
+// a portrait page or 4 in a 2x2 grid on a landscape page. Other values turn
+// handout mode off.
+func WithHandout(n int) Option {
+	return func(c *Converter) {
+		c.handout = 0
+		if n == 2 || n == 4 {
+			c.handout = n
+		}
+	}
+}
+
+// WithCodeFontSize sets the code block font size in points (default 11).
+// Line height scales with it, so larger code also makes taller blocks.
+func WithCodeFontSize(size float64) Option {
+	return func(c *Converter) {
+		if size > 0 {
+			c.codeFontSize = size
+		}
+	}
+}
+
+// WithCodePadding sets the padding between a code block's background and its
+// text in mm: h on the left and right, v at the top and bottom (default 5 and
+// 2.5). Text that does not fit between the horizontal padding is clipped.
+func WithCodePadding(h, v float64) Option {
+	return func(c *Converter) {
+		if h >= 0 {
+			c.codePadH = h
+		}
+		if v >= 0 {
+			c.codePadV = v
+		}
+	}
+}
+
+// WithMaxCodeLines sets how many lines a code block shows before it is
+// truncated with "..." (default 20; 0 = no limit). Blocks are also never
+// taller than the slide's content area. With WithContinuation the limit does
+// not apply, since long blocks continue on the next page.
+func WithMaxCodeLines(n int) Option {
+	return func(c *Converter) {
+		if n >= 0 {
+			c.maxCodeLines = n
+		}
+	}
+}
+
+// WithMaxContentWidth caps the width of the slide content column in mm and
+// centers it, so paragraphs on wide pages keep a readable line length. Titles,
+// code and images share the column. Zero (the default) uses the full width.
+func WithMaxContentWidth(mm float64) Option {
+	return func(c *Converter) {
+		if mm >= 0 {
+			c.maxContentWidth = mm
+		}
+	}
+}
+
+// WithCornerLogo places a small logo (scaled to fit 30x10mm) in a corner of
+// every content slide: "top-left", "top-right", "bottom-left" or
+// "bottom-right". A relative path is resolved against the slide file.
+func WithCornerLogo(path, corner string) Option {
+	return func(c *Converter) {
+		c.logo = &cornerLogo{path: path, corner: corner}
+	}
+}
+
+// WithCornerLogoOnTitleSlide also places the WithCornerLogo logo on the
+// title slide
+func WithCornerLogoOnTitleSlide(show bool) Option {
+	return func(c *Converter) {
+		c.logoOnTitle = show
+	}
+}
+
+// WithLinksAsFootnotes marks every link with a superscript number and lists
+// the numbered URLs in a "References" block at the bottom of the slide, so
+// links stay readable in printed decks. Links remain clickable.
+func WithLinksAsFootnotes(footnotes bool) Option {
+	return func(c *Converter) {
+		c.linksAsFootnotes = footnotes
+	}
+}
+
+// WithNotesPages follows every slide with a notes page showing a thumbnail
+// of the slide and its speaker notes (": " lines), as expected by presenter
+// tools that pair each slide with the next page. The title slide gets a
+// notes page too, so slides and notes strictly alternate. Ignored in handout
+// mode.
+func WithNotesPages(notes bool) Option {
+	return func(c *Converter) {
+		c.notesPages = notes
+	}
+}
+
+// WithNotes sets what is done with the speaker notes (": " lines) of each
+// slide: "ignore" drops them (the default), "append" draws them in small,
+// muted text at the bottom of the slide, below the content, and "pages"
+// follows every slide with a notes page as WithNotesPages does. Unknown
+// modes are ignored.
+func WithNotes(mode string) Option {
+	return func(c *Converter) {
+		switch mode {
+		case "ignore":
+			c.appendNotes, c.notesPages = false, false
+		case "append":
+			c.appendNotes, c.notesPages = true, false
+		case "pages":
+			c.appendNotes, c.notesPages = false, true
+		}
+	}
+}
+
+// WithAudience renders the deck for one audience, "present" or "handout".
+// Sections and blocks marked for another audience with
+//
+//	<!-- only: handout -->
+//
+// are left out: a marker between a section heading and its first block
+// applies to the section, elsewhere to the block that follows it. By default
+// all content is rendered; another audience fails the conversion.
+func WithAudience(audience string) Option {
+	return func(c *Converter) {
+		c.audience = audience
+	}
+}
+
+// WithListAutoFit shrinks the font of a bulleted list (down to 10pt) so that
+// all of its items fit in the space left on the slide
+func WithListAutoFit(autoFit bool) Option {
+	return func(c *Converter) {
+		c.listAutoFit = autoFit
+	}
+}
+
+// WithListBullets sets the bullet glyph for each list nesting depth, starting
+// with top-level items. Deeper levels reuse the last glyph. The default is
+// •, ◦, ▪.
+func WithListBullets(glyphs []string) Option {
+	return func(c *Converter) {
+		if len(glyphs) > 0 {
+			c.listBullets = glyphs
+		}
+	}
+}
+
+// WithListIndent sets how far each nested list level is indented (mm)
+func WithListIndent(step float64) Option {
+	return func(c *Converter) {
+		if step >= 0 {
+			c.listIndent = step
+		}
+	}
+}
+
+// WithSmartPunctuation converts straight quotes to curly quotes, "--" and
+// "---" to en and em dashes and "..." to an ellipsis in slide text. Code is
+// left as written. Off by default.
+func WithSmartPunctuation(smart bool) Option {
+	return func(c *Converter) {
+		c.smartPunctuation = smart
+	}
+}
+
+// WithDefaultCodeLanguage sets the language of code blocks that have no
+// explicit language, no known file extension and no content chroma
+// recognizes. The default is "go".
+func WithDefaultCodeLanguage(language string) Option {
+	return func(c *Converter) {
+		if language != "" {
+			c.defaultLanguage = language
+		}
+	}
+}
+
+// WithCodeLineNumbers numbers the lines of code blocks, right-aligned in a
+// gutter left of the code in the theme's CodeLineNumber color
+func WithCodeLineNumbers(show bool) Option {
+	return func(c *Converter) {
+		c.codeLineNumbers = show
+	}
+}
+
+// WithLanguageTabWidth sets the tab width of code blocks per language, e.g.
+// {"go": 4, "makefile": 8}. Languages not listed use the default of 4.
+func WithLanguageTabWidth(widths map[string]int) Option {
+	return func(c *Converter) {
+		c.tabWidths = make(map[string]int, len(widths))
+		for language, width := range widths {
+			if width > 0 {
+				c.tabWidths[strings.ToLower(language)] = width
+			}
+		}
+	}
+}
+
+// WithCodeFolding collapses the lines between "// FOLD" and "// ENDFOLD"
+// marker lines ("# FOLD" in languages with # comments) in code blocks into
+// a single dimmed "// ..." line, to hide imports and other boilerplate.
+func WithCodeFolding(folding bool) Option {
+	return func(c *Converter) {
+		c.codeFolding = folding
+	}
+}
+
+// WithDebugDir writes intermediate results of each conversion into dir for
+// debugging: preprocessed.slide, the source as handed to the present parser,
+// and slide-NN.txt per slide with the elements the parser produced (HTML
+// as generated from Markdown). The directory is created if needed.
+func WithDebugDir(dir string) Option {
+	return func(c *Converter) {
+		c.debugDir = dir
+	}
+}
+
+// WithClosingSlide appends a last slide styled like the title slide, e.g.
+// "Thank you" / "Questions?", listing the authors with their contact
+// details from the document header. An empty title adds no slide.
+func WithClosingSlide(title, subtitle string) Option {
+	return func(c *Converter) {
+		c.closingTitle = title
+		c.closingSubtitle = subtitle
+	}
+}
+
+// WithIconSet maps shortcodes to small images drawn inline in slide text,
+// e.g. {"warning": "icons/warning.png"} turns :warning: into the image.
+// Relative paths are resolved against the slide file. Unknown shortcodes stay
+// as written.
+func WithIconSet(icons map[string]string) Option {
+	return func(c *Converter) {
+		c.icons = make(map[string]string, len(icons))
+		for name, path := range icons {
+			c.icons[strings.Trim(name, ":")] = path
+		}
+	}
+}
+
+// WithMissingImagePlaceholder draws a dashed box reading "image not found:
+// NAME" where a missing image would go, instead of leaving a gap, so
+// reviewers notice it. The warning is printed either way.
+func WithMissingImagePlaceholder(placeholder bool) Option {
+	return func(c *Converter) {
+		c.imagePlaceholder = placeholder
+	}
+}
+
+// WithAllowRemoteImages downloads images referenced by http or https URL
+// (.image and Markdown images) instead of reporting them as missing. Each
+// download is limited in time and size. Off by default, so converting a deck
+// makes no network requests.
+func WithAllowRemoteImages(allow bool) Option {
+	return func(c *Converter) {
+		c.allowRemoteImages = allow
+	}
+}
+
+// WithPageNumbers draws a small "n / total" counter at the bottom right of
+// every content slide (not the title slide) in a muted color. A WithFooter
+// template with a right part takes its place.
+func WithPageNumbers(show bool) Option {
+	return func(c *Converter) {
+		c.pageNumbers = show
+	}
+}
+
+// WithAutoFit shrinks the text and code of a slide that does not fit, to the
+// largest size at which it does (but not below two thirds, about 12pt list
+// text), and reports the scale chosen. Unlike WithAutoGlobalFontScale only
+// the overflowing slides change.
+func WithAutoFit(autoFit bool) Option {
+	return func(c *Converter) {
+		c.autoFit = autoFit
+	}
+}
+
+// WithContinuation continues a slide whose content does not fit on extra
+// pages titled "TITLE (cont.)", instead of dropping the elements past the
+// bottom. Long code blocks are split across the pages rather than truncated.
+// Handouts keep the default truncation.
+func WithContinuation(continuation bool) Option {
+	return func(c *Converter) {
+		c.continuation = continuation
+	}
+}
+
+// WithCornerSlideNumber draws the slide number large and muted in a bottom
+// corner of every content slide, readable from the back of the room when
+// the audience asks about a slide. It is independent of WithFooter.
+func WithCornerSlideNumber(show bool) Option {
+	return func(c *Converter) {
+		c.cornerNumber = show
+	}
+}
+
+// WithNavDots draws a strip of small dots, one per slide, at the bottom of
+// every content slide for on-screen navigation: each dot links to its slide
+// and the current slide's dot is highlighted. Decks with too many slides for
+// the strip to fit get no dots.
+func WithNavDots(show bool) Option {
+	return func(c *Converter) {
+		c.navDots = show
+	}
+}
+
+// WithAutoGlobalFontScale shrinks body text and code by one factor on every
+// slide, chosen so the fullest slide fits, for decks where shrinking only
+// the dense slides (WithListAutoFit) would look inconsistent. Decks that fit
+// are left at the normal size; the scale does not go below 0.6.
+func WithAutoGlobalFontScale(auto bool) Option {
+	return func(c *Converter) {
+		c.autoFontScale = auto
+	}
+}
+
+// WithGenerationStamp prints "Generated DATE by present2pdf VERSION" in small
+// type at the bottom of every slide, to tell which build produced a shared
+// PDF. The version is set with WithToolVersion.
+func WithGenerationStamp(stamp bool) Option {
+	return func(c *Converter) {
+		c.stamp = stamp
+	}
+}
+
+// WithToolVersion sets the version of the program using the converter, as
+// shown by WithGenerationStamp
+func WithToolVersion(version string) Option {
+	return func(c *Converter) {
+		c.toolVersion = version
+	}
+}
+
+// WithPreserveLineBreaks renders every source line of a text paragraph on
+// its own line, for addresses or poems, instead of reflowing the paragraph.
+// In Markdown decks, <br> line breaks are kept as well.
+func WithPreserveLineBreaks(preserve bool) Option {
+	return func(c *Converter) {
+		c.preserveLineBreaks = preserve
+	}
+}
+
+// WithLanguage sets the language of the deck, "en" (the default) or "ru", so
+// month names in dates are spelled in it. The front-matter key "lang" sets
+// it too. Cyrillic month names are covered by the built-in fonts.
+func WithLanguage(lang string) Option {
+	return func(c *Converter) {
+		c.language = lang
+		c.explicit["lang"] = true
+	}
+}
+
+// WithDateFormat sets the Go time layout of the title slide and {date}
+// footer dates, e.g. "2 January 2006" or "2006-01-02". Month names follow
+// WithLanguage. A locale name, "en" or "ru", picks the usual long form and
+// month names of that language instead. The default is "January 2, 2006",
+// or the usual long form of WithLanguage.
+func WithDateFormat(layout string) Option {
+	return func(c *Converter) {
+		c.dateFormat = layout
+	}
+}
+
+// WithGrayscale draws every theme and code token color as the gray of the
+// same luminance, for black-and-white printing. Contrast between colors is
+// kept; images keep their colors.
+func WithGrayscale(gray bool) Option {
+	return func(c *Converter) {
+		c.grayscale = gray
+	}
+}
+
+// WithBookmarks adds a PDF outline (bookmarks) with an entry for every slide
+// title and, nested below it, the slide's subsections. Enabled by default.
+func WithBookmarks(bookmarks bool) Option {
+	return func(c *Converter) {
+		c.bookmarks = bookmarks
+	}
+}
+
+// WithFooter draws a footer on every content slide. The template may use
+// {title} (deck title), {section} (slide title), {n} (slide number),
+// {total} (slide count) and {date}, and is split on "|" into left, center
+// and right parts, e.g. "{title}|{date}|{n}/{total}". A template without
+// "|" is centered.
+func WithFooter(template string) Option {
+	return func(c *Converter) {
+		c.footer = template
+	}
+}
+
+// WithHeadingFontTTF uses the given TrueType font data for the title slide
+// and slide titles. Custom fonts switch the document to UTF-8 fonts (as with
+// WithFontSubsetting), so the font must cover every script used in titles.
+func WithHeadingFontTTF(ttf []byte) Option {
+	return func(c *Converter) {
+		c.headingTTF = ttf
+	}
+}
+
+// WithBodyFontTTF uses the given TrueType font data for paragraphs, lists and
+// other body text, like WithHeadingFontTTF does for titles
+func WithBodyFontTTF(ttf []byte) Option {
+	return func(c *Converter) {
+		c.bodyTTF = ttf
+	}
+}
+
+// WithBodyFont selects the embedded font family of the slide text by name,
+// one of GetAvailableFonts (default "helvetica"). Titles use it too unless
+// WithHeadingFontTTF sets their font, and WithBodyFontTTF takes precedence.
+// Converting fails if the name is unknown.
+func WithBodyFont(name string) Option {
+	return func(c *Converter) {
+		c.bodyFont = strings.ToLower(name)
+	}
+}
+
+// WithTimingSummary appends a slide listing the duration of each section
+// annotated with <!-- time: 2m --> and the total length of the talk
+func WithTimingSummary(summary bool) Option {
+	return func(c *Converter) {
+		c.timingSummary = summary
+	}
+}
+
 // NewConverter creates a new converter instance with optional configuration
 func NewConverter(opts ...Option) *Converter {
 	// Default configuration
 	c := &Converter{
-		codeTheme: "monokai",
-		theme:     LightTheme,
+		codeTheme:         "monokai",
+		codeThemeFallback: "monokai",
+		theme:             LightTheme,
+		pageWidth:         a4Width,
+		pageHeight:        a4Height,
+		explicit:          make(map[string]bool),
+		maxOverflow:       -1,
+		paragraphSpacing:  5,
+		autoLink:          true,
+		codeFontSize:      defaultCodeFontSize,
+		bodyFont:          defaultBodyFont,
+		maxCodeLines:      defaultMaxCodeLines,
+		codePadH:          defaultCodePadH,
+		codePadV:          defaultCodePadV,
+		listBullets:       defaultListBullets,
+		defaultLanguage:   "go",
+		listIndent:        defaultListIndent,
+		textScale:         1,
+		fontScale:         1,
+		bookmarks:         true,
 	}
 
 	// Apply options
@@ -85,6 +747,9 @@ func NewConverter(opts ...Option) *Converter {
 		opt(c)
 	}
 
+	c.region = computeRegion(c.pageWidth, c.pageHeight, c.contentAspect)
+	c.saveSettings()
+
 	return c
 }
 
@@ -92,57 +757,165 @@ func NewConverter(opts ...Option) *Converter {
 // registers fonts and initializes the Cyrillic translator.
 // Returns a cleanup function that removes the temp directory.
 func (c *Converter) initPDF() (func(), error) {
+	if err := c.checkBodyFont(); err != nil {
+		return nil, err
+	}
 	tmpDir, err := os.MkdirTemp("", "present2pdf-fonts-*")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp dir: %w", err)
 	}
 
-	// Write embedded font files to temp directory
-	fontFiles := map[string][]byte{
-		"cp1251.map":                   cp1251Map,
-		"helvetica_1251.json":          helvetica1251JSON,
-		"helvetica_1251.z":             helvetica1251Z,
-		"jetbrainsmono_1251.json":      jetbrainsmono1251JSON,
-		"jetbrainsmono_1251.z":         jetbrainsmono1251Z,
-		"jetbrainsmono_bold_1251.json": jetbrainsmono1251BoldJSON,
-		"jetbrainsmono_bold_1251.z":    jetbrainsmono1251BoldZ,
-	}
-
-	for filename, data := range fontFiles {
-		if err := os.WriteFile(tmpDir+"/"+filename, data, 0644); err != nil {
+	// Write the cp1251 fonts for gofpdf to read, including the text font
+	// variants registered later by addTextStyle
+	textFont := textFonts[c.bodyFont]
+	if !c.utf8Fonts() {
+		files := []string{"jetbrainsmono_1251", "jetbrainsmono_bold_1251"}
+		for _, name := range textFont {
+			files = append(files, name)
+		}
+		cp1251Map, err := fontFS.ReadFile("font/cp1251.map")
+		if err == nil {
+			err = os.WriteFile(filepath.Join(tmpDir, "cp1251.map"), cp1251Map, 0644)
+		}
+		for _, name := range files {
+			if err == nil {
+				err = writeEmbeddedFont(tmpDir, name)
+			}
+		}
+		if err != nil {
 			os.RemoveAll(tmpDir)
-			return nil, fmt.Errorf("failed to write font file %s: %w", filename, err)
+			return nil, err
 		}
 	}
 
-	c.pdf = gofpdf.New("L", "mm", "A4", tmpDir)
+	c.pdf = gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "L",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: c.pageHeight, Ht: c.pageWidth},
+		FontDirStr:     tmpDir,
+	})
 	c.pdf.SetAutoPageBreak(false, 0)
+	c.region = computeRegion(c.pageWidth, c.pageHeight, c.contentAspect)
+
+	symbolFont, err := inflateFont(dejavuSansMonoZ)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to load symbol font: %w", err)
+	}
+	c.pdf.AddUTF8FontFromBytes(symbolFontFamily, "", symbolFont)
 
-	fonts := []struct{ family, style, file string }{
-		{"Helvetica", "", "helvetica_1251.json"},
-		{"Helvetica", "B", "helvetica_1251.json"},
-		{"Helvetica", "I", "helvetica_1251.json"},
-		{"JetBrainsMono", "", "jetbrainsmono_1251.json"},
-		{"JetBrainsMono", "B", "jetbrainsmono_bold_1251.json"},
+	emojiFont, err := inflateFont(fontAwesomeZ)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to load emoji font: %w", err)
+	}
+	c.pdf.AddUTF8FontFromBytes(emojiFontFamily, "", emojiFont)
+
+	fonts := []struct{ family, style, name string }{
+		{c.bodyFont, "", textFont[""]},
+		{"JetBrainsMono", "", "jetbrainsmono_1251"},
+		{"JetBrainsMono", "B", "jetbrainsmono_bold_1251"},
 	}
 	for _, f := range fonts {
-		c.pdf.AddFont(f.family, f.style, f.file)
+		if err := c.addEmbeddedFont(f.family, f.style, f.name); err != nil {
+			os.RemoveAll(tmpDir)
+			return nil, err
+		}
 	}
 
-	c.translator = c.pdf.UnicodeTranslatorFromDescriptor("cp1251")
+	if c.utf8Fonts() {
+		c.translator = func(s string) string { return s }
+
+		for _, f := range []struct {
+			family string
+			ttf    []byte
+		}{
+			{headingFontFamily, c.headingTTF},
+			{bodyFontFamily, c.bodyTTF},
+		} {
+			if f.ttf == nil {
+				continue
+			}
+			// gofpdf only logs unreadable font data, so check it up front
+			if !isTrueType(f.ttf) {
+				os.RemoveAll(tmpDir)
+				return nil, fmt.Errorf("failed to load %s font: not a TrueType font", strings.ToLower(f.family))
+			}
+			c.pdf.AddUTF8FontFromBytes(f.family, "", f.ttf)
+		}
+	} else {
+		translate := c.pdf.UnicodeTranslatorFromDescriptor("cp1251")
+		c.translator = func(s string) string { return translate(cp1251Fallbacks.Replace(s)) }
+	}
 
 	return func() { os.RemoveAll(tmpDir) }, nil
 }
 
-// setTextFont sets the text font with the given style and size
-// Uses Helvetica (the only one with proper Cyrillic support). Bold/italic — visual simulation
+// isTrueType reports whether data starts with a TrueType font signature
+func isTrueType(data []byte) bool {
+	return bytes.HasPrefix(data, []byte{0, 1, 0, 0}) || bytes.HasPrefix(data, []byte("true"))
+}
+
+// inflateFont decompresses an embedded .z font file back to TrueType data
+func inflateFont(z []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(z))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// setTextFont sets the text font with the given style ("", "B", "I" or "BI")
+// and size. A WithBodyFontTTF font has a single variant, used for every style.
 func (c *Converter) setTextFont(style string, size float64) {
-	c.pdf.SetFont("Helvetica", "", size)
+	if !c.realTextStyles() {
+		c.setFont(bodyFontFamily, "", size)
+		return
+	}
+	c.addTextStyle(style)
+	c.setFont(c.bodyFont, style, size)
+}
+
+// realTextStyles reports whether the text font has bold and italic variants.
+// Without them, formatted text simulates the styles.
+func (c *Converter) realTextStyles() bool {
+	return c.bodyTTF == nil
+}
+
+// setHeadingFont sets the font for titles, falling back to the text font
+func (c *Converter) setHeadingFont(style string, size float64) {
+	if c.headingTTF != nil {
+		c.setFont(headingFontFamily, "", size)
+		return
+	}
+	c.setTextFont(style, size)
+}
+
+// utf8Fonts reports whether fonts are registered as UTF-8 fonts rather than
+// the cp1251-encoded ones
+func (c *Converter) utf8Fonts() bool {
+	return c.subsetFonts || c.rtl || c.headingTTF != nil || c.bodyTTF != nil
 }
 
 // setCodeFont sets the code font with the given style and size
 func (c *Converter) setCodeFont(style string, size float64) {
-	c.pdf.SetFont("JetBrainsMono", style, size)
+	c.setFont("JetBrainsMono", style, size)
+}
+
+// setFont selects a font and remembers it, so that drawText can switch to
+// the symbol font and back
+func (c *Converter) setFont(family, style string, size float64) {
+	c.fontFamily, c.fontStyle = family, style
+	c.pdf.SetFont(family, style, size)
+}
+
+// currentFont returns a function selecting the font last set with setFont
+// again, at the current size
+func (c *Converter) currentFont() func() {
+	family, style := c.fontFamily, c.fontStyle
+	size, _ := c.pdf.GetFontSize()
+	return func() { c.pdf.SetFont(family, style, size) }
 }
 
 // preprocessMarkdownComments escapes lines inside ``` code blocks that the
@@ -174,48 +947,375 @@ func preprocessMarkdownComments(content []byte) []byte {
 
 // Convert converts a .slide file to PDF
 func (c *Converter) Convert(inputPath, outputPath string) error {
+	_, err := c.ConvertTo(inputPath, outputPath)
+	return err
+}
+
+// Result summarizes a conversion
+type Result struct {
+	Title    string      // Presentation title
+	Slides   int         // Number of slides, including the title slide
+	Pages    int         // Number of PDF pages (differs from Slides for handouts and notes pages)
+	Warnings []Warning   // Diagnostics; Warning.Slide tells which slide each belongs to
+	Images   []ImageInfo // Images placed, with their alt text
+	Bytes    int64       // Size of the written PDF
+
+	// FontScale is the scale of body text and code on every slide, below 1
+	// when WithAutoGlobalFontScale shrank the deck
+	FontScale float64
+
+	// Destinations maps the named destinations of the slides (open them
+	// with file.pdf#nameddest=NAME) to their 1-based page numbers
+	Destinations map[string]int
+}
+
+// ConvertTo converts a .slide file to PDF like Convert and returns a summary
+// of the conversion
+func (c *Converter) ConvertTo(inputPath, outputPath string) (*Result, error) {
 	// Read the slide file
-	content, err := os.ReadFile(inputPath)
+	original, err := os.ReadFile(inputPath)
 	if err != nil {
-		return fmt.Errorf("failed to read input file: %w", err)
+		return nil, fmt.Errorf("failed to read input file: %w", err)
 	}
 
-	content = preprocessMarkdownComments(content)
+	var buf bytes.Buffer
+	res, err := c.convert(original, inputPath, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to save PDF: %w", err)
+	}
+	return res, nil
+}
 
-	// Parse the presentation
-	ctx := present.Context{
-		ReadFile: func(name string) ([]byte, error) {
-			return os.ReadFile(name)
-		},
+// streamInputName is the file name parse errors of ConvertStream report
+const streamInputName = "<input>"
+
+// ConvertStream converts slides read from r and writes the PDF to w, without
+// touching the filesystem except for files the slides reference: images,
+// .code files and the like are resolved against slideDir
+func (c *Converter) ConvertStream(r io.Reader, w io.Writer, slideDir string) error {
+	original, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
 	}
+	_, err = c.convert(original, filepath.Join(slideDir, streamInputName), w)
+	return err
+}
 
-	doc, err := ctx.Parse(bytes.NewReader(content), inputPath, 0)
+// ConvertBytes converts slide source held in memory, e.g. a request body,
+// and returns the PDF. Files the slides reference are resolved against
+// slideDir, as with ConvertStream.
+func (c *Converter) ConvertBytes(content []byte, slideDir string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := c.convert(content, filepath.Join(slideDir, streamInputName), &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// prepareSource applies the settings of the slide source original (its
+// front matter, then the theme and its overrides) and returns the source
+// ready to parse
+func (c *Converter) prepareSource(original []byte) ([]byte, error) {
+	c.restoreSettings()
+	if err := c.checkAudience(); err != nil {
+		return nil, err
+	}
+	c.checkHeader(original)
+
+	meta, content := splitFrontMatter(original)
+	c.applyFrontMatter(meta)
+	c.resolveCodeTheme()
+	c.resolveLanguage()
+	if c.autoTheme {
+		c.theme = themeForCodeStyle(c.codeTheme)
+	}
+	if err := c.loadThemeFile(); err != nil {
+		return nil, err
+	}
+	for _, override := range c.themeOverrides {
+		override(&c.theme)
+	}
+	c.applyGrayscale()
+
+	return c.preprocess(content), nil
+}
+
+// convert converts the slide source original, read from inputPath, writes
+// the PDF to w and returns a summary of the conversion
+func (c *Converter) convert(original []byte, inputPath string, w io.Writer) (*Result, error) {
+	c.warnings = nil
+	c.images = nil
+	c.generated = time.Now()
+	c.currentSlideNumber = 0
+	c.currentSlideTitle = ""
+	content, err := c.prepareSource(original)
 	if err != nil {
-		return fmt.Errorf("failed to parse presentation: %w", err)
+		return nil, err
+	}
+	if err := c.writeDebugSource(content); err != nil {
+		return nil, err
+	}
+
+	// Parse the presentation
+	doc, err := parseDoc(content, inputPath)
+	if err != nil {
+		return nil, newParseError(original, content, inputPath, err)
+	}
+	c.applyCodeLanguages(doc)
+	c.filterAudience(doc)
+	if err := c.writeDebugSections(doc); err != nil {
+		return nil, err
 	}
 
 	c.slideDir = filepath.Dir(inputPath)
 
 	cleanup, err := c.initPDF()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer cleanup()
 
+	c.setMetadata(doc)
+	c.registerAnchors(doc)
+	c.registerDestinations(doc)
+	c.footerDoc(doc)
+	c.prepareLogo()
+	defer c.prepareImageFiles()()
+	cleanupScratch, err := c.prepareScratch()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanupScratch()
+
+	codeFontSize := c.codeFontSize
+	defer c.setFontScale(1, codeFontSize)
+	if c.autoFontScale {
+		scale, err := c.globalFontScale(doc, codeFontSize)
+		if err != nil {
+			return nil, err
+		}
+		c.debugf("global font scale %.2f", scale)
+		c.setFontScale(scale, codeFontSize)
+	}
+	if c.timingSummary {
+		c.totalSlides++
+	}
+	if c.closingTitle != "" {
+		c.totalSlides++
+	}
+
 	// Render title slide
 	c.currentSlideNumber = 1
 	c.renderTitleSlide(doc)
+	c.endSlide()
+	c.notesPage(func() { c.renderTitleSlide(doc) }, nil)
 
 	// Render each section as a slide
 	for i, section := range doc.Sections {
 		c.currentSlideNumber = i + 2
 		c.renderSlide(section)
+		c.endSlide()
+		c.notesPage(func() { c.renderSlide(section) }, section.Notes)
+	}
+
+	if c.timingSummary {
+		c.currentSlideNumber = len(doc.Sections) + 2
+		summary := timingSummarySection(doc, c.timings)
+		c.renderSlide(summary)
+		c.endSlide()
+		c.notesPage(func() { c.renderSlide(summary) }, nil)
+	}
+
+	if c.closingTitle != "" {
+		c.currentSlideNumber = c.totalSlides
+		c.currentSlideTitle = c.closingTitle
+		c.renderClosingSlide(doc)
+		c.endSlide()
+		c.notesPage(func() { c.renderClosingSlide(doc) }, nil)
+	}
+
+	if err := c.checkOverflow(); err != nil {
+		return nil, err
 	}
 
 	// Save PDF
-	if err := c.pdf.OutputFileAndClose(outputPath); err != nil {
-		return fmt.Errorf("failed to save PDF: %w", err)
+	var buf bytes.Buffer
+	if err := c.pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to save PDF: %w", err)
+	}
+	data, err := addNamedDests(buf.Bytes(), c.destPages)
+	if err != nil {
+		c.warnf("named destinations not added: %v", err)
+		data = buf.Bytes()
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to save PDF: %w", err)
+	}
+	return c.result(int64(len(data))), nil
+}
+
+// result builds the Result of a conversion that produced a PDF of size bytes
+func (c *Converter) result(size int64) *Result {
+	return &Result{
+		Title:    c.deckTitle,
+		Slides:   c.totalSlides,
+		Pages:    c.pdf.PageCount(),
+		Warnings: c.warnings,
+		Images:   c.images,
+		Bytes:    size,
+
+		Destinations: c.destPages,
+		FontScale:    c.fontScale,
 	}
+}
 
-	return nil
+// parseDoc parses preprocessed slide content
+func parseDoc(content []byte, inputPath string) (*present.Doc, error) {
+	ctx := present.Context{
+		ReadFile: func(name string) ([]byte, error) {
+			return os.ReadFile(name)
+		},
+	}
+
+	if len(bytes.TrimSpace(content)) == 0 {
+		return &present.Doc{}, nil // an empty deck has a blank title slide
+	}
+
+	// The present parser reads the authors up to the first section and
+	// fails at the end of a deck without any, so one is added and removed
+	content, added := addSectionSentinel(content)
+	doc, err := ctx.Parse(bytes.NewReader(content), inputPath, 0)
+	if err != nil {
+		return nil, err
+	}
+	if added && len(doc.Sections) > 0 {
+		doc.Sections = doc.Sections[:len(doc.Sections)-1]
+	}
+	return doc, nil
+}
+
+// sentinelSection is the title of the section addSectionSentinel appends
+const sentinelSection = "present2pdf-sentinel"
+
+// addSectionSentinel appends a section to a deck that has none, so the
+// present parser accepts a header-only deck. Like the parser, it takes a
+// deck whose first line starts with "# " to be Markdown.
+func addSectionSentinel(content []byte) ([]byte, bool) {
+	lines := strings.Split(string(content), "\n")
+	prefix := "*"
+	for _, line := range lines {
+		if line != "" {
+			if strings.HasPrefix(line, "# ") {
+				prefix = "##"
+			}
+			break
+		}
+	}
+	for _, line := range lines {
+		if line == prefix || strings.HasPrefix(line, prefix+" ") {
+			return content, false
+		}
+	}
+	return append(bytes.TrimRight(content, "\n"), []byte("\n\n\n"+prefix+" "+sentinelSection+"\n")...), true
+}
+
+// preprocess turns the slide source (without front matter) into what the
+// present parser reads, recording the .iframe posters, code block languages
+// and slide timings it takes out
+func (c *Converter) preprocess(content []byte) []byte {
+	c.iframePosters, content = extractIframePosters(content)
+	c.codeLanguages, content = extractCodeLanguages(content)
+	content = isolateAudienceMarkers(content)
+	content = c.extractTimings(content)
+	content = preprocessReferenceLinks(content)
+	return preprocessMarkdownComments(content)
+}
+
+// SlideTitles parses a .slide file and returns the slide titles in rendering
+// order: the presentation title first, then each section title. opts select
+// the content like for a conversion, e.g. WithAudience. It does not load
+// fonts or render anything, so it is cheap enough for progress reporting.
+func SlideTitles(inputPath string, opts ...Option) ([]string, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	original := content
+	_, content = splitFrontMatter(content)
+	c := NewConverter(opts...)
+	c.quiet = true
+	if err := c.checkAudience(); err != nil {
+		return nil, err
+	}
+	content = c.preprocess(content)
+
+	doc, err := parseDoc(content, inputPath)
+	if err != nil {
+		return nil, newParseError(original, content, inputPath, err)
+	}
+	c.filterAudience(doc)
+
+	titles := make([]string, 0, len(doc.Sections)+1)
+	titles = append(titles, doc.Title)
+	for _, section := range doc.Sections {
+		titles = append(titles, section.Title)
+	}
+	return titles, nil
+}
+
+// CheckImages returns the image files referenced by the slides (.image,
+// Markdown images, .iframe posters and .background images) that do not
+// exist, resolved against the slide directory, so missing files can be
+// reported before converting. Remote images are skipped. opts select the
+// content like for a conversion, e.g. WithAudience.
+func CheckImages(inputPath string, opts ...Option) ([]string, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	original := content
+	_, content = splitFrontMatter(content)
+	c := NewConverter(opts...)
+	c.quiet = true
+	if err := c.checkAudience(); err != nil {
+		return nil, err
+	}
+	content = c.preprocess(content)
+	posters := c.iframePosters
+
+	doc, err := parseDoc(content, inputPath)
+	if err != nil {
+		return nil, newParseError(original, content, inputPath, err)
+	}
+	c.filterAudience(doc)
+
+	var refs []string
+	for _, section := range doc.Sections {
+		refs = append(refs, imageRefs([]present.Elem{section}, posters)...)
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if isRemoteURL(ref) {
+			continue
+		}
+		path := ref
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(inputPath), path)
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if _, err := os.Stat(path); err != nil {
+			missing = append(missing, path)
+		}
+	}
+	return missing, nil
 }
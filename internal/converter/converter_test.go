@@ -2,17 +2,34 @@ package converter
 
 import (
 	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
 	"image"
 	"image/color"
+	"image/jpeg"
 	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/jung-kurt/gofpdf"
 	"golang.org/x/tools/present"
@@ -138,8 +155,9 @@ func TestConvertInvalidSlideFile(t *testing.T) {
 	}
 	defer os.Remove(tmpFile.Name())
 
-	// Write invalid content
-	if _, err := tmpFile.Write([]byte("Invalid content\nwithout proper format")); err != nil {
+	// Write invalid content: a header-only deck is valid, but not one with
+	// more header lines than a title and a subtitle
+	if _, err := tmpFile.Write([]byte("Invalid content\nwithout proper format\nand an unexpected header line")); err != nil {
 		t.Fatalf("Failed to write temp file: %v", err)
 	}
 	tmpFile.Close()
@@ -631,6 +649,31 @@ func TestDetectLanguage(t *testing.T) {
 	}
 }
 
+func TestRegisterLanguageExtension(t *testing.T) {
+	RegisterLanguageExtension(".jsonnet", "jsonnet")
+	RegisterLanguageExtension("tf", "terraform")
+	t.Cleanup(func() {
+		languageExtensionsMu.Lock()
+		delete(languageExtensions, "jsonnet")
+		delete(languageExtensions, "tf")
+		languageExtensionsMu.Unlock()
+	})
+
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"config.jsonnet", "jsonnet"},
+		{"main.tf", "terraform"},
+		{"main.go", "go"},
+	}
+	for _, tt := range tests {
+		if got := detectLanguage(tt.filename); got != tt.want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
 func TestRenderCodeWithSyntaxHighlighting(t *testing.T) {
 	// Test that code rendering with syntax highlighting works
 	slideContent := `# Syntax Highlighting Test
@@ -699,7 +742,7 @@ func TestRenderCodePlain(t *testing.T) {
 	// Initialize translator for UTF-8 support (cp1251 for Cyrillic)
 	conv.translator = conv.pdf.UnicodeTranslatorFromDescriptor("cp1251")
 
-	y := conv.renderCodePlain("test code\nline 2", 40.0)
+	y := conv.renderCodePlain("test code\nline 2", 1, 40.0)
 
 	if y <= 40.0 {
 		t.Errorf("renderCodePlain() did not advance Y position")
@@ -1204,6 +1247,27 @@ func TestParseHTMLFormatting(t *testing.T) {
 				{Text: "italic", Italic: true},
 			},
 		},
+		{
+			name:  "keyboard keys",
+			input: "Press <kbd>Ctrl</kbd>+<kbd>C</kbd> to copy",
+			wantFrags: []TextFragment{
+				{Text: "Press "},
+				{Text: "Ctrl", Kbd: true},
+				{Text: "+"},
+				{Text: "C", Kbd: true},
+				{Text: " to copy"},
+			},
+		},
+		{
+			name:  "highlighted text",
+			input: "a <mark>key <b>point</b></mark> here",
+			wantFrags: []TextFragment{
+				{Text: "a "},
+				{Text: "key ", Mark: true},
+				{Text: "point", Mark: true, Bold: true},
+				{Text: " here"},
+			},
+		},
 		{
 			name:  "link with href",
 			input: `<a href="https://example.com">click here</a>`,
@@ -1218,6 +1282,13 @@ func TestParseHTMLFormatting(t *testing.T) {
 				{Text: "Go", URL: "https://golang.org"},
 			},
 		},
+		{
+			name:  "link with entity in href",
+			input: `<a href="https://example.com/search?a=1&amp;b=2">A &amp; B</a>`,
+			wantFrags: []TextFragment{
+				{Text: "A & B", URL: "https://example.com/search?a=1&b=2"},
+			},
+		},
 		{
 			name:  "text before and after link",
 			input: `Visit <a href="https://go.dev">Go</a> now.`,
@@ -1266,6 +1337,53 @@ func TestParseHTMLFormatting(t *testing.T) {
 				{Text: " here"},
 			},
 		},
+		{
+			name:  "bold code",
+			input: "<strong><code>x</code></strong>",
+			wantFrags: []TextFragment{
+				{Text: "x", Bold: true, Code: true},
+			},
+		},
+		{
+			name:  "italic code",
+			input: "<em>see <code>f()</code></em>",
+			wantFrags: []TextFragment{
+				{Text: "see ", Italic: true},
+				{Text: "f()", Italic: true, Code: true},
+			},
+		},
+		{
+			name:  "bold italic link",
+			input: `<strong><em><a href="https://go.dev">Go</a></em></strong>`,
+			wantFrags: []TextFragment{
+				{Text: "Go", Bold: true, Italic: true, URL: "https://go.dev"},
+			},
+		},
+		{
+			name:  "all four styles",
+			input: `<a href="https://pkg.go.dev/fmt"><strong><em><code>fmt.Println</code></em></strong></a>`,
+			wantFrags: []TextFragment{
+				{Text: "fmt.Println", Bold: true, Italic: true, Code: true, URL: "https://pkg.go.dev/fmt"},
+			},
+		},
+		{
+			name:  "nested bold",
+			input: "<b>a <strong>b</strong> c</b> d",
+			wantFrags: []TextFragment{
+				{Text: "a ", Bold: true},
+				{Text: "b", Bold: true},
+				{Text: " c", Bold: true},
+				{Text: " d"},
+			},
+		},
+		{
+			name:  "stray closing tag",
+			input: "</em>a <em>b</em>",
+			wantFrags: []TextFragment{
+				{Text: "a "},
+				{Text: "b", Italic: true},
+			},
+		},
 		{
 			name:      "empty input",
 			input:     "",
@@ -1598,6 +1716,49 @@ func TestRenderHTMLBlockquote(t *testing.T) {
 	}
 }
 
+func TestRenderHTMLBlockquoteWithCode(t *testing.T) {
+	const html = "<blockquote>\n<p>Note: print with</p>\n<pre><code class=\"language-go\">fmt.Println(&quot;hi&quot;)\nreturn\n</code></pre>\n</blockquote>"
+
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.pdf.AddPage()
+
+	startY := conv.contentTop()
+	endY := conv.renderHTMLBlockquote(html, startY)
+
+	// Paragraph, spacing and a two-line code block inside the padding
+	codeHeight := 2*conv.codeLineHeight() + 2*conv.codePadV
+	if minHeight := 11 + 3 + codeHeight + 8; endY-startY < minHeight {
+		t.Errorf("blockquote height = %.1f, want at least %.1f to cover the code", endY-startY, minHeight)
+	}
+	if conv.codeIndent != 0 {
+		t.Errorf("codeIndent = %.1f after the blockquote, want 0", conv.codeIndent)
+	}
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	pdf := buf.String()
+	for _, want := range []string{"(Println)Tj", "(return)Tj"} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("PDF does not contain highlighted code %s", want)
+		}
+	}
+	if strings.Contains(pdf, "fmt.Println(\"hi\") return") {
+		t.Error("code was flattened into the quote text")
+	}
+
+	// The code box is clipped inside the quote's text inset
+	clip := fmt.Sprintf("q %.2f ", (conv.contentX()+8+conv.codePadH)*conv.pdf.GetConversionRatio())
+	if !strings.Contains(pdf, clip) {
+		t.Errorf("code block is not indented inside the quote (no clip starting %q)", clip)
+	}
+}
+
 func TestConvertMarkdownInlineCode(t *testing.T) {
 	slideContent := `# Inline Code Test
 Test Presentation
@@ -2185,3 +2346,4750 @@ Different themes should have different colors.
 		})
 	}
 }
+
+func TestComputeRegion(t *testing.T) {
+	tests := []struct {
+		name   string
+		aspect float64
+		want   rect
+	}{
+		{"full page", 0, rect{0, 0, 297, 210}},
+		{"wide letterbox", 2.0, rect{0, 30.75, 297, 148.5}},
+		{"tall pillarbox", 1.0, rect{43.5, 0, 210, 210}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeRegion(297, 210, tt.aspect)
+			if got != tt.want {
+				t.Errorf("computeRegion(297, 210, %v) = %+v, want %+v", tt.aspect, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithContentAspect(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "aspect.slide")
+	slideContent := "# Aspect Test\n18 Feb 2026\n\nAuthor\n\n## Wide Slide\n\nContent authored for 16:9.\n\n- One\n- Two\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	conv := NewConverter(WithContentAspect(16.0 / 9.0))
+	if conv.region.H >= 210 || conv.region.W != 297 {
+		t.Errorf("region = %+v, want full width and letterboxed height", conv.region)
+	}
+	if conv.contentBottom() >= conv.region.Y+conv.region.H {
+		t.Errorf("contentBottom() = %.1f, outside region %+v", conv.contentBottom(), conv.region)
+	}
+
+	outputPath := filepath.Join(dir, "aspect.pdf")
+	if err := conv.Convert(slideFile, outputPath); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if info, err := os.Stat(outputPath); err != nil || info.Size() < 1024 {
+		t.Errorf("Output PDF missing or too small: %v", err)
+	}
+}
+
+func TestRenderHighlightedLineAdvance(t *testing.T) {
+	conv := NewConverter()
+	cleanup, err := conv.initPDF()
+	if err != nil {
+		t.Fatalf("initPDF: %v", err)
+	}
+	defer cleanup()
+	conv.pdf.AddPage()
+
+	tokens, err := conv.highlightCode("x:=a+b*c-d/e%f&g|h^i<<j>>k!=l&&m||n;o++\r\n", "go")
+	if err != nil {
+		t.Fatalf("highlightCode() error = %v", err)
+	}
+	lines := splitTokensIntoLines(tokens)
+	if len(lines[0]) < 10 {
+		t.Fatalf("expected many short tokens, got %d", len(lines[0]))
+	}
+
+	conv.setCodeFont("", 11)
+	sum := 0.0
+	for _, tok := range lines[0] {
+		if strings.ContainsAny(tok.Value, "\r\t") {
+			t.Errorf("token %q contains CR or tab after highlighting", tok.Value)
+		}
+		sum += conv.pdf.GetStringWidth(conv.translator(tok.Value))
+	}
+
+	startX := 25.0
+	endX := conv.renderHighlightedLine(lines[0], startX, 50)
+	if diff := endX - startX - sum; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("advanced width = %.4f, want sum of token widths %.4f", endX-startX, sum)
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"crlf", "a\r\nb\r\n", "a\nb\n"},
+		{"lone cr", "a\rb", "a\nb"},
+		{"leading tab", "\tx := 1", "    x := 1"},
+		{"tab stop alignment", "ab\tc", "ab  c"},
+		{"mixed crlf and tabs", "func f() {\r\n\treturn\r\n}", "func f() {\n    return\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeCode(tt.input); got != tt.want {
+				t.Errorf("normalizeCode(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantMeta map[string]string
+		wantRest string
+	}{
+		{
+			name:     "no front matter",
+			input:    "# Title\n",
+			wantMeta: nil,
+			wantRest: "# Title\n",
+		},
+		{
+			name:     "theme and code theme",
+			input:    "---\ntheme: dark\ncode-theme: \"github\"\n---\n# Title\n",
+			wantMeta: map[string]string{"theme": "dark", "code-theme": "github"},
+			wantRest: "# Title\n",
+		},
+		{
+			name:     "comments and blank lines",
+			input:    "---\n# comment\n\ntheme: light\n---\n# Title\n",
+			wantMeta: map[string]string{"theme": "light"},
+			wantRest: "# Title\n",
+		},
+		{
+			name:     "unterminated block",
+			input:    "---\ntheme: dark\n# Title\n",
+			wantMeta: nil,
+			wantRest: "---\ntheme: dark\n# Title\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, rest := splitFrontMatter([]byte(tt.input))
+			if len(meta) != len(tt.wantMeta) {
+				t.Fatalf("meta = %v, want %v", meta, tt.wantMeta)
+			}
+			for k, v := range tt.wantMeta {
+				if meta[k] != v {
+					t.Errorf("meta[%q] = %q, want %q", k, meta[k], v)
+				}
+			}
+			if string(rest) != tt.wantRest {
+				t.Errorf("rest = %q, want %q", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestConvertFrontMatterTheme(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "frontmatter.slide")
+	slideContent := "---\ntheme: dark\ncode-theme: github\n---\n# Front Matter\n18 Feb 2026\n\nAuthor\n\n## Slide\n\nBody text.\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	conv := NewConverter()
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if conv.theme != DarkTheme {
+		t.Error("front matter theme: dark was not applied")
+	}
+	if conv.codeTheme != "github" {
+		t.Errorf("codeTheme = %q, want %q", conv.codeTheme, "github")
+	}
+
+	// Explicit options win over front matter
+	conv = NewConverter(WithTheme("light"))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out2.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if conv.theme != LightTheme {
+		t.Error("explicit WithTheme(\"light\") was overridden by front matter")
+	}
+}
+
+func TestFrontMatterReusedConverter(t *testing.T) {
+	conv := NewConverter(WithQuiet(true))
+	styled := []byte("---\ntheme: dark\ncode-theme: github\npage-size: 16:9\nlang: ru\n---\n# Styled\n\n## Slide\n\nBody\n")
+	if _, err := conv.ConvertBytes(styled, t.TempDir()); err != nil {
+		t.Fatalf("ConvertBytes() error = %v", err)
+	}
+	if conv.theme != DarkTheme || conv.language != "ru" {
+		t.Fatal("front matter of the first deck not applied")
+	}
+
+	// The next deck starts from the options again
+	if _, err := conv.ConvertBytes([]byte("# Plain\n\n## Slide\n\nBody\n"), t.TempDir()); err != nil {
+		t.Fatalf("ConvertBytes() error = %v", err)
+	}
+	if conv.theme != LightTheme {
+		t.Error("theme of the previous deck kept")
+	}
+	if conv.codeTheme != "monokai" {
+		t.Errorf("codeTheme = %q, want monokai", conv.codeTheme)
+	}
+	if conv.pageWidth != a4Width || conv.pageHeight != a4Height {
+		t.Errorf("page size = %vx%v, want A4", conv.pageWidth, conv.pageHeight)
+	}
+	if conv.language != "en" {
+		t.Errorf("language = %q, want en", conv.language)
+	}
+}
+
+func TestDedent(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no indent", "a\n  b", "a\n  b"},
+		{"common tabs", "\t\tfunc f() {\n\t\t\treturn\n\t\t}", "func f() {\n\treturn\n}"},
+		{"common spaces", "    a\n      b\n    c", "a\n  b\nc"},
+		{"blank lines ignored", "    a\n\n    b", "a\n\nb"},
+		{"whitespace-only line", "    a\n  \n    b", "a\n\nb"},
+		{"mixed prefixes", "\t  a\n\tb", "  a\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dedent(tt.input); got != tt.want {
+				t.Errorf("dedent(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertCodeDirectiveIndentedSnippet(t *testing.T) {
+	dir := t.TempDir()
+	goSource := "package main\n\ntype server struct{}\n\nfunc outer() {\n\tfunc() {\n\t\tinner := 1\n\t\t_ = inner\n\t}()\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "nested.go"), []byte(goSource), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	slideContent := "Code Range\n18 Feb 2026\n\nAuthor\n\n* Nested\n\n.code nested.go /^\\tfunc/,/^\\t}/\n"
+	slideFile := filepath.Join(dir, "code.slide")
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ctx := present.Context{ReadFile: os.ReadFile}
+	doc, err := ctx.Parse(strings.NewReader(slideContent), slideFile, 0)
+	if err != nil {
+		t.Fatalf("present.Parse() error = %v", err)
+	}
+	code, ok := doc.Sections[0].Elem[0].(present.Code)
+	if !ok {
+		t.Fatalf("expected present.Code element, got %T", doc.Sections[0].Elem[0])
+	}
+	if !strings.HasPrefix(string(code.Raw), "\tfunc") {
+		t.Fatalf("code.Raw = %q, expected indented snippet", code.Raw)
+	}
+	if got := dedent(string(code.Raw)); !strings.HasPrefix(got, "func() {\n\tinner") {
+		t.Errorf("dedent(code.Raw) = %q, want common indentation removed", got)
+	}
+
+	conv := NewConverter()
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+}
+
+func TestDedentFencedCodeBlocks(t *testing.T) {
+	conv := NewConverter()
+	cleanup, err := conv.initPDF()
+	if err != nil {
+		t.Fatalf("initPDF: %v", err)
+	}
+	defer cleanup()
+	conv.pdf.AddPage()
+
+	tests := []struct {
+		name string
+		html string
+	}{
+		{"plain", "<pre><code>    if x {\n        return\n    }\n</code></pre>"},
+		{"with language", "<pre><code class=\"language-go\">\n    if x {\n        return\n    }\n</code></pre>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, ok := parseHTMLCodeBlock(tt.html)
+			want := "if x {\n    return\n}"
+			if !ok || got != want {
+				t.Errorf("dedented code = %q, want %q", got, want)
+			}
+			if endY := conv.renderHTMLCode(tt.html, 50); endY <= 50 {
+				t.Errorf("renderHTMLCode() did not advance Y: %.1f", endY)
+			}
+		})
+	}
+}
+
+func TestTrimBlankLines(t *testing.T) {
+	got := trimBlankLines("\n  \n    a\n      b\n\n")
+	if want := "    a\n      b"; got != want {
+		t.Errorf("trimBlankLines() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractIframePosters(t *testing.T) {
+	input := "* Demo\n\n.iframe https://example.com/demo 400 600 poster=shot.png\n.iframe https://example.com/other\n"
+	posters, out := extractIframePosters([]byte(input))
+
+	if got := posters["https://example.com/demo"]; got != "shot.png" {
+		t.Errorf("poster for demo = %q, want %q", got, "shot.png")
+	}
+	if len(posters) != 1 {
+		t.Errorf("posters = %v, want exactly one entry", posters)
+	}
+	if want := ".iframe https://example.com/demo 400 600\n"; !strings.Contains(string(out), want) {
+		t.Errorf("poster argument not stripped: %q", out)
+	}
+}
+
+func TestConvertIframeWithPoster(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "shot.png"), 640, 360)
+
+	slideContent := "Iframe Test\n18 Feb 2026\n\nAuthor\n\n* Live Demo\n\n.iframe https://example.com/demo 400 600 poster=shot.png\n\n* No Poster\n\n.iframe https://example.com/other\n"
+	slideFile := filepath.Join(dir, "iframe.slide")
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "out.pdf")
+	conv := NewConverter()
+	if err := conv.Convert(slideFile, outputPath); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/Subtype /Image")) {
+		t.Error("poster image was not embedded")
+	}
+	if !bytes.Contains(data, []byte("https://example.com/demo")) {
+		t.Error("live demo link was not created")
+	}
+}
+
+func TestFontSubsettingReducesSize(t *testing.T) {
+	dir := t.TempDir()
+	slideContent := "# Small Deck\n18 Feb 2026\n\nAuthor\n\n## ASCII Slide\n\nJust a little **text**.\n\n```go\nfmt.Println(\"hi\")\n```\n\n## Кириллица\n\nПривет, мир!\n"
+	slideFile := filepath.Join(dir, "small.slide")
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	sizes := make(map[bool]int64)
+	for _, subset := range []bool{false, true} {
+		outputPath := filepath.Join(dir, "out.pdf")
+		conv := NewConverter(WithFontSubsetting(subset))
+		if err := conv.Convert(slideFile, outputPath); err != nil {
+			t.Fatalf("Convert(subset=%v) error = %v", subset, err)
+		}
+		info, err := os.Stat(outputPath)
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		sizes[subset] = info.Size()
+	}
+
+	t.Logf("full fonts: %d bytes, subset fonts: %d bytes", sizes[false], sizes[true])
+	if sizes[true]*4 > sizes[false] {
+		t.Errorf("subset output %d bytes is not substantially smaller than full %d bytes", sizes[true], sizes[false])
+	}
+}
+
+func BenchmarkConvertFontSubsetting(b *testing.B) {
+	dir := b.TempDir()
+	slideFile := filepath.Join(dir, "bench.slide")
+	slideContent := "# Bench\n18 Feb 2026\n\nAuthor\n\n## Slide\n\nSome text.\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		b.Fatalf("WriteFile: %v", err)
+	}
+
+	for _, subset := range []bool{false, true} {
+		name := "full"
+		if subset {
+			name = "subset"
+		}
+		b.Run(name, func(b *testing.B) {
+			outputPath := filepath.Join(dir, name+".pdf")
+			for i := 0; i < b.N; i++ {
+				if err := NewConverter(WithFontSubsetting(subset)).Convert(slideFile, outputPath); err != nil {
+					b.Fatalf("Convert() error = %v", err)
+				}
+			}
+			if info, err := os.Stat(outputPath); err == nil {
+				b.ReportMetric(float64(info.Size()), "bytes/pdf")
+			}
+		})
+	}
+}
+
+func TestSlideOverflowDroppedElements(t *testing.T) {
+	dir := t.TempDir()
+	var sb strings.Builder
+	sb.WriteString("Overflow\n18 Feb 2026\n\nAuthor\n\n* Too Much\n\n")
+	for i := 0; i < 12; i++ {
+		sb.WriteString("Paragraph line of text.\n\n")
+	}
+	sb.WriteString("- last list\n\n.link https://golang.org Go\n")
+	slideFile := filepath.Join(dir, "overflow.slide")
+	if err := os.WriteFile(slideFile, []byte(sb.String()), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	warnings := conv.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("Warnings() = %v, want exactly one overflow warning", warnings)
+	}
+	w := warnings[0]
+	if w.Slide != 2 || w.Title != "Too Much" {
+		t.Errorf("warning slide = %d %q, want 2 \"Too Much\"", w.Slide, w.Title)
+	}
+
+	// 12 paragraphs at 15mm each from y=45 cross the 190mm bottom after the 10th
+	want := []DroppedElement{{10, "text"}, {11, "text"}, {12, "list"}, {13, "link"}}
+	if len(w.Dropped) != len(want) {
+		t.Fatalf("Dropped = %v, want %v", w.Dropped, want)
+	}
+	for i := range want {
+		if w.Dropped[i] != want[i] {
+			t.Errorf("Dropped[%d] = %v, want %v", i, w.Dropped[i], want[i])
+		}
+	}
+
+	// Strict mode: more than 2 dropped elements fails the conversion
+	conv = NewConverter(WithQuiet(true), WithMaxSlideOverflow(2))
+	err := conv.Convert(slideFile, filepath.Join(dir, "strict.pdf"))
+	if err == nil {
+		t.Fatal("Convert() with WithMaxSlideOverflow(2) expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "list #12") {
+		t.Errorf("error %q does not list the dropped elements", err)
+	}
+}
+
+func TestWithThemeOverride(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "override.slide")
+	slideContent := "# Override\n18 Feb 2026\n\nAuthor\n\n## Links\n\nVisit [Go](https://golang.org).\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	orange := RGB{255, 184, 108}
+	conv := NewConverter(
+		WithTheme("dark"),
+		WithThemeOverride(func(t *Theme) { t.LinkColor = orange }),
+	)
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	want := DarkTheme
+	want.LinkColor = orange
+	if conv.theme != want {
+		t.Errorf("theme = %+v, want DarkTheme with only LinkColor changed", conv.theme)
+	}
+	if DarkTheme.LinkColor == orange {
+		t.Error("override modified the predefined DarkTheme")
+	}
+}
+
+func TestParagraphSpacing(t *testing.T) {
+	html := "<p>First.</p>\n<p>Second.</p>\n<p>Third.</p>"
+	const lineHeight = 11.0
+
+	for _, spacing := range []float64{0, 2, 5} {
+		conv := NewConverter(WithParagraphSpacing(spacing))
+		conv.pdf = gofpdf.New("L", "mm", "A4", "")
+		conv.pdf.AddPage()
+		conv.translator = conv.pdf.UnicodeTranslatorFromDescriptor("")
+
+		startY := 45.0
+		endY := conv.renderHTMLParagraphs(html, startY)
+		want := startY + 3*(lineHeight+spacing)
+		if endY != want {
+			t.Errorf("spacing %.0f: three one-line paragraphs advanced to %.1f, want %.1f", spacing, endY, want)
+		}
+	}
+
+	if conv := NewConverter(); conv.paragraphSpacing != 5 {
+		t.Errorf("default paragraphSpacing = %.1f, want 5", conv.paragraphSpacing)
+	}
+}
+
+func TestPreprocessReferenceLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "full reference",
+			input: "# T\n\n## S\n\nSee [Go][go].\n\n[go]: https://golang.org\n",
+			want:  "# T\n\n## S\n\nSee [Go](https://golang.org).\n\n",
+		},
+		{
+			name:  "collapsed and shortcut, case-insensitive",
+			input: "# T\n\n## S\n\n[Docs][] and [docs] and [DOCS].\n\n[docs]: <https://pkg.go.dev> \"Go docs\"\n",
+			want:  "# T\n\n## S\n\n[Docs](https://pkg.go.dev) and [docs](https://pkg.go.dev) and [DOCS](https://pkg.go.dev).\n\n",
+		},
+		{
+			name:  "inline links and unknown refs untouched",
+			input: "# T\n\n## S\n\n[a](https://a.example) [b][missing] [go]\n\n[go]: https://golang.org\n",
+			want:  "# T\n\n## S\n\n[a](https://a.example) [b][missing] [go](https://golang.org)\n\n",
+		},
+		{
+			name:  "fenced code untouched",
+			input: "# T\n\n## S\n\n```\n[go]\n```\n\n[go]: https://golang.org\n",
+			want:  "# T\n\n## S\n\n```\n[go]\n```\n\n",
+		},
+		{
+			name:  "legacy format untouched",
+			input: "Title\n\n* S\n\n[go]\n\n[go]: https://golang.org\n",
+			want:  "Title\n\n* S\n\n[go]\n\n[go]: https://golang.org\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(preprocessReferenceLinks([]byte(tt.input)))
+			if got != tt.want {
+				t.Errorf("preprocessReferenceLinks() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertReferenceLinks(t *testing.T) {
+	dir := t.TempDir()
+	slideContent := "# Ref Links\n18 Feb 2026\n\nAuthor\n\n## First\n\nRead the [Go docs][docs].\n\n## Second\n\n- See [Go][go]\n\n[go]: https://golang.org\n[docs]: https://pkg.go.dev\n"
+	slideFile := filepath.Join(dir, "refs.slide")
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "out.pdf")
+	if err := NewConverter().Convert(slideFile, outputPath); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, url := range []string{"https://pkg.go.dev", "https://golang.org"} {
+		if !bytes.Contains(data, []byte("/URI ("+url+")")) {
+			t.Errorf("no clickable link to %s in PDF", url)
+		}
+	}
+}
+
+func TestLinkifyFragments(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []TextFragment
+		want  []TextFragment
+	}{
+		{
+			name:  "bare URL mid-sentence",
+			input: []TextFragment{{Text: "See https://example.com/docs for more."}},
+			want: []TextFragment{
+				{Text: "See "},
+				{Text: "https://example.com/docs", URL: "https://example.com/docs"},
+				{Text: " for more."},
+			},
+		},
+		{
+			name:  "trailing punctuation and parenthesis",
+			input: []TextFragment{{Text: "(http://a.example/x_(y))."}},
+			want: []TextFragment{
+				{Text: "("},
+				{Text: "http://a.example/x_(y)", URL: "http://a.example/x_(y)"},
+				{Text: ")."},
+			},
+		},
+		{
+			name:  "existing link untouched",
+			input: []TextFragment{{Text: "https://golang.org", URL: "https://golang.org"}},
+			want:  []TextFragment{{Text: "https://golang.org", URL: "https://golang.org"}},
+		},
+		{
+			name:  "inline code untouched",
+			input: []TextFragment{{Text: "curl https://x.example", Code: true}},
+			want:  []TextFragment{{Text: "curl https://x.example", Code: true}},
+		},
+		{
+			name:  "bold URL keeps formatting",
+			input: []TextFragment{{Text: "https://b.example", Bold: true}},
+			want:  []TextFragment{{Text: "https://b.example", Bold: true, URL: "https://b.example"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := linkifyFragments(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("linkifyFragments() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("fragment %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConvertBareURLAutoLink(t *testing.T) {
+	dir := t.TempDir()
+	slideContent := "# Auto Link\n18 Feb 2026\n\nAuthor\n\n## Slide\n\nDocs live at https://pkg.go.dev/golang.org/x/tools/present today.\n"
+	slideFile := filepath.Join(dir, "autolink.slide")
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	for _, autoLink := range []bool{true, false} {
+		outputPath := filepath.Join(dir, "out.pdf")
+		if err := NewConverter(WithAutoLink(autoLink)).Convert(slideFile, outputPath); err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		data, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		hasLink := bytes.Contains(data, []byte("/URI (https://pkg.go.dev/golang.org/x/tools/present)"))
+		if hasLink != autoLink {
+			t.Errorf("WithAutoLink(%v): link annotation present = %v", autoLink, hasLink)
+		}
+	}
+}
+
+func TestSplitSymbolRuns(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []textRun
+		dropped []rune
+	}{
+		{"plain", "hello ", []textRun{{"hello ", ""}}, nil},
+		{"symbol only", "✅", []textRun{{"✅", symbolFontFamily}}, nil},
+		{"mixed", "done ✔ ok", []textRun{{"done ", ""}, {"✔", symbolFontFamily}, {" ok", ""}}, nil},
+		{"variation selector dropped", "⚠️ care", []textRun{{"⚠", symbolFontFamily}, {" care", ""}}, nil},
+		{"emoji icon", "go 🚀!", []textRun{{"go ", ""}, {"\uf135", emojiFontFamily}, {"!", ""}}, nil},
+		{"skin tone dropped", "👍🏽👎", []textRun{{"\uf164\uf165", emojiFontFamily}}, nil},
+		{"emoji without icon dropped", "hm 🦄", []textRun{{"hm ", ""}}, []rune{'🦄'}},
+		{"cyrillic is not a symbol", "Привет", []textRun{{"Привет", ""}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped := splitSymbolRuns(tt.input)
+			if !reflect.DeepEqual(dropped, tt.dropped) {
+				t.Errorf("dropped %q, want %q", dropped, tt.dropped)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitSymbolRuns(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("run %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMeasureRunsSymbols(t *testing.T) {
+	conv := NewConverter(WithQuiet(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+
+	setFont := func() { conv.setTextFont("", 18) }
+	plain := conv.measureRuns(conv.symbolRuns("ok "), setFont)
+	withSymbol := conv.measureRuns(conv.symbolRuns("ok ✅ "), setFont)
+	if withSymbol <= plain {
+		t.Errorf("width with symbol = %.2f, want > %.2f", withSymbol, plain)
+	}
+}
+
+func TestDrawTextSymbols(t *testing.T) {
+	// utf16 encodes s the way gofpdf writes text in a UTF-8 font
+	utf16 := func(s string) string {
+		var b strings.Builder
+		for _, r := range s {
+			b.WriteByte(byte(r >> 8))
+			b.WriteByte(byte(r))
+		}
+		return b.String()
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderTitleSlide(&present.Doc{Title: "Done ✓ ⚡", Subtitle: "Launch 🚀"})
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	pdf := buf.String()
+
+	// The title symbols are drawn in the symbol font, not translated away
+	for _, glyph := range []string{"✓", "⚡"} {
+		if !strings.Contains(pdf, "("+utf16(glyph)+")Tj") {
+			t.Errorf("title symbol %s not drawn", glyph)
+		}
+	}
+	if !strings.Contains(pdf, "(Done )Tj") {
+		t.Error("title text not drawn")
+	}
+
+	// The rocket is drawn as its emoji font icon
+	if len(conv.warnings) != 0 {
+		t.Errorf("warnings = %v, want none", conv.warnings)
+	}
+	if !strings.Contains(pdf, "(Launch )Tj") || !strings.Contains(pdf, "("+utf16("\uf135")+")Tj") {
+		t.Error("subtitle rocket not drawn")
+	}
+
+	// An emoji without an icon is dropped with a warning
+	conv.warnings = nil
+	conv.renderTitleSlide(&present.Doc{Title: "Magic 🦄"})
+	if len(conv.warnings) != 1 || !strings.Contains(conv.warnings[0].Message, "U+1F984") {
+		t.Errorf("warnings = %v, want one about U+1F984", conv.warnings)
+	}
+}
+
+func TestConvertEmojiList(t *testing.T) {
+	dir := t.TempDir()
+	convert := func(name, list string) int64 {
+		slideContent := "# Emoji\n18 Feb 2026\n\nAuthor\n\n## Status\n\n" + list
+		slideFile := filepath.Join(dir, name+".slide")
+		if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		outputPath := filepath.Join(dir, name+".pdf")
+		if err := NewConverter(WithQuiet(true)).Convert(slideFile, outputPath); err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		info, err := os.Stat(outputPath)
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		return info.Size()
+	}
+
+	plain := convert("plain", "- tests pass\n- docs pending\n- shipped\n")
+	emoji := convert("emoji", "- ✅ tests pass\n- ⚠️ docs pending\n- 🚀 shipped\n")
+
+	// The symbol and emoji fonts are subset, so the PDF grows only by the
+	// glyphs used
+	if emoji <= plain {
+		t.Errorf("PDF with emoji = %d bytes, want more than %d (symbol glyphs not embedded)", emoji, plain)
+	}
+
+	// The rocket bullet is drawn as its emoji font icon
+	conv := NewConverter(WithQuiet(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.currentSlideNumber = 2
+	conv.renderSlide(present.Section{Title: "Status", Elem: []present.Elem{present.List{Bullet: []string{"🚀 shipped"}}}})
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "(\xf1\x35)Tj") || len(conv.Warnings()) != 0 {
+		t.Errorf("rocket bullet not drawn with the emoji font (warnings %v)", conv.Warnings())
+	}
+}
+
+func TestConvertWithGradientBackground(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "gradient.slide")
+	slideContent := "# Gradient\n18 Feb 2026\n\nAuthor\n\n## Slide\n\nBody text.\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		from, to    RGB
+		wantWarning bool
+	}{
+		{"readable", RGB{20, 40, 90}, RGB{60, 20, 90}, false},
+		{"low contrast", RGB{20, 40, 90}, RGB{230, 230, 255}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(WithQuiet(true), WithGradientBackground(tt.from, tt.to))
+			if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got := len(conv.Warnings()) > 0; got != tt.wantWarning {
+				t.Errorf("contrast warning = %v, want %v (warnings: %v)", got, tt.wantWarning, conv.Warnings())
+			}
+		})
+	}
+}
+
+func TestBlendRGB(t *testing.T) {
+	from, to := RGB{0, 100, 200}, RGB{100, 200, 0}
+	if got := blendRGB(from, to, 0); got != from {
+		t.Errorf("blendRGB(t=0) = %v, want %v", got, from)
+	}
+	if got := blendRGB(from, to, 1); got != to {
+		t.Errorf("blendRGB(t=1) = %v, want %v", got, to)
+	}
+	if got, want := blendRGB(from, to, 0.5), (RGB{50, 150, 100}); got != want {
+		t.Errorf("blendRGB(t=0.5) = %v, want %v", got, want)
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"latin unchanged", "hello world", "hello world"},
+		{"hebrew reversed", "שלום עולם", "םלוע םולש"},
+		{"embedded latin keeps order", "גרסה Go 1.22 חדשה", "השדח Go 1.22 הסרג"},
+		{"brackets mirrored", "שלום (עולם)", "(םלוע) םולש"},
+		{"trailing space moves left", "שלום ", " םולש"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := visualOrder(tt.input); got != tt.want {
+				t.Errorf("visualOrder(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertRTL(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "rtl.slide")
+	slideContent := "# מצגת\n18 Feb 2026\n\nמחבר\n\n## שקופית ראשונה\n\nשלום **עולם** עם [קישור](https://go.dev).\n\n- פריט ראשון\n- פריט שני\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	conv := NewConverter(WithQuiet(true), WithRTL(true))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "rtl.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(conv.Warnings()) > 0 {
+		t.Errorf("unexpected warnings: %v", conv.Warnings())
+	}
+}
+
+func TestRenderFormattedTextRTLAlignsRight(t *testing.T) {
+	conv := NewConverter(WithQuiet(true), WithRTL(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+
+	conv.renderFormattedText([]TextFragment{{Text: "שלום"}}, 20, 50, 200, 11)
+	// The word is drawn flush with the right edge, so the cursor ends at x+maxWidth
+	if got := conv.pdf.GetX(); got < 219.99 || got > 220.01 {
+		t.Errorf("word ends at x=%.2f, want 220", got)
+	}
+}
+
+func TestSlideTitles(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "titles.slide")
+	slideContent := "---\ntheme: dark\n---\n# Deck Title\n18 Feb 2026\n\nAuthor\n\n## First\n\nText.\n\n<!-- ## Commented Out -->\n\n## Second\n\n.iframe https://go.dev 300 400 poster=shot.png\n\n## Third\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := SlideTitles(slideFile)
+	if err != nil {
+		t.Fatalf("SlideTitles() error = %v", err)
+	}
+	want := []string{"Deck Title", "First", "Second", "Third"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("SlideTitles() = %q, want %q", got, want)
+	}
+
+	if _, err := SlideTitles(filepath.Join(dir, "missing.slide")); err == nil {
+		t.Error("SlideTitles() on a missing file: expected error")
+	}
+
+	// Slides left out for the audience are not listed
+	audienceFile := filepath.Join(dir, "audience.md")
+	if err := os.WriteFile(audienceFile, []byte("# Deck\n\n## Talk\n\nText.\n\n## Appendix\n<!-- only: handout -->\n\nMore.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = SlideTitles(audienceFile, WithAudience("present"))
+	if err != nil {
+		t.Fatalf("SlideTitles() error = %v", err)
+	}
+	if want := []string{"Deck", "Talk"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SlideTitles(present) = %q, want %q", got, want)
+	}
+}
+
+func TestOverflowWarnThreshold(t *testing.T) {
+	conv := NewConverter(WithQuiet(true), WithOverflowWarnThreshold(0.8))
+	top, bottom := conv.contentTop(), conv.contentBottom()
+	atThreshold := top + 0.8*(bottom-top)
+
+	tests := []struct {
+		name        string
+		y           float64
+		wantWarning bool
+	}{
+		{"half full", top + 0.5*(bottom-top), false},
+		{"at threshold", atThreshold, false},
+		{"just past threshold", atThreshold + 0.5, true},
+		{"full", bottom, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv.warnings = nil
+			conv.checkFill(tt.y)
+			if got := len(conv.warnings) > 0; got != tt.wantWarning {
+				t.Errorf("checkFill(%.1f) warned = %v, want %v", tt.y, got, tt.wantWarning)
+			}
+		})
+	}
+
+	// Disabled by default
+	conv = NewConverter(WithQuiet(true))
+	conv.checkFill(conv.contentBottom())
+	if len(conv.warnings) > 0 {
+		t.Errorf("checkFill() without threshold warned: %v", conv.warnings)
+	}
+}
+
+func TestConvertNearlyFullSlide(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "full.slide")
+	slideContent := "# Full\n18 Feb 2026\n\nAuthor\n\n## Busy\n\n" + strings.Repeat("A paragraph of text.\n\n", 6) + "## Calm\n\nOne line.\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	conv := NewConverter(WithQuiet(true), WithOverflowWarnThreshold(0.5))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	warnings := conv.Warnings()
+	if len(warnings) != 1 || warnings[0].Title != "Busy" || !strings.Contains(warnings[0].Message, "nearly full") {
+		t.Errorf("Warnings() = %v, want one nearly-full warning for slide \"Busy\"", warnings)
+	}
+}
+
+func TestConvertImageAltText(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "chart.png"), 100, 50)
+	createTestPNG(t, filepath.Join(dir, "logo.png"), 50, 50)
+	slideContent := "# Alt Text\n18 Feb 2026\n\nAuthor\n\n## Chart\n\n![Sales & revenue by quarter](chart.png)\n\n## Logo\n\n![](logo.png)\n"
+	slideFile := filepath.Join(dir, "alt.slide")
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	want := []ImageInfo{
+		{Slide: 2, Title: "Chart", Path: filepath.Join(dir, "chart.png"), Alt: "Sales & revenue by quarter"},
+		{Slide: 3, Title: "Logo", Path: filepath.Join(dir, "logo.png"), Alt: ""},
+	}
+	got := conv.Images()
+	if len(got) != len(want) {
+		t.Fatalf("Images() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Images()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConvertHandout(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "handout.slide")
+	// Title slide + 6 sections = 7 slides
+	var sb strings.Builder
+	sb.WriteString("# Handout\n18 Feb 2026\n\nAuthor\n")
+	for i := 1; i <= 6; i++ {
+		sb.WriteString("\n## Slide " + string(rune('0'+i)) + "\n\nSee [docs](https://go.dev/doc/).\n")
+	}
+	if err := os.WriteFile(slideFile, []byte(sb.String()), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		perPage   int
+		wantPages int
+	}{
+		{"one per page", 0, 7},
+		{"two per page", 2, 4},
+		{"four per page", 4, 2},
+		{"unsupported falls back", 3, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(WithQuiet(true), WithHandout(tt.perPage))
+			if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got := conv.pdf.PageCount(); got != tt.wantPages {
+				t.Errorf("PageCount() = %d, want %d", got, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestHandoutSlotsFitPage(t *testing.T) {
+	for _, n := range []int{2, 4} {
+		conv := NewConverter(WithHandout(n))
+		_, size, _, _ := handoutLayout(n)
+		for i := 0; i < n; i++ {
+			s := conv.handoutSlot(i)
+			right, bottom := s.X+conv.pageWidth*s.Scale, s.Y+conv.pageHeight*s.Scale
+			if s.X < 0 || s.Y < 0 || right > size.Wd || bottom > size.Ht {
+				t.Errorf("n=%d slot %d = %+v exceeds page %vx%v", n, i, s, size.Wd, size.Ht)
+			}
+			if i > 0 {
+				prev := conv.handoutSlot(i - 1)
+				if prev == s {
+					t.Errorf("n=%d slots %d and %d overlap", n, i-1, i)
+				}
+			}
+		}
+	}
+}
+
+func TestConvertLinkWithAmpersand(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "amp.slide")
+	slideContent := "# Links\n18 Feb 2026\n\nAuthor\n\n## Query\n\nSee [search results](https://example.com/search?a=1&b=2) here.\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "amp.pdf")
+	if err := NewConverter(WithQuiet(true)).Convert(slideFile, outputPath); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/URI (https://example.com/search?a=1&b=2)")) {
+		t.Error("link target does not contain a literal & in the query string")
+	}
+}
+
+func TestExtractCallouts(t *testing.T) {
+	code := "conn, err := net.Dial(\"tcp\", addr) // <1> Open the connection\nif err != nil {\n\treturn err // <2>\n}\nx := a < b\nprint(x)  # <a> Python-style marker"
+	gotCode, callouts := extractCallouts(code)
+
+	wantCode := "conn, err := net.Dial(\"tcp\", addr)\nif err != nil {\n\treturn err\n}\nx := a < b\nprint(x)"
+	if gotCode != wantCode {
+		t.Errorf("code = %q, want %q", gotCode, wantCode)
+	}
+	want := []codeCallout{
+		{Line: 0, Label: "1", Text: "Open the connection"},
+		{Line: 2, Label: "2", Text: ""},
+		{Line: 5, Label: "a", Text: "Python-style marker"},
+	}
+	if len(callouts) != len(want) {
+		t.Fatalf("callouts = %+v, want %+v", callouts, want)
+	}
+	for i := range want {
+		if callouts[i] != want[i] {
+			t.Errorf("callout %d = %+v, want %+v", i, callouts[i], want[i])
+		}
+	}
+
+	plain := "fmt.Println(1 < 2)"
+	if got, c := extractCallouts(plain); got != plain || c != nil {
+		t.Errorf("extractCallouts(%q) = %q, %v; want unchanged", plain, got, c)
+	}
+}
+
+func TestRenderCodeTextCalloutLegend(t *testing.T) {
+	conv := NewConverter(WithQuiet(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+
+	code := "x := 1 // <1> Declare x\ny := 2 // <2> Declare y\nz := 3 // <3>"
+	plainCode, _ := extractCallouts(code)
+
+	withoutLegend := conv.renderCodeText(plainCode, "go", 50)
+	withLegend := conv.renderCodeText(code, "go", 50)
+
+	// Two legend entries (the third callout has no text)
+	if got, want := withLegend-withoutLegend, 2*8.0+2; got != want {
+		t.Errorf("legend height = %.1f, want %.1f", got, want)
+	}
+}
+
+func TestConvertCodeCallouts(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "callouts.slide")
+	slideContent := "# Callouts\n18 Feb 2026\n\nAuthor\n\n## Dial\n\n```go\nconn, err := net.Dial(\"tcp\", addr) // <1> Open the connection\ndefer conn.Close() // <2> Always close it\n```\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(conv.Warnings()) > 0 {
+		t.Errorf("unexpected warnings: %v", conv.Warnings())
+	}
+}
+
+func TestWithCodeFontSize(t *testing.T) {
+	code := "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}"
+	blockHeight := func(opts ...Option) float64 {
+		conv := NewConverter(append([]Option{WithQuiet(true)}, opts...)...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.AddPage()
+		return conv.renderCodeText(code, "go", 50) - 50
+	}
+
+	normal := blockHeight()
+	if got, want := blockHeight(WithCodeFontSize(defaultCodeFontSize)), normal; got != want {
+		t.Errorf("WithCodeFontSize(default) height = %.1f, want %.1f", got, want)
+	}
+	if large := blockHeight(WithCodeFontSize(16)); large <= normal {
+		t.Errorf("16pt code height = %.1f, want more than %.1f", large, normal)
+	}
+	if small := blockHeight(WithCodeFontSize(8)); small >= normal {
+		t.Errorf("8pt code height = %.1f, want less than %.1f", small, normal)
+	}
+}
+
+func TestFilterAudience(t *testing.T) {
+	markdown := "# Deck\n\nAuthor\n\n## Intro\n\nShared text.\n\n<!-- only: handout -->\nFurther reading: see the appendix.\nSecond line.\n\n<!-- only: present -->\n- Live demo now\n\n## Handout Notes\n\n<!-- only: handout -->\n\nDetails for readers.\n\n## Code\n\nRun it:\n\n<!-- only: present -->\n```go\nfmt.Println(\"live\")\n```\n\nEnd.\n"
+	legacy := "Deck\n\nAuthor\n\n* Intro\n\nShared text.\n<!-- only: handout -->\nFurther reading: see the appendix.\n\n<!-- only: present -->\n- Live demo now\n\n* Handout Notes\n\n<!-- only: handout -->\n\nDetails for readers.\n\n* Code\n\nRun it:\n<!-- only: present -->\n  fmt.Println(\"live\")\n\nEnd.\n"
+
+	tests := []struct {
+		name     string
+		content  string
+		file     string
+		audience string
+		want     string
+	}{
+		{"markdown all", markdown, "deck.md", "", "Intro: Shared text. | Further reading: see the appendix. | Second line. | Live demo now\nHandout Notes: Details for readers.\nCode: Run it: | fmt.Println(\"live\") | End.\n"},
+		{"markdown present", markdown, "deck.md", "present", "Intro: Shared text. | Live demo now\nCode: Run it: | fmt.Println(\"live\") | End.\n"},
+		{"markdown handout", markdown, "deck.md", "handout", "Intro: Shared text. | Further reading: see the appendix. | Second line.\nHandout Notes: Details for readers.\nCode: Run it: | End.\n"},
+		{"legacy all", legacy, "deck.slide", "", "Intro: Shared text. | Further reading: see the appendix. | Live demo now\nHandout Notes: Details for readers.\nCode: Run it: | fmt.Println(\"live\") | End.\n"},
+		{"legacy present", legacy, "deck.slide", "present", "Intro: Shared text. | Live demo now\nCode: Run it: | fmt.Println(\"live\") | End.\n"},
+		{"legacy handout", legacy, "deck.slide", "handout", "Intro: Shared text. | Further reading: see the appendix.\nHandout Notes: Details for readers.\nCode: Run it: | End.\n"},
+	}
+
+	tagRe := regexp.MustCompile(`<[^>]+>`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConverter(WithQuiet(true), WithAudience(tt.audience))
+			doc, err := parseDoc(c.preprocess([]byte(tt.content)), tt.file)
+			if err != nil {
+				t.Fatalf("parseDoc() error = %v", err)
+			}
+			c.filterAudience(doc)
+
+			var b strings.Builder
+			for _, section := range doc.Sections {
+				var blocks []string
+				for _, elem := range section.Elem {
+					switch e := elem.(type) {
+					case present.HTML:
+						for _, block := range strings.Split(tagRe.ReplaceAllString(string(e.HTML), ""), "\n") {
+							if block = strings.TrimSpace(html.UnescapeString(block)); block != "" && !strings.HasPrefix(block, "<!--") {
+								blocks = append(blocks, block)
+							}
+						}
+					case present.Text:
+						blocks = append(blocks, strings.TrimSpace(strings.Join(e.Lines, "\n")))
+					case present.List:
+						blocks = append(blocks, strings.Join(e.Bullet, "\n"))
+					}
+				}
+				fmt.Fprintf(&b, "%s: %s\n", section.Title, strings.Join(blocks, " | "))
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("filterAudience(%q) =\n%s\nwant:\n%s", tt.audience, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAudienceUnknown(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.slide")
+	if err := os.WriteFile(slideFile, []byte("# Talk\n\n## One\n\nText.\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	conv := NewConverter(WithQuiet(true), WithAudience("speakers"))
+	err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf"))
+	if err == nil || !strings.Contains(err.Error(), `unknown audience "speakers"`) {
+		t.Errorf("Convert() error = %v, want unknown audience", err)
+	}
+	if _, err := SlideTitles(slideFile, WithAudience("speakers")); err == nil {
+		t.Error("SlideTitles() error = nil, want unknown audience")
+	}
+	if _, err := CheckImages(slideFile, WithAudience("Handout")); err != nil {
+		t.Errorf("CheckImages(Handout) error = %v", err)
+	}
+}
+
+func TestConvertAudienceExcludesHandoutSlide(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "audience.slide")
+	slideContent := "# Talk\n18 Feb 2026\n\nAuthor\n\n## Live\n\nOn stage.\n\n## Appendix\n<!-- only: handout -->\n\nReference material.\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	for _, tt := range []struct {
+		audience  string
+		wantPages int
+	}{
+		{"present", 2},
+		{"handout", 3},
+	} {
+		conv := NewConverter(WithQuiet(true), WithAudience(tt.audience))
+		if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if got := conv.pdf.PageCount(); got != tt.wantPages {
+			t.Errorf("WithAudience(%q): PageCount() = %d, want %d", tt.audience, got, tt.wantPages)
+		}
+	}
+}
+
+func TestConvertInternalAnchorLinks(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "anchors.slide")
+	slideContent := "# Anchors\n18 Feb 2026\n\nAuthor\n\n## Contents\n\n[jump](#custom-anchor) or [back to start](#slide-1) or [nowhere](#missing).\n\n## Middle\n\nFiller.\n\n## Target {#custom-anchor}\n\nYou made it.\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "anchors.pdf")
+	conv := NewConverter(WithQuiet(true))
+	if err := conv.Convert(slideFile, outputPath); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if bytes.Contains(data, []byte("/URI (#")) {
+		t.Error("#anchor link written as an external URI")
+	}
+	// Internal jumps are /Dest annotations; two resolved links, one per word
+	// of "jump" and three for "back to start" (outline items have /Dest too)
+	if got := len(regexp.MustCompile(`/Subtype /Link [^>]*/Dest \[`).FindAll(data, -1)); got != 4 {
+		t.Errorf("internal link annotations = %d, want 4", got)
+	}
+
+	warnings := conv.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, `"#missing"`) {
+		t.Errorf("Warnings() = %v, want one unknown-anchor warning", warnings)
+	}
+}
+
+func TestListAutoFit(t *testing.T) {
+	dir := t.TempDir()
+	var items strings.Builder
+	for i := 1; i <= 14; i++ {
+		items.WriteString("- Bullet point number " + string(rune('A'+i-1)) + " with some text\n")
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"markdown", "# Lists\n18 Feb 2026\n\nAuthor\n\n## Many Bullets\n\n" + items.String()},
+		{"legacy", "Lists\n18 Feb 2026\n\nAuthor\n\n* Many Bullets\n\n" + items.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slideFile := filepath.Join(dir, tt.name+".slide")
+			if err := os.WriteFile(slideFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			conv := NewConverter(WithQuiet(true))
+			if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if len(conv.Warnings()) == 0 {
+				t.Fatal("expected the list to overflow without auto-fit")
+			}
+
+			conv = NewConverter(WithQuiet(true), WithListAutoFit(true))
+			if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if len(conv.Warnings()) > 0 {
+				t.Errorf("WithListAutoFit(true): unexpected warnings %v", conv.Warnings())
+			}
+		})
+	}
+}
+
+func TestFitListScaleKeepsShortLists(t *testing.T) {
+	conv := NewConverter(WithListAutoFit(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+
+	items := []listItem{{Fragments: []TextFragment{{Text: "one"}}}, {Fragments: []TextFragment{{Text: "two"}}}}
+	if got := conv.fitListScale(items, conv.contentTop()); got != 1 {
+		t.Errorf("fitListScale() = %.2f for a short list, want 1", got)
+	}
+	if conv.textScale != 1 {
+		t.Errorf("textScale = %.2f after fitListScale, want 1", conv.textScale)
+	}
+}
+
+func TestVerboseOutput(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "pic.png"), 200, 100)
+	slideFile := filepath.Join(dir, "verbose.slide")
+	slideContent := "Verbose\n18 Feb 2026\n\nAuthor\n\n* Layout\n\nSome text.\n\n- item\n\n.image pic.png\n\n* Code\n\n```go\nx := 1\n```\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	var out bytes.Buffer
+	conv := NewConverter(WithVerbose(true), WithLogOutput(&out))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	log := out.String()
+	for _, want := range []string{`slide 2 "Layout": text #0`, "list #1", "image #2", "scaled by", `slide 3 "Code": code: language "go", 1 lines`, "content ends at y="} {
+		if !strings.Contains(log, want) {
+			t.Errorf("verbose output does not mention %q:\n%s", want, log)
+		}
+	}
+
+	out.Reset()
+	conv = NewConverter(WithVerbose(true), WithQuiet(true), WithLogOutput(&out))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("WithQuiet(true) did not suppress verbose output:\n%s", out.String())
+	}
+}
+
+func TestExpandFooter(t *testing.T) {
+	vars := footerVars{Title: "Go Tips", Section: "Slices", Slide: 3, Total: 12, Date: "February 18, 2026"}
+	tests := []struct {
+		template            string
+		wantL, wantC, wantR string
+	}{
+		{"{title} — {n}/{total}", "", "Go Tips — 3/12", ""},
+		{"{title}|{n}/{total}", "Go Tips", "", "3/12"},
+		{"{title} | {date} | {section} {n}", "Go Tips", "February 18, 2026", "Slices 3"},
+		{"plain|{unknown}|", "plain", "{unknown}", ""},
+	}
+
+	for _, tt := range tests {
+		l, c, r := expandFooter(tt.template, vars)
+		if l != tt.wantL || c != tt.wantC || r != tt.wantR {
+			t.Errorf("expandFooter(%q) = %q, %q, %q; want %q, %q, %q", tt.template, l, c, r, tt.wantL, tt.wantC, tt.wantR)
+		}
+	}
+}
+
+func TestRenderFooter(t *testing.T) {
+	conv := NewConverter(WithFooter("{title}|{n}/{total}"))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.footerDoc(&present.Doc{Title: "Footer Deck", Sections: make([]present.Section, 4)})
+	conv.currentSlideNumber = 2
+	conv.pdf.AddPage()
+	conv.renderFooter()
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	for _, want := range []string{"(Footer Deck)", "(2/5)"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("footer text %s not found in page content", want)
+		}
+	}
+}
+
+func TestConvertParseErrorLineContext(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		wantLine int
+		wantText string
+	}{
+		{
+			name:     "missing blank line after header",
+			content:  "---\ntheme: dark\n---\n# Deck\n18 Feb 2026\nAuthor\n## First Slide\n\nText.\n",
+			wantLine: 7,
+			wantText: "## First Slide",
+		},
+		{
+			name:     "bad directive",
+			content:  "# Deck\n18 Feb 2026\n\nAuthor\n\n## Slide\n\nIntro.\n\n.image\n",
+			wantLine: 10,
+			wantText: ".image",
+		},
+		{
+			name:     "missing code file",
+			content:  "# Deck\n18 Feb 2026\n\nAuthor\n\n## Slide\n\n.code missing.go\n",
+			wantLine: 8,
+			wantText: ".code missing.go",
+		},
+		{
+			name:     "repeated line",
+			content:  "---\ntheme: dark\n---\n# Deck\n18 Feb 2026\n\nAuthor\n\n## Example\n\n    .code missing.go\n\n## Slide\n\n.code missing.go\n",
+			wantLine: 15,
+			wantText: ".code missing.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slideFile := filepath.Join(dir, "broken talk.slide")
+			if err := os.WriteFile(slideFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			err := NewConverter(WithQuiet(true)).Convert(slideFile, filepath.Join(dir, "out.pdf"))
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Convert() error = %v, want *ParseError", err)
+			}
+			if perr.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d", perr.Line, tt.wantLine)
+			}
+			wantMarker := fmt.Sprintf("> %4d | %s", tt.wantLine, tt.wantText)
+			if !strings.Contains(err.Error(), wantMarker) {
+				t.Errorf("error does not point at the line %q:\n%v", wantMarker, err)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprintf("broken talk.slide:%d:", tt.wantLine)) {
+				t.Errorf("error does not mention file:line:\n%v", err)
+			}
+		})
+	}
+}
+
+func TestCheckHeaderMalformedDate(t *testing.T) {
+	tests := []struct {
+		content     string
+		wantWarning bool
+	}{
+		{"# Deck\n18 Feb 2026\n\nAuthor\n", false},
+		{"# Deck\nSubtitle\n10:30 18 Feb 2026\n\nAuthor\n", false},
+		{"# Deck\nFebruary 18, 2026\n\nAuthor\n", true},
+		{"---\ntheme: dark\n---\n# Deck\n2026-02-18\n\nAuthor\n", true},
+		{"# Deck\nA talk about Go\n\nAuthor\n", false},
+	}
+
+	for _, tt := range tests {
+		conv := NewConverter(WithQuiet(true))
+		conv.checkHeader([]byte(tt.content))
+		if got := len(conv.Warnings()) > 0; got != tt.wantWarning {
+			t.Errorf("checkHeader(%q) warned = %v, want %v (%v)", tt.content, got, tt.wantWarning, conv.Warnings())
+		}
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	conv.checkHeader([]byte("---\ntheme: dark\n---\n# Deck\n2026-02-18\n"))
+	if w := conv.Warnings(); len(w) != 1 || !strings.Contains(w[0].Message, "line 5:") {
+		t.Errorf("Warnings() = %v, want a warning for line 5", w)
+	}
+}
+
+func TestWithHeadingFontTTF(t *testing.T) {
+	ttf, err := inflateFont(dejavuSansMonoZ)
+	if err != nil {
+		t.Fatalf("inflateFont() error = %v", err)
+	}
+
+	conv := NewConverter(WithHeadingFontTTF(ttf))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+
+	// A monospace heading font measures differently from Helvetica body text
+	conv.setHeadingFont("B", 29)
+	heading := conv.pdf.GetStringWidth("iiiii")
+	conv.setTextFont("B", 29)
+	body := conv.pdf.GetStringWidth("iiiii")
+	if heading <= body {
+		t.Errorf("heading font width %.2f, want wider than Helvetica %.2f", heading, body)
+	}
+
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "fonts.slide")
+	slideContent := "# Заголовок\n18 Feb 2026\n\nAuthor\n\n## Слайд\n\nТекст.\n"
+	if err := os.WriteFile(slideFile, []byte(slideContent), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	outputPath := filepath.Join(dir, "fonts.pdf")
+	if err := NewConverter(WithQuiet(true), WithHeadingFontTTF(ttf)).Convert(slideFile, outputPath); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/BaseFont /utf8heading")) {
+		t.Error("custom heading font not embedded")
+	}
+}
+
+func TestWithBodyFontTTFInvalid(t *testing.T) {
+	conv := NewConverter(WithBodyFontTTF([]byte("not a font")))
+	if _, err := conv.initPDF(); err == nil {
+		t.Error("initPDF() with invalid body font: expected error")
+	}
+}
+
+func TestRenderBlankSlide(t *testing.T) {
+	tests := []struct {
+		name    string
+		section present.Section
+		blank   bool
+	}{
+		{"blank title", present.Section{Title: ".blank"}, true},
+		{"legacy marker", present.Section{Title: "Questions", Elem: []present.Elem{present.Text{Lines: []string{"<!-- blank -->"}}}}, true},
+		{"markdown marker", present.Section{Title: "Questions", Elem: []present.Elem{present.HTML{HTML: "<!-- blank -->"}}}, true},
+		{"regular slide", present.Section{Title: "Questions", Elem: []present.Elem{present.Text{Lines: []string{"Any questions?"}}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(WithQuiet(true))
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.pdf.SetCompression(false)
+			conv.renderSlide(tt.section)
+
+			var buf bytes.Buffer
+			if err := conv.pdf.Output(&buf); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+			drawn := bytes.Contains(buf.Bytes(), []byte("(Questions)"))
+			if drawn == tt.blank {
+				t.Errorf("title drawn = %v, want %v", drawn, !tt.blank)
+			}
+			if tt.blank && bytes.Contains(buf.Bytes(), []byte(")Tj")) {
+				t.Error("blank slide contains text")
+			}
+		})
+	}
+}
+
+func TestWrapTextCyrillic(t *testing.T) {
+	paragraph := strings.Repeat("Съешь же ещё этих мягких французских булок, да выпей чаю. ", 6)
+
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{"cp1251 font", nil},
+		{"utf8 font", []Option{WithFontSubsetting(true)}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(tt.opts...)
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.setTextFont("", 21)
+
+			const width = 150.0
+			lines := conv.wrapText(paragraph, width)
+			if len(lines) < 4 {
+				t.Fatalf("wrapText() = %d lines, want a long paragraph to wrap", len(lines))
+			}
+			for i, line := range lines {
+				if w := conv.textWidth(line); w > width {
+					t.Errorf("line %d %q is %.1fmm wide, exceeds %.0fmm", i, line, w, width)
+				}
+				if line != strings.TrimSpace(line) {
+					t.Errorf("line %d %q has surrounding spaces", i, line)
+				}
+			}
+			if got, want := strings.Join(lines, " "), strings.TrimSpace(paragraph); got != want {
+				t.Errorf("wrapped lines lost text:\n got %q\nwant %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWrapTextBreaksLongWords(t *testing.T) {
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.setTextFont("", 18)
+
+	word := strings.Repeat("Превысокомногорассмотрительствующий", 3)
+	lines := conv.wrapText("Слово: "+word+"\nконец", 80)
+	if len(lines) < 3 {
+		t.Fatalf("wrapText() = %q, want the long word split", lines)
+	}
+	if lines[0] != "Слово:" {
+		t.Errorf("first line = %q, want %q", lines[0], "Слово:")
+	}
+	if lines[len(lines)-1] != "конец" {
+		t.Errorf("explicit newline not kept, last line = %q", lines[len(lines)-1])
+	}
+	for i, line := range lines {
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d %q splits a multi-byte rune", i, line)
+		}
+		if conv.textWidth(line) > 80 {
+			t.Errorf("line %d %q exceeds the width", i, line)
+		}
+	}
+}
+
+func TestRenderTextHeightFollowsWrapping(t *testing.T) {
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+
+	short := conv.renderText(present.Text{Lines: []string{"Коротко"}}, 50)
+	long := conv.renderText(present.Text{Lines: []string{strings.Repeat("очень длинный абзац текста ", 20)}}, 50)
+	if short != 65 {
+		t.Errorf("single line text ends at %.1f, want 65", short)
+	}
+	if long <= short+11 {
+		t.Errorf("wrapped text ends at %.1f, want several lines below %.1f", long, short)
+	}
+}
+
+func TestHighlightGenericsAndTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		want     string
+		language string
+	}{
+		{
+			name:     "go generics",
+			html:     "<pre><code class=\"language-go\">func Map[T any, U any](s []T, f func(T) U) []U {\n    if len(s) &lt; 1 || len(s) &gt; 9 {\n        return nil\n    }\n}\n</code></pre>",
+			want:     "func Map[T any, U any](s []T, f func(T) U) []U {\n    if len(s) < 1 || len(s) > 9 {\n        return nil\n    }\n}",
+			language: "go",
+		},
+		{
+			name:     "c++ templates",
+			html:     "<pre><code class=\"language-cpp\">std::vector&lt;int&gt; v;\nstd::map&lt;std::string, std::vector&lt;int&gt;&gt; m;\n</code></pre>",
+			want:     "std::vector<int> v;\nstd::map<std::string, std::vector<int>> m;",
+			language: "cpp",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, language, ok := parseHTMLCodeBlock(tt.html)
+			if !ok {
+				t.Fatalf("parseHTMLCodeBlock() failed for %q", tt.html)
+			}
+			if code != tt.want || language != tt.language {
+				t.Fatalf("parseHTMLCodeBlock() = %q, %q; want %q, %q", code, language, tt.want, tt.language)
+			}
+
+			tokens, err := conv.highlightCode(code, language)
+			if err != nil {
+				t.Fatalf("highlightCode() error = %v", err)
+			}
+			var text strings.Builder
+			for _, tok := range tokens {
+				text.WriteString(tok.Value)
+				if strings.ContainsAny(tok.Value, "<>[]") && !tok.Type.InCategory(chroma.Operator) && !tok.Type.InCategory(chroma.Punctuation) {
+					t.Errorf("token %q lexed as %v, want an operator or punctuation", tok.Value, tok.Type)
+				}
+			}
+			if got := strings.TrimRight(text.String(), "\n"); got != tt.want {
+				t.Errorf("highlighted text = %q, want %q", got, tt.want)
+			}
+			for _, entity := range []string{"&lt;", "&gt;", "&amp;"} {
+				if strings.Contains(text.String(), entity) {
+					t.Errorf("highlighted text still contains %s", entity)
+				}
+			}
+		})
+	}
+}
+
+func TestLongCodeBlockReturnsDrawnHeight(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&b, "x%d := %d\n", i, i)
+	}
+	code := b.String()
+
+	for _, tt := range []struct {
+		name   string
+		render func(c *Converter, y float64) float64
+	}{
+		{"highlighted", func(c *Converter, y float64) float64 { return c.renderCodeText(code, "go", y) }},
+		{"plain", func(c *Converter, y float64) float64 { return c.renderCodePlain(code, 1, y) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(WithQuiet(true))
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.pdf.AddPage()
+
+			const startY = 50.0
+			endY := tt.render(conv, startY)
+			want := startY + defaultMaxCodeLines*conv.codeLineHeight() + 12
+			if math.Abs(endY-want) > 0.01 {
+				t.Errorf("returned y = %.1f, want %.1f for a block capped at %d lines", endY, want, defaultMaxCodeLines)
+			}
+			if _, pageH := conv.pdf.GetPageSize(); endY > pageH {
+				t.Errorf("returned y = %.1f is below the page (height %.0f)", endY, pageH)
+			}
+			if len(conv.Warnings()) != 1 || !strings.Contains(conv.Warnings()[0].Message, "truncated") {
+				t.Errorf("Warnings() = %v, want one truncation warning", conv.Warnings())
+			}
+		})
+	}
+}
+
+func TestThemeAuto(t *testing.T) {
+	tests := []struct {
+		codeTheme string
+		want      Theme
+	}{
+		{"monokai", DarkTheme},
+		{"dracula", DarkTheme},
+		{"github", LightTheme},
+		{"friendly", LightTheme},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.codeTheme, func(t *testing.T) {
+			if got := themeForCodeStyle(tt.codeTheme); got != tt.want {
+				t.Errorf("themeForCodeStyle(%q) = %+v, want %+v", tt.codeTheme, got.SlideBackground, tt.want.SlideBackground)
+			}
+
+			dir := t.TempDir()
+			slideFile := filepath.Join(dir, "test.slide")
+			if err := os.WriteFile(slideFile, []byte("Title\n\n* Slide\n\nText\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			conv := NewConverter(WithTheme("auto"), WithCodeTheme(tt.codeTheme))
+			if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if conv.theme != tt.want {
+				t.Errorf("WithTheme(\"auto\") with code theme %q did not select the matching theme", tt.codeTheme)
+			}
+		})
+	}
+}
+
+func TestTimingSummary(t *testing.T) {
+	content := "# Talk\n\n## Intro\n<!-- time: 2m -->\n\nHello\n\n## Demo\n\n<!-- time: 1m30s -->\n- step\n\n```\n<!-- time: 9m -->\n```\n\n## Q&A\n\nQuestions\n"
+
+	conv := NewConverter(WithQuiet(true), WithTimingSummary(true))
+	out := conv.extractTimings([]byte(content))
+	if strings.Contains(string(out), "time: 2m") || strings.Contains(string(out), "time: 1m30s") {
+		t.Errorf("timing markers not stripped:\n%s", out)
+	}
+	if !strings.Contains(string(out), "time: 9m") {
+		t.Errorf("marker inside a code block was stripped:\n%s", out)
+	}
+	want := map[int]time.Duration{0: 2 * time.Minute, 1: 90 * time.Second}
+	if !reflect.DeepEqual(conv.timings, want) {
+		t.Errorf("timings = %v, want %v", conv.timings, want)
+	}
+
+	doc := &present.Doc{Sections: []present.Section{{Title: "Intro"}, {Title: "Demo"}, {Title: "Q&A"}}}
+	section := timingSummarySection(doc, conv.timings)
+	list, ok := section.Elem[0].(present.List)
+	if !ok {
+		t.Fatalf("summary element = %T, want present.List", section.Elem[0])
+	}
+	wantBullets := []string{"Intro — 2:00", "Demo — 1:30", "Total — 3:30"}
+	if !reflect.DeepEqual(list.Bullet, wantBullets) {
+		t.Errorf("summary = %q, want %q", list.Bullet, wantBullets)
+	}
+
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outFile := filepath.Join(dir, "talk.pdf")
+	if err := conv.Convert(slideFile, outFile); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if n := conv.pdf.PageCount(); n != 5 {
+		t.Errorf("PageCount() = %d, want 5 (title, 3 slides, summary)", n)
+	}
+}
+
+// panickyLexer is a lexer that crashes while tokenising, like some chroma
+// lexers do on pathological input
+type panickyLexer struct{ chroma.Lexer }
+
+func (panickyLexer) Config() *chroma.Config {
+	return &chroma.Config{Name: "Panicky", Aliases: []string{"panicky"}}
+}
+
+func (panickyLexer) Tokenise(*chroma.TokeniseOptions, string) (chroma.Iterator, error) {
+	return func() chroma.Token { panic("unbalanced state stack") }, nil
+}
+
+func TestLexerPanicFallsBackToPlainCode(t *testing.T) {
+	lexers.Register(panickyLexer{lexers.Get("go")})
+
+	if _, err := NewConverter().highlightCode("((((", "panicky"); err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Errorf("highlightCode() error = %v, want the lexer panic as an error", err)
+	}
+
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "test.md")
+	content := "# Title\n\n## Code\n\n```panicky\n(((( }}}} <<<<\n```\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conv := NewConverter(WithQuiet(true))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	warnings := conv.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "rendering plain code") {
+		t.Errorf("Warnings() = %v, want a plain-code fallback warning", warnings)
+	}
+}
+
+func TestWithCodePadding(t *testing.T) {
+	code := "short := 1\n" + "long := \"" + strings.Repeat("x", 200) + "\""
+
+	for _, tt := range []struct {
+		name   string
+		opts   []Option
+		padH   float64
+		padV   float64
+		render func(c *Converter, y float64) float64
+	}{
+		{"default highlighted", nil, 5, 2.5, func(c *Converter, y float64) float64 { return c.renderCodeText(code, "go", y) }},
+		{"custom highlighted", []Option{WithCodePadding(8, 4)}, 8, 4, func(c *Converter, y float64) float64 { return c.renderCodeText(code, "go", y) }},
+		{"custom plain", []Option{WithCodePadding(8, 4)}, 8, 4, func(c *Converter, y float64) float64 { return c.renderCodePlain(code, 1, y) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(tt.opts...)
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.pdf.SetCompression(false)
+			conv.pdf.AddPage()
+
+			const startY = 50.0
+			endY := tt.render(conv, startY)
+			boxHeight := 2*conv.codeLineHeight() + 2*tt.padV
+			if want := startY + boxHeight + codeBlockGap; math.Abs(endY-want) > 0.01 {
+				t.Errorf("returned y = %.2f, want %.2f", endY, want)
+			}
+
+			// The long line must be clipped to the padded box
+			var buf bytes.Buffer
+			if err := conv.pdf.Output(&buf); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+			k := 72 / 25.4
+			_, pageH := conv.pdf.GetPageSize()
+			clip := fmt.Sprintf("q %.2f %.2f %.2f %.2f re W n", (conv.contentX()+tt.padH)*k, (pageH-startY)*k, (conv.contentWidth()-2*tt.padH)*k, -boxHeight*k)
+			if !bytes.Contains(buf.Bytes(), []byte(clip)) {
+				t.Errorf("code text is not clipped to the padded box %q", clip)
+			}
+		})
+	}
+}
+
+func TestRenderFormattedTextBoldCode(t *testing.T) {
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.pdf.AddPage()
+
+	fragments := parseHTMLFormatting(`<strong>plain</strong> <strong><code>mono</code></strong> <a href="https://go.dev"><code>linked</code></a>`)
+	conv.renderFormattedText(fragments, conv.contentX(), 50, conv.contentWidth(), 11)
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	pdf := buf.String()
+	if n := strings.Count(pdf, "(plain )Tj"); n != 1 || !strings.Contains(pdf, "/BaseFont /DejaVuSansCondensed-Bold") {
+		t.Errorf("bold text drawn %d times, want once in the bold text font", n)
+	}
+	if n := strings.Count(pdf, "(mono )Tj"); n != 1 {
+		t.Errorf("bold code drawn %d times, want once in the bold monospace font", n)
+	}
+	if !strings.Contains(pdf, "(https://go.dev)") {
+		t.Error("code link is not clickable")
+	}
+}
+
+func TestWithMaxContentWidth(t *testing.T) {
+	paragraph := strings.Repeat("Readable line length keeps long paragraphs comfortable. ", 8)
+
+	render := func(opts ...Option) (*Converter, int) {
+		conv := NewConverter(opts...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.AddPage()
+		endY := conv.renderText(present.Text{Lines: []string{paragraph}}, 50)
+		return conv, int(math.Round((endY - 50 - 4) / 11))
+	}
+
+	full, fullLines := render()
+	capped, cappedLines := render(WithMaxContentWidth(150))
+
+	if got := capped.contentWidth(); got != 150 {
+		t.Errorf("contentWidth() = %.1f, want 150", got)
+	}
+	if got, want := capped.contentX(), (a4Width-150)/2; math.Abs(got-want) > 0.01 {
+		t.Errorf("contentX() = %.1f, want %.1f (centered column)", got, want)
+	}
+	if full.contentWidth() != a4Width-2*slideMargin {
+		t.Errorf("default contentWidth() = %.1f, want the full width", full.contentWidth())
+	}
+
+	capped.setTextFont("", 21)
+	want := len(capped.wrapText(paragraph, 150-2*capped.pdf.GetCellMargin()))
+	if cappedLines != want {
+		t.Errorf("capped paragraph has %d lines, want %d wrapped at 150mm", cappedLines, want)
+	}
+	if cappedLines <= fullLines {
+		t.Errorf("capped paragraph has %d lines, full width %d; want more lines in the narrower column", cappedLines, fullLines)
+	}
+}
+
+func TestWithCornerLogo(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "logo.png"), 300, 100)
+	doc := &present.Doc{
+		Title:    "Sponsored Talk",
+		Sections: []present.Section{{Title: "One"}, {Title: "Two"}, {Title: "Three"}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantLogo int
+	}{
+		{"content slides", []Option{WithCornerLogo("logo.png", "top-right")}, 3},
+		{"with title slide", []Option{WithCornerLogo("logo.png", "bottom-left"), WithCornerLogoOnTitleSlide(true)}, 4},
+		{"unknown corner", []Option{WithCornerLogo("logo.png", "middle"), WithQuiet(true)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(append(tt.opts, WithFooter("{title}|{n}"))...)
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.pdf.SetCompression(false)
+			conv.slideDir = dir
+			conv.footerDoc(doc)
+			conv.prepareLogo()
+
+			conv.renderTitleSlide(doc)
+			for _, section := range doc.Sections {
+				conv.renderSlide(section)
+			}
+
+			var buf bytes.Buffer
+			if err := conv.pdf.Output(&buf); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+			if got := bytes.Count(buf.Bytes(), []byte(" Do")); got != tt.wantLogo {
+				t.Errorf("logo drawn %d times, want %d", got, tt.wantLogo)
+			}
+			if images := bytes.Count(buf.Bytes(), []byte("/Subtype /Image")); tt.wantLogo > 0 && images != 1 {
+				t.Errorf("logo embedded %d times, want once", images)
+			}
+
+			r, ok := conv.logoRect()
+			if !ok {
+				return
+			}
+			if r.W > logoMaxWidth+0.01 || r.H > logoMaxHeight+0.01 {
+				t.Errorf("logo is %.1fx%.1fmm, want at most %.0fx%.0fmm", r.W, r.H, logoMaxWidth, logoMaxHeight)
+			}
+			if r.Y < conv.contentBottom() && r.Y+r.H > conv.region.Y+titleTop {
+				t.Errorf("logo at y=%.1f..%.1f overlaps the title", r.Y, r.Y+r.H)
+			}
+			if x, w := conv.footerSpan(); r.X < x+w && r.X+r.W > x && r.Y > conv.contentBottom() {
+				t.Errorf("footer %.1f..%.1f overlaps the logo at x=%.1f..%.1f", x, x+w, r.X, r.X+r.W)
+			}
+		})
+	}
+}
+
+func TestWithLinksAsFootnotes(t *testing.T) {
+	section := present.Section{
+		Title: "Links",
+		Elem: []present.Elem{
+			present.HTML{HTML: `<p>Read <a href="https://go.dev/doc">the <strong>docs</strong></a> and <a href="https://go.dev/blog">the blog</a>, then <a href="https://go.dev/doc">the docs</a> again.</p>`},
+		},
+	}
+
+	for _, footnotes := range []bool{false, true} {
+		conv := NewConverter(WithLinksAsFootnotes(footnotes))
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.renderSlide(section)
+
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		pdf := buf.String()
+		for _, want := range []string{"(References)", "(1. https://go.dev/doc)", "(2. https://go.dev/blog)", "(1)Tj", "(2)Tj"} {
+			if got := strings.Contains(pdf, want); got != footnotes {
+				t.Errorf("WithLinksAsFootnotes(%v): %s present = %v", footnotes, want, got)
+			}
+		}
+		if footnotes {
+			if !reflect.DeepEqual(conv.footnotes, []string{"https://go.dev/doc", "https://go.dev/blog"}) {
+				t.Errorf("footnotes = %q, want each URL numbered once", conv.footnotes)
+			}
+			if strings.Contains(pdf, "(3)Tj") {
+				t.Error("repeated link got a new footnote number")
+			}
+		}
+	}
+}
+
+func TestWithNotesPages(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Talk\n\n## Intro\n\nHello\n\n: Greet the audience\n: and introduce yourself\n\n## Missing\n\n![chart](missing.png)\n\n: Explain the chart\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		notes     bool
+		wantPages int
+	}{
+		{false, 3},
+		{true, 6},
+	} {
+		conv := NewConverter(WithQuiet(true), WithNotesPages(tt.notes))
+		if err := conv.Convert(slideFile, filepath.Join(dir, "talk.pdf")); err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if got := conv.pdf.PageCount(); got != tt.wantPages {
+			t.Errorf("WithNotesPages(%v): PageCount() = %d, want %d", tt.notes, got, tt.wantPages)
+		}
+		// Thumbnails must not repeat the slide's diagnostics
+		if got := len(conv.Warnings()); got != 1 {
+			t.Errorf("WithNotesPages(%v): %d warnings, want 1: %v", tt.notes, got, conv.Warnings())
+		}
+	}
+
+	conv := NewConverter(WithNotesPages(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	section := present.Section{Title: "Intro", Notes: []string{"Greet the audience"}}
+	conv.renderSlide(section)
+	conv.endSlide()
+	conv.notesPage(func() { conv.renderSlide(section) }, section.Notes)
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("(Intro)Tj")); n != 2 {
+		t.Errorf("slide title drawn %d times, want on the slide and its thumbnail", n)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("(Greet the audience)Tj")) {
+		t.Error("speaker notes not found on the notes page")
+	}
+}
+
+func TestCheckImages(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "present.png"), 10, 10)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		opts    []Option
+		want    []string
+	}{
+		{
+			name:    "legacy",
+			file:    "legacy.slide",
+			content: "Title\n\n* Images\n\n.image present.png\n.image missing.png\n\n* Again\n\n.image missing.png\n",
+			want:    []string{filepath.Join(dir, "missing.png")},
+		},
+		{
+			name:    "markdown",
+			file:    "deck.md",
+			content: "# Title\n\n## Images\n\n![here](present.png)\n\n![gone](img/gone.png)\n\n![remote](https://example.com/logo.png)\n",
+			want:    []string{filepath.Join(dir, "img", "gone.png")},
+		},
+		{
+			name:    "background",
+			file:    "background.slide",
+			content: "Title\n\n* Backdrop\n\n.background bg.jpg\n\nText\n\n* Remote\n\n.background https://example.com/bg.jpg\n\nText\n",
+			want:    []string{filepath.Join(dir, "bg.jpg")},
+		},
+		{
+			name:    "all present",
+			file:    "ok.md",
+			content: "# Title\n\n## Images\n\n![here](present.png)\n",
+			want:    nil,
+		},
+		{
+			name:    "other audience",
+			file:    "audience.md",
+			content: "# Title\n\n## Images\n\n![here](present.png)\n\n## Appendix\n<!-- only: handout -->\n\n![gone](handout.png)\n",
+			opts:    []Option{WithAudience("present")},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slideFile := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(slideFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := CheckImages(slideFile, tt.opts...)
+			if err != nil {
+				t.Fatalf("CheckImages() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckImages() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageTypeSniffedFromContent(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "chart.img"), 40, 30)
+
+	jpg, err := os.Create(filepath.Join(dir, "photo.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(jpg, image.NewRGBA(image.Rect(0, 0, 40, 30)), nil); err != nil {
+		t.Fatal(err)
+	}
+	jpg.Close()
+
+	for _, tt := range []struct{ file, want string }{
+		{"chart.img", "PNG"},
+		{"photo.png", "JPEG"},
+	} {
+		if got, ok := imageType(filepath.Join(dir, tt.file)); !ok || got != tt.want {
+			t.Errorf("imageType(%s) = %q, %v; want %q", tt.file, got, ok, tt.want)
+		}
+	}
+
+	slideFile := filepath.Join(dir, "deck.md")
+	content := "# Title\n\n## Chart\n\n![chart](chart.img)\n\n## Photo\n\n![photo](photo.png)\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conv := NewConverter(WithQuiet(true))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "deck.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(conv.Images()) != 2 {
+		t.Errorf("Images() = %v, want both mislabeled images embedded", conv.Images())
+	}
+	for _, w := range conv.Warnings() {
+		if strings.Contains(w.Message, "image") {
+			t.Errorf("unexpected warning: %s", w)
+		}
+	}
+}
+
+func TestConvertToResult(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "deck.slide")
+	var b strings.Builder
+	b.WriteString("Result Deck\n\n* Short\n\nFits\n\n* Long\n\n")
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&b, "Paragraph %d\n\n", i)
+	}
+	b.WriteString("* Last\n\nDone\n")
+	if err := os.WriteFile(slideFile, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outFile := filepath.Join(dir, "deck.pdf")
+
+	res, err := NewConverter(WithQuiet(true)).ConvertTo(slideFile, outFile)
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	info, err := os.Stat(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Title != "Result Deck" {
+		t.Errorf("Title = %q, want %q", res.Title, "Result Deck")
+	}
+	if res.Slides != 4 || res.Pages != 4 {
+		t.Errorf("Slides, Pages = %d, %d; want 4, 4", res.Slides, res.Pages)
+	}
+	if res.Bytes != info.Size() || res.Bytes == 0 {
+		t.Errorf("Bytes = %d, want the PDF size %d", res.Bytes, info.Size())
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].Slide != 3 || len(res.Warnings[0].Dropped) == 0 {
+		t.Errorf("Warnings = %v, want one overflow on slide 3", res.Warnings)
+	}
+}
+
+func TestWithListBullets(t *testing.T) {
+	const html = `<ul>
+<li>one
+<ul>
+<li>two
+<ul>
+<li>three
+<ul>
+<li>four</li>
+</ul>
+</li>
+</ul>
+</li>
+</ul>
+</li>
+<li>back</li>
+</ul>`
+
+	conv := NewConverter(WithListBullets([]string{">", "-", "+"}), WithListIndent(6))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+
+	items := conv.parseHTMLList(html)
+	var got []string
+	for _, item := range items {
+		got = append(got, fmt.Sprintf("%s@%d:%s", item.Fragments[0].Text, item.Depth, conv.bulletFor(item.Depth)))
+	}
+	want := []string{"one@0:>", "two@1:-", "three@2:+", "four@3:+", "back@0:>"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %q, want %q", got, want)
+	}
+
+	for depth := 0; depth < 3; depth++ {
+		x := conv.renderBullet(depth, conv.contentTop(), 9)
+		if want := conv.contentX() + 10 + float64(depth)*6; math.Abs(x-want) > 0.01 {
+			t.Errorf("renderBullet(%d) text x = %.2f, want %.2f", depth, x, want)
+		}
+	}
+
+	conv.renderSlide(present.Section{Title: "Nested", Elem: []present.Elem{present.HTML{HTML: html}}})
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	for _, glyph := range []string{"(>)Tj", "(-)Tj", "(+)Tj"} {
+		if !strings.Contains(buf.String(), glyph) {
+			t.Errorf("PDF does not contain bullet %s", glyph)
+		}
+	}
+
+	if got := NewConverter().bulletFor(5); got != "▪" {
+		t.Errorf("default bulletFor(5) = %q, want ▪", got)
+	}
+}
+
+func TestSmartPunctuation(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`"Go" -- it's fast`, "“Go” – it’s fast"},
+		{"wait---what...", "wait—what…"},
+		{`say 'hi' ("now")`, "say ‘hi’ (“now”)"},
+		{"no quotes", "no quotes"},
+	}
+	for _, tt := range tests {
+		if got, _ := smartPunctuation(tt.in, ' '); got != tt.want {
+			t.Errorf("smartPunctuation(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// Quotes around formatted text continue across fragments; code is kept
+	conv := NewConverter(WithSmartPunctuation(true))
+	fragments := conv.parseFormatting(`"<strong>bold</strong>" and <code>"raw" -- x</code>`)
+	var got []string
+	for _, f := range fragments {
+		got = append(got, f.Text)
+	}
+	if want := []string{"“", "bold", "” and ", `"raw" -- x`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fragments = %q, want %q", got, want)
+	}
+}
+
+func TestTypographicPunctuationSurvivesCP1251(t *testing.T) {
+	conv := NewConverter(WithSmartPunctuation(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderSlide(present.Section{
+		Title: "Dashes",
+		Elem: []present.Elem{
+			present.HTML{HTML: "<p>Em — dash, “curly” ‘quotes’</p>"},
+			present.HTML{HTML: "<p>\"Straight\" -- quotes</p>"},
+			present.HTML{HTML: "<p>Minus −1</p>"},
+		},
+	})
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	pdf := buf.String()
+	for _, want := range []string{"(\x97 )Tj", "(\x93curly\x94 )Tj", "(\x91quotes\x92 )Tj", "(\x93Straight\x94 )Tj", "(\x96 )Tj", "(-1 )Tj"} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("PDF does not contain %q", want)
+		}
+	}
+}
+
+func TestWrappedTitleMovesContentDown(t *testing.T) {
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+
+	conv.renderSlide(present.Section{Title: "Short", Elem: []present.Elem{present.Text{Lines: []string{"Body"}}}})
+	if got, want := conv.contentTop(), conv.region.Y+contentTop; got != want {
+		t.Errorf("contentTop() = %.1f for a one-line title, want %.1f", got, want)
+	}
+
+	title := strings.Repeat("A rather long slide title ", 5)
+	conv.renderSlide(present.Section{Title: title, Elem: []present.Elem{present.Text{Lines: []string{"Body"}}}})
+	conv.setHeadingFont("B", 29)
+	lines := len(conv.wrapText(title, conv.contentWidth()))
+	if lines < 2 {
+		t.Fatalf("title wraps to %d lines, want at least 2", lines)
+	}
+	titleBottom := conv.region.Y + titleTop + float64(lines)*titleLine
+	if got := conv.contentTop(); got < titleBottom+contentTop-titleTop-titleLine {
+		t.Errorf("contentTop() = %.1f, want the usual gap below the wrapped title ending at %.1f", got, titleBottom)
+	}
+}
+
+func TestCodeLanguagePrecedence(t *testing.T) {
+	const goCode = "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(1) }\n"
+	const bashCode = "#!/bin/bash\necho hi\n"
+
+	tests := []struct {
+		name     string
+		conv     *Converter
+		explicit string
+		filename string
+		code     string
+		want     string
+	}{
+		{"explicit wins over extension", NewConverter(), "python", "main.go", goCode, "python"},
+		{"extension wins over content", NewConverter(), "", "script.py", bashCode, "python"},
+		{"content when extension is unknown", NewConverter(), "", "notes.txt", bashCode, "bash"},
+		{"content without filename", NewConverter(), "", "", goCode, "go"},
+		{"default", NewConverter(), "", "notes.txt", "plain words", "go"},
+		{"configured default", NewConverter(WithDefaultCodeLanguage("text")), "", "", "plain words", "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.conv.codeLanguage(tt.explicit, tt.filename, tt.code); got != tt.want {
+				t.Errorf("codeLanguage(%q, %q) = %q, want %q", tt.explicit, tt.filename, got, tt.want)
+			}
+		})
+	}
+
+	// .code -lang=X is stripped before parsing and overrides the extension,
+	// for each directive even if they are otherwise the same
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "build.txt"), []byte("echo hi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	slideFile := filepath.Join(dir, "code.slide")
+	content := "Langs\n18 Feb 2026\n\nAuthor\n\n* Shell\n\n.code -lang=bash build.txt\n\n* Python\n\n.code -lang=python build.txt\n\n* Plain\n\n.code build.txt\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	conv := NewConverter(WithVerbose(true), WithLogOutput(&out))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	for _, want := range []string{`"Shell": code: language "bash"`, `"Python": code: language "python"`, `"Plain": code: language "go"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("log does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWithDebugDir(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Talk\n\n## Intro\n\nHello **world**\n\n```go\n// comment\nx := 1\n```\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	debugDir := filepath.Join(dir, "debug")
+	conv := NewConverter(WithDebugDir(debugDir))
+	if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	source, err := os.ReadFile(filepath.Join(debugDir, debugSourceFile))
+	if err != nil {
+		t.Fatalf("preprocessed source not written: %v", err)
+	}
+	if !strings.Contains(string(source), "\u200C// comment") {
+		t.Errorf("preprocessed source = %q, want the protected // comment line", source)
+	}
+
+	dump, err := os.ReadFile(filepath.Join(debugDir, "slide-02.txt"))
+	if err != nil {
+		t.Fatalf("slide dump not written: %v", err)
+	}
+	for _, want := range []string{"# Intro", "html ---", "<strong>world</strong>", `<code class="language-go">`} {
+		if !strings.Contains(string(dump), want) {
+			t.Errorf("slide dump does not contain %q:\n%s", want, dump)
+		}
+	}
+}
+
+func TestWithClosingSlide(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Talk\n18 Feb 2026\n\nJane Doe\njane@example.com\n\n## Intro\n\nHello\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewConverter(WithClosingSlide("Thank you", "Questions?")).ConvertTo(slideFile, filepath.Join(dir, "out.pdf"))
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if res.Slides != 3 || res.Pages != 3 {
+		t.Errorf("Result = %d slides, %d pages; want 3 and 3 with the closing slide", res.Slides, res.Pages)
+	}
+
+	doc, err := parseDoc([]byte(content), slideFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conv := NewConverter(WithClosingSlide("Thank you", "Questions?"))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderClosingSlide(doc)
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	for _, want := range []string{"(Thank you)Tj", "(Questions?)Tj", "(Jane Doe)Tj", "(jane@example.com)Tj", "mailto:jane@example.com"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("closing slide does not contain %s", want)
+		}
+	}
+	if strings.Contains(buf.String(), "February") {
+		t.Error("closing slide repeats the presentation date")
+	}
+}
+
+func TestCommentContrastAndItalic(t *testing.T) {
+	for _, themeName := range []string{"light", "dark"} {
+		for _, codeTheme := range []string{"monokai", "github", "dracula", "emacs", "no-such-style"} {
+			conv := NewConverter(WithTheme(themeName), WithCodeTheme(codeTheme))
+			tokens, err := conv.highlightCode("// explain\nx := 1\n", "go")
+			if err != nil {
+				t.Fatalf("highlightCode() error = %v", err)
+			}
+			var comment *Token
+			for i := range tokens {
+				if tokens[i].Type.InCategory(chroma.Comment) {
+					comment = &tokens[i]
+					break
+				}
+			}
+			if comment == nil {
+				t.Fatalf("%s/%s: no comment token in %v", themeName, codeTheme, tokens)
+			}
+			col := RGB{comment.Color[0], comment.Color[1], comment.Color[2]}
+			if ratio := contrastRatio(col, conv.theme.CodeBackground); ratio < minCommentContrast {
+				t.Errorf("%s/%s: comment color %v has contrast %.2f against the code background, want >= %.1f", themeName, codeTheme, col, ratio, minCommentContrast)
+			}
+			wantItalic := styles.Get(codeTheme).Get(comment.Type).Italic == chroma.Yes
+			if comment.Italic != wantItalic {
+				t.Errorf("%s/%s: comment Italic = %v, want %v", themeName, codeTheme, comment.Italic, wantItalic)
+			}
+		}
+	}
+
+	// emacs sets comments in italics; the flag survives line splitting
+	tokens, _ := NewConverter(WithCodeTheme("emacs")).highlightCode("// explain\n", "go")
+	if lines := splitTokensIntoLines(tokens); len(lines) == 0 || len(lines[0]) == 0 || !lines[0][0].Italic {
+		t.Errorf("emacs comment is not italic after splitTokensIntoLines: %+v", lines)
+	}
+}
+
+func TestWithIconSet(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "check.png"), 16, 16)
+
+	conv := NewConverter(WithIconSet(map[string]string{"check": "check.png"}))
+	conv.slideDir = dir
+	fragments := conv.parseFormatting("Tests :check: and docs :nope:")
+	var got []string
+	for _, f := range fragments {
+		got = append(got, fmt.Sprintf("%q %s", f.Text, filepath.Base(f.Icon)))
+	}
+	want := []string{`"Tests " .`, `":check:" check.png`, `" and docs :nope:" .`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fragments = %q, want %q", got, want)
+	}
+
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderSlide(present.Section{Title: "Status", Elem: []present.Elem{present.HTML{HTML: "<p>Tests :check: and docs :nope:</p>"}}})
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	pdf := buf.String()
+	if !strings.Contains(pdf, "/Subtype /Image") {
+		t.Error("icon image not embedded")
+	}
+	if strings.Contains(pdf, "(:check: )Tj") {
+		t.Error("known shortcode drawn as text")
+	}
+	if !strings.Contains(pdf, "(:nope: )Tj") {
+		t.Error("unknown shortcode not kept as text")
+	}
+	if len(conv.Warnings()) != 0 {
+		t.Errorf("unexpected warnings: %v", conv.Warnings())
+	}
+}
+
+func TestConvertBundle(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "chart.png"), 40, 30)
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Talk\n\n## Chart\n\n![chart](chart.png)\n\n## Again\n\n![chart](chart.png)\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := filepath.Join(dir, "out")
+	conv := NewConverter(WithTheme("dark"), WithCodeTheme("github"))
+	if _, err := conv.ConvertBundle(slideFile, outDir); err != nil {
+		t.Fatalf("ConvertBundle() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "talk.pdf")); err != nil {
+		t.Fatalf("PDF not written: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "talk.json"))
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("manifest is not JSON: %v", err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	want := map[string]any{
+		"source":        "talk.md",
+		"source_sha256": hex.EncodeToString(sum[:]),
+		"pdf":           "talk.pdf",
+		"title":         "Talk",
+		"theme":         "dark",
+		"code_theme":    "github",
+		"slides":        3.0,
+		"pages":         3.0,
+		"images":        []any{"chart.png"},
+	}
+	for key, value := range want {
+		if !reflect.DeepEqual(m[key], value) {
+			t.Errorf("manifest %s = %v, want %v", key, m[key], value)
+		}
+	}
+	if generated, _ := m["generated_at"].(string); generated == "" {
+		t.Error("manifest has no generated_at")
+	} else if at, err := time.Parse(time.RFC3339, generated); err != nil {
+		t.Errorf("generated_at = %q is not RFC 3339: %v", generated, err)
+	} else if want := conv.generated.Truncate(time.Second); !at.Equal(want) {
+		t.Errorf("generated_at = %v, want the PDF's generation time %v", at, want)
+	}
+}
+
+func TestNamedDestinations(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Talk\n\n## Intro {#intro}\n\nHello\n\n## Why Go?\n\nBecause\n\n## Why Go?\n\nAgain\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputFile := filepath.Join(dir, "talk.pdf")
+	res, err := NewConverter().ConvertTo(slideFile, outputFile)
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	want := map[string]int{
+		"slide-1": 1, "slide-2": 2, "slide-3": 3, "slide-4": 4,
+		"intro": 2, "why-go": 3, "why-go-2": 4,
+	}
+	if !reflect.DeepEqual(res.Destinations, want) {
+		t.Errorf("Destinations = %v, want %v", res.Destinations, want)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"/Dests ", "/intro [", "/why-go-2 [", "/Prev "} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("PDF does not contain %q", s)
+		}
+	}
+	if !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Error("PDF does not end with the new trailer")
+	}
+}
+
+func TestNamedDestinationsSlideNumbers(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Talk\n\n## Intro\n\nHello\n\n## Outro\n\nBye\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every slide is followed by its notes page; N stays the slide number
+	conv := NewConverter(WithQuiet(true), WithNotes("pages"), WithClosingSlide("Thanks", ""))
+	res, err := conv.ConvertTo(slideFile, filepath.Join(dir, "talk.pdf"))
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	want := map[string]int{
+		"slide-1": 1, "slide-2": 3, "slide-3": 5, "slide-4": 7,
+		"intro": 3, "outro": 5,
+	}
+	if !reflect.DeepEqual(res.Destinations, want) {
+		t.Errorf("Destinations = %v, want %v", res.Destinations, want)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Why Go?":           "why-go",
+		"  Hello, World!  ": "hello-world",
+		"Go 1.22 release":   "go-1-22-release",
+		"Привет мир":        "привет-мир",
+		"???":               "",
+	}
+	for title, want := range tests {
+		if got := slugify(title); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestMeasureText(t *testing.T) {
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+	conv.setTextFont("", 12)
+
+	conv.pdf.SetFontSize(18)
+	exact := conv.textWidth("hello world")
+	formatted := conv.textWidth("hello world ")
+	conv.pdf.SetFontSize(12)
+
+	tests := []struct {
+		name  string
+		text  string
+		width float64
+		lines int
+	}{
+		{"single line", "hello world", 200, 1},
+		{"exactly fitting", "hello world", exact, 1},
+		{"slightly too narrow", "hello world", exact - 0.1, 2},
+		{"multi-line", "one two three four five six seven eight nine ten", 40, 4},
+		{"explicit newline", "one\ntwo", 200, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, height := conv.measureText(tt.text, 18, tt.width)
+			if lines != tt.lines {
+				t.Errorf("measureText() lines = %d, want %d", lines, tt.lines)
+			}
+			if want := float64(tt.lines) * 9; height != want {
+				t.Errorf("measureText() height = %.1f, want %.1f", height, want)
+			}
+			if size, _ := conv.pdf.GetFontSize(); size != 12 {
+				t.Errorf("font size after measureText() = %.0f, want 12", size)
+			}
+		})
+	}
+
+	// Formatted text keeps room for the space after the last word
+	if lines, _ := conv.measureFormattedText("hello world", 18, formatted); lines != 1 {
+		t.Errorf("measureFormattedText() with room for the space = %d lines, want 1", lines)
+	}
+	if lines, _ := conv.measureFormattedText("hello world", 18, exact); lines != 2 {
+		t.Errorf("measureFormattedText() without room for the space = %d lines, want 2", lines)
+	}
+}
+
+func TestMeasureFragments(t *testing.T) {
+	wide := filepath.Join(t.TempDir(), "wide.png")
+	createTestPNG(t, wide, 80, 10)
+
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+	conv.setTextFont("", 18)
+	width := conv.textWidth("iiii iiii iiii ")
+
+	tests := []struct {
+		name      string
+		fragments []TextFragment
+		lines     int
+	}{
+		{"text", []TextFragment{{Text: "iiii iiii iiii"}}, 1},
+		{"inline code is wider", []TextFragment{{Text: "iiii iiii iiii", Code: true}}, 3},
+		{"key cap is not broken", []TextFragment{{Text: "i"}, {Text: "iiii iiii", Kbd: true}}, 2},
+		{"icon", []TextFragment{{Text: "i"}, {Text: ":wide:", Icon: wide}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, height := conv.measureFragments(tt.fragments, 18, width)
+			if lines != tt.lines || height != float64(tt.lines)*9 {
+				t.Errorf("measureFragments() = %d lines, %.1fmm; want %d lines", lines, height, tt.lines)
+			}
+			// The same layout as renderFormattedText draws
+			if drawn := int(math.Round((conv.renderFormattedText(tt.fragments, 20, 50, width, 9) - 50) / 9)); drawn != lines {
+				t.Errorf("renderFormattedText() drew %d lines, measureFragments() = %d", drawn, lines)
+			}
+		})
+	}
+}
+
+func TestWithMissingImagePlaceholder(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "chart.png")
+
+	for _, placeholder := range []bool{false, true} {
+		var log bytes.Buffer
+		conv := NewConverter(WithMissingImagePlaceholder(placeholder), WithLogOutput(&log))
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.pdf.AddPage()
+
+		y := conv.contentTop()
+		got := conv.renderImageFile(missing, "chart", imageSize{}, y)
+		if !strings.Contains(log.String(), "image not found") {
+			t.Errorf("placeholder=%v: missing image not reported, log %q", placeholder, log.String())
+		}
+
+		var out bytes.Buffer
+		if err := conv.pdf.Output(&out); err != nil {
+			t.Fatal(err)
+		}
+		drawn := strings.Contains(out.String(), "(image not found: chart.png)")
+		if placeholder {
+			if got != y+imagePlaceholderHeight+5 {
+				t.Errorf("with placeholder: y = %.1f, want %.1f", got, y+imagePlaceholderHeight+5)
+			}
+			if !drawn {
+				t.Error("with placeholder: label not drawn")
+			}
+		} else {
+			if got != y {
+				t.Errorf("without placeholder: y = %.1f, want unchanged %.1f", got, y)
+			}
+			if drawn {
+				t.Error("without placeholder: label drawn")
+			}
+		}
+	}
+}
+
+func TestWithLanguageTabWidth(t *testing.T) {
+	conv := NewConverter(WithLanguageTabWidth(map[string]int{"Go": 2, "yaml": 6}))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderSlide(present.Section{Title: "Config", Elem: []present.Elem{
+		present.HTML{HTML: "<pre><code class=\"language-go\">func f() {\n\treturn\n}\n</code></pre>"},
+		present.HTML{HTML: "<pre><code class=\"language-yaml\">server:\n\tport: 80\n</code></pre>"},
+	}})
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	x := func(text string) float64 {
+		m := regexp.MustCompile(`([\d.]+) [\d.]+ Td \(` + text + `\)`).FindSubmatch(buf.Bytes())
+		if m == nil {
+			t.Fatalf("%q not drawn", text)
+		}
+		v, _ := strconv.ParseFloat(string(m[1]), 64)
+		return v
+	}
+
+	goIndent := x("return") - x("func")
+	yamlIndent := x("port") - x("server")
+	if goIndent <= 0 || math.Abs(yamlIndent/goIndent-3) > 0.01 {
+		t.Errorf("indents go %.2f, yaml %.2f: want a 2 to 6 column ratio", goIndent, yamlIndent)
+	}
+}
+
+func TestWithCodeFolding(t *testing.T) {
+	const code = "<pre><code class=\"language-go\">package main\n\n// FOLD\nimport \"fmt\"\n// ENDFOLD\n\nfunc main() {}\n</code></pre>"
+
+	for _, folding := range []bool{false, true} {
+		conv := NewConverter(WithCodeFolding(folding))
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.renderSlide(present.Section{Title: "Main", Elem: []present.Elem{present.HTML{HTML: code}}})
+
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		pdf := buf.String()
+		if got := strings.Contains(pdf, "(// ...)"); got != folding {
+			t.Errorf("folding=%v: fold marker drawn = %v", folding, got)
+		}
+		if got := strings.Contains(pdf, "(import)"); got == folding {
+			t.Errorf("folding=%v: folded import drawn = %v", folding, got)
+		}
+		if !strings.Contains(pdf, "(main)") {
+			t.Errorf("folding=%v: code after the fold not drawn", folding)
+		}
+	}
+
+	// A # FOLD marker folds to a # comment
+	if got := (&Converter{codeFolding: true}).foldCode("import os\n# FOLD\nimport sys\n# ENDFOLD\nprint(os.name)"); got != "import os\n# ...\nprint(os.name)" {
+		t.Errorf("foldCode() = %q, want a # ... line", got)
+	}
+}
+
+func TestWithCornerSlideNumber(t *testing.T) {
+	conv := NewConverter(WithCornerSlideNumber(true), WithFooter("{title}|{n}"))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+
+	for n := 2; n <= 4; n++ {
+		conv.currentSlideNumber = n
+		conv.renderSlide(present.Section{Title: "Topic", Elem: []present.Elem{present.Text{Lines: []string{"Hello"}}}})
+
+		r, ok := conv.cornerNumberRect()
+		if !ok {
+			t.Fatal("cornerNumberRect() not placed")
+		}
+		if r.Y < conv.contentBottom() {
+			t.Errorf("slide %d: corner number at y=%.1f overlaps content ending at %.1f", n, r.Y, conv.contentBottom())
+		}
+		if x, w := conv.footerSpan(); x+w > r.X {
+			t.Errorf("slide %d: footer ends at x=%.1f, past the corner number at %.1f", n, x+w, r.X)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	pdf := buf.String()
+	for n := 2; n <= 4; n++ {
+		// The number is drawn in the large font, right after selecting it
+		re := regexp.MustCompile(fmt.Sprintf(`%.2f Tf ET\n[^\n]* Td \(%d\)Tj`, cornerNumberSize, n))
+		if !re.MatchString(pdf) {
+			t.Errorf("slide number %d not drawn in the corner font", n)
+		}
+	}
+}
+
+func TestConvertWithoutSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		title   string
+	}{
+		{"markdown header only", "talk.md", "# Only a Title\nA subtitle\n", "Only a Title"},
+		{"markdown header and author", "talk.md", "# Only a Title\n\nJane Doe\njane@example.com\n", "Only a Title"},
+		{"legacy header only", "talk.slide", "Only a Title\n", "Only a Title"},
+		{"empty file", "talk.slide", "", ""},
+		{"blank lines only", "talk.slide", "\n\n\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			slideFile := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(slideFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			outputFile := filepath.Join(dir, "out.pdf")
+			res, err := NewConverter().ConvertTo(slideFile, outputFile)
+			if err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+			if res.Slides != 1 || res.Pages != 1 {
+				t.Errorf("Slides = %d, Pages = %d, want a single title page", res.Slides, res.Pages)
+			}
+			if res.Title != tt.title {
+				t.Errorf("Title = %q, want %q", res.Title, tt.title)
+			}
+
+			data, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.Contains(data[len(data)-16:], []byte("%%EOF")) {
+				t.Error("output is not a complete PDF")
+			}
+
+			titles, err := SlideTitles(slideFile)
+			if err != nil {
+				t.Fatalf("SlideTitles() error = %v", err)
+			}
+			if len(titles) != 1 {
+				t.Errorf("SlideTitles() = %q, want only the title", titles)
+			}
+		})
+	}
+}
+
+func TestWithCodeThemeFallback(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	if err := os.WriteFile(slideFile, []byte("# Talk\n\n## Code\n\n```go\nfunc main() {}\n```\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		want     string
+		warnings int
+	}{
+		{"known theme", []Option{WithCodeTheme("dracula"), WithCodeThemeFallback("github")}, "dracula", 0},
+		{"unknown theme", []Option{WithCodeTheme("monokia"), WithCodeThemeFallback("github")}, "github", 1},
+		{"default fallback", []Option{WithCodeTheme("monokia")}, "monokai", 1},
+		{"unknown fallback", []Option{WithCodeTheme("monokia"), WithCodeThemeFallback("githb")}, styles.Fallback.Name, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(append(tt.opts, WithQuiet(true))...)
+			if err := conv.Convert(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if conv.codeTheme != tt.want {
+				t.Errorf("code theme = %q, want %q", conv.codeTheme, tt.want)
+			}
+			if len(conv.Warnings()) != tt.warnings {
+				t.Fatalf("warnings = %v, want %d", conv.Warnings(), tt.warnings)
+			}
+			if tt.warnings > 0 && !strings.Contains(conv.Warnings()[0].Message, fmt.Sprintf(`unknown code theme "monokia", using %q`, tt.want)) {
+				t.Errorf("warning = %q, want it to name the theme and its fallback", conv.Warnings()[0].Message)
+			}
+
+			tokens, err := conv.highlightCode("func main() {}", "go")
+			if err != nil {
+				t.Fatalf("highlightCode() error = %v", err)
+			}
+			want, _ := NewConverter(WithCodeTheme(tt.want)).highlightCode("func main() {}", "go")
+			if !reflect.DeepEqual(tokens, want) {
+				t.Errorf("tokens not highlighted with the %q style", tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAutoGlobalFontScale(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	var deck strings.Builder
+	deck.WriteString("# Talk\n\n## Sparse\n\nOne short paragraph.\n\n## Dense\n\n")
+	for i := 0; i < 6; i++ {
+		deck.WriteString("A paragraph long enough to wrap onto a second line of the slide at the regular body text size.\n\n")
+	}
+	deck.WriteString("## Also Sparse\n\nAnother short paragraph.\n")
+	if err := os.WriteFile(slideFile, []byte(deck.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewConverter(WithQuiet(true)).ConvertTo(slideFile, filepath.Join(dir, "plain.pdf"))
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if len(res.Warnings) == 0 || res.FontScale != 1 {
+		t.Fatalf("without the option: warnings %v, scale %.2f; want the dense slide to overflow at scale 1", res.Warnings, res.FontScale)
+	}
+
+	outputFile := filepath.Join(dir, "scaled.pdf")
+	res, err = NewConverter(WithQuiet(true), WithAutoGlobalFontScale(true)).ConvertTo(slideFile, outputFile)
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if len(res.Warnings) > 0 {
+		t.Errorf("with the option: unexpected warnings %v", res.Warnings)
+	}
+	if res.FontScale >= 1 || res.FontScale <= minGlobalFontScale {
+		t.Fatalf("FontScale = %.2f, want a reduced scale", res.FontScale)
+	}
+
+	// Every content slide sets its body text in the same reduced size
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := fmt.Sprintf("%.2f Tf", 18*res.FontScale)
+	pages := 0
+	for _, m := range regexp.MustCompile(`(?s)/FlateDecode /Length \d+>>\nstream\n(.*?)\nendstream`).FindAllSubmatch(data, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(r)
+		if err != nil || !bytes.Contains(content, []byte("29.00 Tf")) {
+			continue // not a content slide
+		}
+		pages++
+		if !bytes.Contains(content, []byte(body)) || bytes.Contains(content, []byte("18.00 Tf")) {
+			t.Errorf("content slide %d: body text not set at %s", pages, body)
+		}
+	}
+	if pages != 3 {
+		t.Errorf("found %d content slides, want 3", pages)
+	}
+}
+
+func TestWithGenerationStamp(t *testing.T) {
+	conv := NewConverter(WithGenerationStamp(true), WithToolVersion("v1.2.3"))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.generated = time.Date(2026, 2, 15, 10, 0, 0, 0, time.UTC)
+
+	want := "Generated 2026-02-15 by present2pdf v1.2.3"
+	if got := conv.generationStamp(); got != want {
+		t.Errorf("generationStamp() = %q, want %q", got, want)
+	}
+
+	conv.renderTitleSlide(&present.Doc{Title: "Talk"})
+	conv.renderSlide(present.Section{Title: "Topic", Elem: []present.Elem{present.Text{Lines: []string{"Hello"}}}})
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if got := strings.Count(buf.String(), "("+want+")"); got != 2 {
+		t.Errorf("stamp drawn %d times, want on both slides", got)
+	}
+
+	if got := NewConverter().generationStamp(); !strings.HasSuffix(got, " by present2pdf") {
+		t.Errorf("generationStamp() without a version = %q, want it to end with the tool name", got)
+	}
+}
+
+func TestWithPreserveLineBreaks(t *testing.T) {
+	address := present.Text{Lines: []string{"Jane Doe", "12 Main Street", "Springfield"}}
+
+	render := func(opts ...Option) string {
+		conv := NewConverter(opts...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.renderSlide(present.Section{Title: "Contact", Elem: []present.Elem{address}})
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		return buf.String()
+	}
+
+	out := render(WithPreserveLineBreaks(true))
+	for _, line := range address.Lines {
+		if !strings.Contains(out, "("+line+")Tj") {
+			t.Errorf("line %q not drawn on its own with WithPreserveLineBreaks", line)
+		}
+	}
+
+	out = render()
+	if !strings.Contains(out, "(Jane Doe 12 Main Street Springfield)Tj") {
+		t.Error("default rendering should reflow the lines into one")
+	}
+
+	// Markdown paragraphs keep their source lines and <br> breaks too
+	doc, err := parseDoc([]byte("# Talk\n\n## Contact\n\nJane Doe\n12 Main Street  \nSpringfield\n"), "talk.md")
+	if err != nil {
+		t.Fatalf("parseDoc() error = %v", err)
+	}
+	lineYs := func(opts ...Option) map[string]string {
+		conv := NewConverter(opts...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.renderSlide(doc.Sections[0])
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		ys := make(map[string]string)
+		for _, m := range regexp.MustCompile(`[\d.]+ ([\d.]+) Td \((\w+) \)Tj`).FindAllStringSubmatch(buf.String(), -1) {
+			ys[m[2]] = m[1]
+		}
+		return ys
+	}
+	ys := lineYs(WithPreserveLineBreaks(true))
+	if ys["Jane"] == ys["12"] || ys["12"] == ys["Springfield"] || ys["Jane"] == "" {
+		t.Errorf("markdown lines at y %v, want one line each with WithPreserveLineBreaks", ys)
+	}
+	ys = lineYs()
+	if ys["Jane"] != ys["Springfield"] || ys["Jane"] == "" {
+		t.Errorf("markdown lines at y %v, want the paragraph reflowed by default", ys)
+	}
+}
+
+func TestFormatDateLocalized(t *testing.T) {
+	date := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		expect string
+	}{
+		{"default", nil, "February 15, 2026"},
+		{"russian", []Option{WithLanguage("ru")}, "15 февраля 2026"},
+		{"russian month and year", []Option{WithLanguage("ru"), WithDateFormat("January 2006")}, "февраль 2026"},
+		{"russian short month", []Option{WithLanguage("ru"), WithDateFormat("02 Jan 2006")}, "15 фев 2026"},
+		{"iso layout", []Option{WithDateFormat("2006-01-02")}, "2026-02-15"},
+		{"russian locale name", []Option{WithDateFormat("ru")}, "15 февраля 2026"},
+		{"english locale name", []Option{WithLanguage("ru"), WithDateFormat("EN")}, "February 15, 2026"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewConverter(tt.opts...).formatDate(date); got != tt.expect {
+				t.Errorf("formatDate() = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+
+	// The Cyrillic month reaches the title slide through the cp1251 fonts
+	conv := NewConverter(WithLanguage("ru"))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderTitleSlide(&present.Doc{Title: "Доклад", Time: date})
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "("+conv.translator("15 февраля 2026")+")Tj") {
+		t.Error("title slide date not drawn with the Russian month name")
+	}
+}
+
+func TestFrontMatterLanguage(t *testing.T) {
+	var log bytes.Buffer
+	conv := NewConverter(WithLogOutput(&log))
+	conv.applyFrontMatter(map[string]string{"lang": "ru"})
+	conv.resolveLanguage()
+	if conv.language != "ru" {
+		t.Errorf("language = %q, want ru", conv.language)
+	}
+
+	conv = NewConverter(WithLogOutput(&log), WithLanguage("xx"))
+	conv.resolveLanguage()
+	if conv.language != "en" || !strings.Contains(log.String(), `unknown language "xx"`) {
+		t.Errorf("unknown language: language = %q, log = %q", conv.language, log.String())
+	}
+}
+
+func TestWithNavDots(t *testing.T) {
+	render := func(sections int) string {
+		doc := &present.Doc{Title: "Talk"}
+		for i := 0; i < sections; i++ {
+			doc.Sections = append(doc.Sections, present.Section{Title: fmt.Sprintf("Topic %d", i+1)})
+		}
+		conv := NewConverter(WithNavDots(true))
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.registerAnchors(doc)
+		conv.footerDoc(doc)
+
+		conv.currentSlideNumber = 1
+		conv.renderTitleSlide(doc)
+		for i, section := range doc.Sections {
+			conv.currentSlideNumber = i + 2
+			conv.renderSlide(section)
+		}
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		return buf.String()
+	}
+
+	// Page N is object 1+2N; each of the two content slides links to the
+	// other two slides
+	pdf := render(2)
+	for page, want := range map[int]int{1: 2, 2: 1, 3: 1} {
+		dest := regexp.MustCompile(fmt.Sprintf(`/Subtype /Link [^>]*/Dest \[%d 0 R `, 1+2*page))
+		if got := len(dest.FindAllString(pdf, -1)); got != want {
+			t.Errorf("%d dots link to slide %d, want %d", got, page, want)
+		}
+	}
+
+	if pdf := render(80); strings.Contains(pdf, "/Subtype /Link") {
+		t.Error("dots drawn for a deck with more slides than fit")
+	}
+}
+
+func TestNavDotsHandout(t *testing.T) {
+	content := []byte("# Talk\n\n## One\n\nA\n\n## Two\n\nB\n\n## Three\n\nC\n")
+	conv := NewConverter(WithQuiet(true), WithHandout(4), WithNavDots(true))
+	pdf, err := conv.ConvertBytes(content, t.TempDir())
+	if err != nil {
+		t.Fatalf("ConvertBytes() error = %v", err)
+	}
+
+	// Each content slide links to the three others from inside its own slot
+	const k = 72 / 25.4
+	re := regexp.MustCompile(`/Subtype /Link /Rect \[([\d.]+) ([\d.]+) ([\d.]+) ([\d.]+)\]`)
+	rects := re.FindAllStringSubmatch(string(pdf), -1)
+	if len(rects) != 9 {
+		t.Fatalf("%d dot links, want 9", len(rects))
+	}
+	seen := make(map[string]bool)
+	for _, m := range rects {
+		var v [4]float64
+		for i := range v {
+			v[i], _ = strconv.ParseFloat(m[i+1], 64)
+		}
+		// PDF space: origin at the bottom left of the A4 landscape page
+		x1, x2, top, bottom := v[0]/k, v[2]/k, a4Height-v[1]/k, a4Height-v[3]/k
+		inside := false
+		for i := 1; i < 4; i++ {
+			s := conv.handoutSlot(i)
+			if x1 >= s.X && x2 <= s.X+conv.pageWidth*s.Scale && top >= s.Y && bottom <= s.Y+conv.pageHeight*s.Scale {
+				inside = true
+			}
+		}
+		if !inside {
+			t.Errorf("link rect %v outside the content slide slots", m[0])
+		}
+		if seen[m[0]] {
+			t.Errorf("link rect %v repeated", m[0])
+		}
+		seen[m[0]] = true
+	}
+}
+
+func TestWithGrayscale(t *testing.T) {
+	keywordColor := func(conv *Converter) [3]int {
+		tokens, err := conv.highlightCode("func main() {}", "go")
+		if err != nil {
+			t.Fatalf("highlightCode() error = %v", err)
+		}
+		return tokens[0].Color
+	}
+
+	if col := keywordColor(NewConverter()); col[0] == col[1] && col[1] == col[2] {
+		t.Fatalf("keyword color %v is already gray, pick another token", col)
+	}
+
+	conv := NewConverter(WithGrayscale(true))
+	conv.applyGrayscale()
+	if col := keywordColor(conv); col[0] != col[1] || col[1] != col[2] {
+		t.Errorf("keyword color = %v, want equal R, G and B", col)
+	}
+
+	// Every color of a slide with a link and code is drawn with the gray
+	// operators, never as RGB
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderSlide(present.Section{Title: "Topic", Elem: []present.Elem{present.Text{Lines: []string{"See https://go.dev"}}}})
+	conv.renderCodeText("func main() {}", "go", 100)
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if m := regexp.MustCompile(`[\d.]+ [\d.]+ [\d.]+ (rg|RG)\b`).FindString(buf.String()); m != "" {
+		t.Errorf("color %q drawn in RGB", m)
+	}
+
+	if got := grayRGB(RGB{255, 255, 255}); got != (RGB{255, 255, 255}) {
+		t.Errorf("grayRGB(white) = %v", got)
+	}
+	col := RGB{41, 128, 185}
+	if diff := math.Abs(relativeLuminance(grayRGB(col)) - relativeLuminance(col)); diff > 0.005 {
+		t.Errorf("grayRGB(%v) changes luminance by %.4f", col, diff)
+	}
+}
+
+func TestWithPageSize(t *testing.T) {
+	tests := []struct {
+		size          string
+		width, height float64
+	}{
+		{"A4", 297, 210},
+		{"16:9", 373.33, 210},
+		{"4:3", 280, 210},
+		{"unknown", 297, 210},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			conv := NewConverter(WithPageSize(tt.size))
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.pdf.SetCompression(false)
+			conv.renderSlide(present.Section{Title: "Topic", Elem: []present.Elem{present.Text{Lines: []string{"Hello"}}}})
+
+			w, h := conv.pdf.GetPageSize()
+			if math.Abs(w-tt.width) > 0.01 || math.Abs(h-tt.height) > 0.01 {
+				t.Errorf("page size = %.2fx%.2f, want %.2fx%.2f", w, h, tt.width, tt.height)
+			}
+			if want := tt.width - 2*slideMargin; math.Abs(conv.contentWidth()-want) > 0.01 {
+				t.Errorf("contentWidth() = %.2f, want %.2f", conv.contentWidth(), want)
+			}
+
+			var buf bytes.Buffer
+			if err := conv.pdf.Output(&buf); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+			mediaBox := fmt.Sprintf("/MediaBox [0 0 %.2f %.2f]", w*72/25.4, h*72/25.4)
+			if !strings.Contains(buf.String(), mediaBox) {
+				t.Errorf("PDF has no %s", mediaBox)
+			}
+		})
+	}
+
+	conv := NewConverter()
+	conv.applyFrontMatter(map[string]string{"page-size": "16:9"})
+	if conv.pageWidth != pageSizes["16:9"][0] {
+		t.Errorf("front matter page-size: width = %.2f, want 16:9", conv.pageWidth)
+	}
+	conv = NewConverter(WithPageSize("4:3"))
+	conv.applyFrontMatter(map[string]string{"page-size": "16:9"})
+	if conv.pageWidth != pageSizes["4:3"][0] {
+		t.Errorf("WithPageSize should win over the front matter, width = %.2f", conv.pageWidth)
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	// A complete theme file written from the dark theme, in both color forms
+	fields := map[string]any{}
+	v := reflect.ValueOf(DarkTheme)
+	for i := 0; i < v.NumField(); i++ {
+		col := v.Field(i).Interface().(RGB)
+		if i%2 == 0 {
+			fields[v.Type().Field(i).Name] = fmt.Sprintf("#%02X%02X%02X", col.R, col.G, col.B)
+		} else {
+			fields[v.Type().Field(i).Name] = []int{col.R, col.G, col.B}
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theme, err := LoadTheme(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("LoadTheme() error = %v", err)
+	}
+	if theme != DarkTheme {
+		t.Errorf("LoadTheme() = %+v, want the dark theme", theme)
+	}
+
+	// Optional fields come from the theme matching the slide background
+	for _, name := range []string{"Letterbox", "Footer", "MarkBackground", "MarkText", "KbdBackground", "KbdBorder"} {
+		delete(fields, name)
+	}
+	data, _ = json.Marshal(fields)
+	if theme, err := LoadTheme(bytes.NewReader(data)); err != nil || theme != DarkTheme {
+		t.Errorf("LoadTheme() without optional fields = %+v, %v; want the dark theme", theme, err)
+	}
+
+	tests := []struct {
+		name string
+		json string
+		want []string
+	}{
+		{"missing fields", `{"TitleBackground": "#2980B9"}`, []string{"missing theme fields: TitleText, TitleSubtext", "InlineCodeText"}},
+		{"unknown field", `{"SlideTxt": "#000000"}`, []string{"unknown theme fields: SlideTxt", "SlideText"}},
+		{"bad hex", `{"TitleBackground": "blue"}`, []string{"TitleBackground", `"blue" is not #RRGGBB`}},
+		{"short array", `{"TitleBackground": [1, 2]}`, []string{"TitleBackground", "[1, 2]"}},
+		{"out of range", `{"TitleBackground": [1, 2, 300]}`, []string{"outside 0-255"}},
+		{"not json", `theme`, []string{"invalid theme JSON"}},
+		{"unknown base", `{"extends": "solarized"}`, []string{`unknown theme "solarized"`, "dark, light"}},
+		{"extends with unknown field", `{"extends": "dark", "LinkColour": "#FFB000"}`, []string{"unknown theme fields: LinkColour"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTheme(strings.NewReader(tt.json))
+			if err == nil {
+				t.Fatal("LoadTheme() error = nil")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadTheme() error = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadThemeExtends(t *testing.T) {
+	theme, err := LoadTheme(strings.NewReader(`{"extends": "dark", "LinkColor": "#FFB000"}`))
+	if err != nil {
+		t.Fatalf("LoadTheme() error = %v", err)
+	}
+	want := DarkTheme
+	want.LinkColor = RGB{255, 176, 0}
+	if theme != want {
+		t.Errorf("LoadTheme() = %+v, want the dark theme with LinkColor #FFB000", theme)
+	}
+}
+
+func TestWithThemeFile(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	if err := os.WriteFile(slideFile, []byte("---\ntheme: dark\n---\n# Talk\n\n## Slide\n\nHello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	custom := LightTheme
+	custom.SlideTitle = RGB{200, 16, 46}
+	fields := map[string]string{}
+	v := reflect.ValueOf(custom)
+	for i := 0; i < v.NumField(); i++ {
+		col := v.Field(i).Interface().(RGB)
+		fields[v.Type().Field(i).Name] = fmt.Sprintf("#%02x%02x%02x", col.R, col.G, col.B)
+	}
+	data, _ := json.Marshal(fields)
+	themeFile := filepath.Join(dir, "brand.json")
+	if err := os.WriteFile(themeFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := NewConverter(WithTheme("dark"), WithThemeFile(themeFile))
+	if _, err := conv.ConvertTo(slideFile, filepath.Join(dir, "out.pdf")); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if conv.theme != custom {
+		t.Errorf("theme = %+v, want the theme file over -theme and the front matter", conv.theme)
+	}
+
+	conv = NewConverter(WithThemeFile(filepath.Join(dir, "missing.json")))
+	if _, err := conv.ConvertTo(slideFile, filepath.Join(dir, "out.pdf")); err == nil || !strings.Contains(err.Error(), "theme file") {
+		t.Errorf("ConvertTo() with a missing theme file: error = %v", err)
+	}
+}
+
+func TestPDFMetadata(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Доклад о Go\nПодзаголовок\n\nИван Петров\nivan@example.com\n\nJane Doe\n\n## Slide\n\nHello\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputFile := filepath.Join(dir, "out.pdf")
+	if _, err := NewConverter(WithToolVersion("v1.2.3")).ConvertTo(slideFile, outputFile); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// gofpdf stores UTF-8 metadata as big-endian UTF-16 with a byte order mark
+	utf16 := func(s string) string {
+		var b strings.Builder
+		b.WriteString("\xFE\xFF")
+		for _, r := range s {
+			b.WriteByte(byte(r >> 8))
+			b.WriteByte(byte(r))
+		}
+		return b.String()
+	}
+	for key, value := range map[string]string{
+		"Title":   "Доклад о Go",
+		"Subject": "Подзаголовок",
+		"Author":  "Иван Петров, Jane Doe",
+		"Creator": "present2pdf v1.2.3",
+	} {
+		if want := "/" + key + " (" + utf16(value) + ")"; !bytes.Contains(data, []byte(want)) {
+			t.Errorf("PDF has no /%s %q", key, value)
+		}
+	}
+}
+
+func TestWithBookmarks(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Talk\n\n## Введение\n\nHello\n\n### Details\n\nMore\n\n## Summary\n\nBye\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	convert := func(opts ...Option) string {
+		outputFile := filepath.Join(dir, "out.pdf")
+		if _, err := NewConverter(opts...).ConvertTo(slideFile, outputFile); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// Outline items are written as "N 0 obj\n<</Title (...)\n/Parent P 0 R"
+	itemRe := regexp.MustCompile(`(\d+) 0 obj\n<</Title \(([^)]*)\)\n/Parent (\d+) 0 R`)
+	for _, opts := range [][]Option{nil, {WithFontSubsetting(true)}} {
+		pdf := convert(opts...)
+		obj, parent := map[string]string{}, map[string]string{}
+		for _, m := range itemRe.FindAllStringSubmatch(pdf, -1) {
+			obj[m[2]], parent[m[2]] = m[1], m[3]
+		}
+		intro, details, summary := pdfUTF16("Введение"), pdfUTF16("Details"), pdfUTF16("Summary")
+		if len(obj) != 3 || obj[intro] == "" || obj[details] == "" || obj[summary] == "" {
+			t.Fatalf("outline items = %q, want the two slides and the subsection", obj)
+		}
+		if parent[intro] != parent[summary] {
+			t.Error("slide bookmarks are not on the same level")
+		}
+		if parent[details] != obj[intro] {
+			t.Error("subsection bookmark not nested under its slide")
+		}
+	}
+
+	if pdf := convert(WithBookmarks(false)); strings.Contains(pdf, "/Outlines") {
+		t.Error("outline written with WithBookmarks(false)")
+	}
+}
+
+func TestWithPageNumbers(t *testing.T) {
+	doc := &present.Doc{Title: "Talk", Sections: []present.Section{
+		{Title: "One", Elem: []present.Elem{present.Text{Lines: []string{"Hello"}}}},
+		{Title: "Two", Elem: []present.Elem{present.Text{Lines: []string{"Bye"}}}},
+	}}
+	render := func(opts ...Option) string {
+		conv := NewConverter(opts...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.footerDoc(doc)
+		conv.currentSlideNumber = 1
+		conv.renderTitleSlide(doc)
+		for i, section := range doc.Sections {
+			conv.currentSlideNumber = i + 2
+			conv.renderSlide(section)
+		}
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		return buf.String()
+	}
+
+	pdf := render(WithPageNumbers(true))
+	for _, want := range []string{"(2 / 3)Tj", "(3 / 3)Tj"} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("page number %s not drawn", want)
+		}
+	}
+	if strings.Contains(pdf, "(1 / 3)Tj") {
+		t.Error("page number drawn on the title slide")
+	}
+
+	if pdf := render(WithPageNumbers(true), WithFooter("{title}|{n}")); strings.Contains(pdf, "(2 / 3)Tj") {
+		t.Error("page number drawn over the footer's right part")
+	}
+	if pdf := render(); strings.Contains(pdf, "(2 / 3)Tj") {
+		t.Error("page number drawn without WithPageNumbers")
+	}
+}
+
+func TestWithCodeLineNumbers(t *testing.T) {
+	var code strings.Builder
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&code, "%c := f()\n", 'a'+i-1)
+	}
+
+	render := func(numbers bool) []byte {
+		conv := NewConverter(WithCodeLineNumbers(numbers))
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.beginSlide()
+		conv.renderCodeText(code.String(), "go", 60)
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		return buf.Bytes()
+	}
+	x := func(pdf []byte, text string) float64 {
+		m := regexp.MustCompile(`([\d.]+) [\d.]+ Td \(` + text + `\)Tj`).FindSubmatch(pdf)
+		if m == nil {
+			t.Fatalf("%q not drawn", text)
+		}
+		v, _ := strconv.ParseFloat(string(m[1]), 64)
+		return v
+	}
+
+	pdf := render(true)
+	for i := 1; i <= 12; i++ {
+		x(pdf, strconv.Itoa(i))
+	}
+	// Numbers are right-aligned: the one-digit 9 starts one column after 12
+	if d := x(pdf, "9") - x(pdf, "12"); d <= 0 {
+		t.Errorf("9 starts %.2f after 12, want right-aligned numbers", d)
+	}
+	// The code moves right by the gutter and stays clear of the numbers
+	if x(pdf, "l") <= x(pdf, "12")+1 {
+		t.Error("code overlaps the line numbers")
+	}
+	plain := render(false)
+	if x(pdf, "a") <= x(plain, "a") {
+		t.Error("code not offset by the line number gutter")
+	}
+	if bytes.Contains(plain, []byte("(12)Tj")) {
+		t.Error("line numbers drawn without WithCodeLineNumbers")
+	}
+}
+
+func TestWithContinuation(t *testing.T) {
+	var elems []present.Elem
+	for i := 1; i <= 8; i++ {
+		elems = append(elems, present.Text{Lines: []string{fmt.Sprintf("Paragraph %d", i)}})
+	}
+	var code strings.Builder
+	for i := 1; i <= 45; i++ {
+		fmt.Fprintf(&code, "line%d()\n", i)
+	}
+	elems = append(elems, present.Code{Raw: []byte(code.String())}, present.Text{Lines: []string{"The end"}})
+	section := present.Section{Title: "Long", Elem: elems}
+
+	render := func(opts ...Option) (*Converter, string) {
+		conv := NewConverter(append(opts, WithQuiet(true))...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		cleanup, err := conv.prepareScratch()
+		if err != nil {
+			t.Fatalf("prepareScratch() error = %v", err)
+		}
+		defer cleanup()
+		conv.pdf.SetCompression(false)
+		conv.currentSlideNumber = 2
+		conv.renderSlide(section)
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		return conv, buf.String()
+	}
+
+	conv, pdf := render(WithContinuation(true))
+	if len(conv.Warnings()) != 0 {
+		t.Errorf("warnings = %v, want the slide to fit on continuation pages", conv.Warnings())
+	}
+	if pages := conv.pdf.PageCount(); pages < 3 {
+		t.Errorf("pages = %d, want the paragraphs and the code split over 3 or more", pages)
+	}
+	if got := strings.Count(pdf, `(Long \(cont.\))Tj`); got != conv.pdf.PageCount()-1 {
+		t.Errorf("continuation titles = %d, want one per extra page", got)
+	}
+	for _, want := range []string{"(Paragraph 8)Tj", "(line1)Tj", "(line45)Tj", "(The end)Tj"} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("%s not drawn", want)
+		}
+	}
+	if strings.Contains(pdf, "(...)Tj") {
+		t.Error("code block truncated with WithContinuation")
+	}
+
+	// Line numbers go on counting over the continuation pages
+	conv, pdf = render(WithContinuation(true), WithCodeLineNumbers(true))
+	for i := 1; i <= 45; i++ {
+		if n := strings.Count(pdf, fmt.Sprintf("(%d)Tj", i)); n != 1 {
+			t.Errorf("line number %d drawn %d times, want once", i, n)
+		}
+	}
+
+	conv, pdf = render()
+	if conv.pdf.PageCount() != 1 || len(conv.Warnings()) == 0 || strings.Contains(pdf, "(The end)Tj") {
+		t.Errorf("default: %d pages, %d warnings; want the slide truncated on one page", conv.pdf.PageCount(), len(conv.Warnings()))
+	}
+}
+
+func TestWithAutoFit(t *testing.T) {
+	var elems []present.Elem
+	for i := 1; i <= 12; i++ {
+		elems = append(elems, present.Text{Lines: []string{fmt.Sprintf("Paragraph %d", i)}})
+	}
+	section := present.Section{Title: "Dense", Elem: elems}
+
+	render := func(opts ...Option) (*Converter, string) {
+		conv := NewConverter(append(opts, WithQuiet(true))...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		cleanup, err := conv.prepareScratch()
+		if err != nil {
+			t.Fatalf("prepareScratch() error = %v", err)
+		}
+		defer cleanup()
+		conv.pdf.SetCompression(false)
+		conv.currentSlideNumber = 2
+		conv.renderSlide(section)
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		return conv, buf.String()
+	}
+
+	conv, pdf := render(WithAutoFit(true))
+	warnings := conv.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "shrunk to") {
+		t.Errorf("warnings = %v, want only the chosen scale", warnings)
+	}
+	if !strings.Contains(pdf, "(Paragraph 12)Tj") {
+		t.Error("last paragraph not drawn")
+	}
+	if strings.Contains(pdf, "21.00 Tf") {
+		t.Error("paragraphs drawn at the full 21pt")
+	}
+	if conv.fontScale != 1 {
+		t.Errorf("fontScale = %v after the slide, want 1", conv.fontScale)
+	}
+
+	conv, pdf = render()
+	if len(conv.Warnings()) == 0 || strings.Contains(pdf, "(Paragraph 12)Tj") {
+		t.Errorf("default: %d warnings; want the slide truncated", len(conv.Warnings()))
+	}
+}
+
+func TestConvertCodeDirectiveOmit(t *testing.T) {
+	dir := t.TempDir()
+	goSource := "package main\n\n// START OMIT\nfunc add(a, b int) int {\n\treturn a + b // HL\n}\n// END OMIT\n\nfunc main() {\n\t_ = add(1, 2) // OMIT \n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "snip.go"), []byte(goSource), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	slideFile := filepath.Join(dir, "code.slide")
+	content := "Code\n\n* Snippet\n\n.code snip.go /START OMIT/,/END OMIT/\n\n* Whole\n\n.code snip.go\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	doc, err := parseDoc([]byte(content), slideFile)
+	if err != nil {
+		t.Fatalf("parseDoc() error = %v", err)
+	}
+	snippet := doc.Sections[0].Elem[0].(present.Code)
+	if got, want := codeSnippet(string(snippet.Raw)), "func add(a, b int) int {\n\treturn a + b\n}"; got != want {
+		t.Errorf("codeSnippet() = %q, want %q", got, want)
+	}
+	whole := doc.Sections[1].Elem[0].(present.Code)
+	if strings.Contains(codeSnippet(string(whole.Raw)), "OMIT") {
+		t.Errorf("codeSnippet() = %q, want OMIT lines dropped", codeSnippet(string(whole.Raw)))
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	if got := conv.codeLanguage("", whole.FileName, string(whole.Raw)); got != "go" {
+		t.Errorf("language = %q, want go from the file name", got)
+	}
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	for _, section := range doc.Sections {
+		conv.renderSlide(section)
+	}
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "(add)Tj") {
+		t.Error("snippet not drawn")
+	}
+	if strings.Contains(buf.String(), "OMIT)Tj") || strings.Contains(buf.String(), "HL)Tj") {
+		t.Error("OMIT or HL markers drawn")
+	}
+}
+
+func TestNestedListsIndented(t *testing.T) {
+	html := present.HTML{HTML: "<p>Intro</p>\n<ul>\n<li>one\n<ul>\n<li>sub</li>\n</ul>\n</li>\n<li>two</li>\n</ul>\n<p>After</p>"}
+	legacy := present.List{Bullet: []string{"one\n- sub", "two"}}
+
+	for name, elem := range map[string]present.Elem{"html": html, "legacy": legacy} {
+		t.Run(name, func(t *testing.T) {
+			conv := NewConverter()
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.pdf.SetCompression(false)
+			conv.renderSlide(present.Section{Title: "Nested", Elem: []present.Elem{elem}})
+			var buf bytes.Buffer
+			if err := conv.pdf.Output(&buf); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+
+			x := func(text string) float64 {
+				m := regexp.MustCompile(`BT ([\d.]+) [\d.]+ Td \(` + text + ` \)Tj`).FindStringSubmatch(buf.String())
+				if m == nil {
+					t.Fatalf("%s not drawn", text)
+				}
+				v, _ := strconv.ParseFloat(m[1], 64)
+				return v
+			}
+			if one, sub, two := x("one"), x("sub"), x("two"); sub <= one || two != one {
+				t.Errorf("text x: one %.2f, sub %.2f, two %.2f; want sub indented", one, sub, two)
+			}
+			if elem == html && !strings.Contains(buf.String(), "(After )Tj") {
+				t.Error("paragraph after the nested list not drawn")
+			}
+		})
+	}
+}
+
+func TestOrderedLists(t *testing.T) {
+	const html = "<p>Steps</p>\n<ol start=\"5\">\n<li>five\n<ul>\n<li>note</li>\n</ul>\n</li>\n<li>six\n<ol>\n<li>one</li>\n</ol>\n</li>\n</ol>"
+
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+
+	var got []string
+	for _, item := range conv.parseHTMLList(html[strings.Index(html, "<ol"):]) {
+		marker := conv.bulletFor(item.Depth)
+		if item.Ordered {
+			marker = fmt.Sprintf("%d.", item.Number)
+		}
+		got = append(got, marker+" "+item.Fragments[0].Text)
+	}
+	want := []string{"5. five", "◦ note", "6. six", "1. one"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %q, want %q", got, want)
+	}
+
+	conv.renderSlide(present.Section{Title: "Ordered", Elem: []present.Elem{present.HTML{HTML: html}}})
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	for _, want := range []string{"(Steps )Tj", "(5.)Tj", "(6.)Tj", "(1.)Tj", "(one )Tj"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s not drawn", want)
+		}
+	}
+
+	// The gutter widens for two-digit numbers so they do not run into the text
+	conv.setTextFont("", 18)
+	if widen := conv.numberGutterWidening(conv.parseHTMLList("<ol><li>a</li><li>b</li></ol>")); widen[0] != 0 {
+		t.Errorf("numberGutterWidening(1., 2.) = %.1f, want 0", widen[0])
+	}
+	items := conv.parseHTMLList("<ol start=\"98\"><li>a</li><li>b</li><li>c</li></ol>")
+	widen := conv.numberGutterWidening(items)
+	labelWidth := conv.pdf.GetStringWidth("100.")
+	textX := conv.renderItemNumber(100, 0, widen[0], conv.contentTop(), 9)
+	if labelEnd := conv.contentX() + labelWidth + conv.pdf.GetCellMargin(); labelEnd+listNumberGap > textX+0.01 {
+		t.Errorf("label 100. ends at %.1f, text starts at %.1f", labelEnd, textX)
+	}
+}
+
+func TestBackgroundImage(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "bg.png"), 400, 100)
+	slideFile := filepath.Join(dir, "talk.slide")
+	content := "Talk\n\n* Cover\n\n.background bg.png\n\nOn top\n\n* Missing\n\n.background nope.png\n\nText\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	res, err := conv.ConvertTo(slideFile, filepath.Join(dir, "out.pdf"))
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if len(res.Images) != 1 || res.Images[0].Slide != 2 || filepath.Base(res.Images[0].Path) != "bg.png" {
+		t.Errorf("Images = %+v, want bg.png on slide 2", res.Images)
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].Slide != 3 || !strings.Contains(res.Warnings[0].Message, "background image not found") {
+		t.Errorf("Warnings = %+v, want the missing background on slide 3", res.Warnings)
+	}
+
+	// The 4:1 image covers the 297x210mm page at full height, centered and
+	// cropped at the sides
+	doc, err := parseDoc([]byte(content), slideFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conv = NewConverter(WithQuiet(true))
+	conv.slideDir = dir
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderSlide(doc.Sections[0])
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	m := regexp.MustCompile(`q ([\d.]+) 0 0 ([\d.]+) (-?[\d.]+) `).FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatal("background not drawn")
+	}
+	wants := []float64{840, 210, -(840 - 297) / 2.0}
+	for i, want := range wants {
+		if got, _ := strconv.ParseFloat(m[i+1], 64); math.Abs(got-want*72/25.4) > 0.01 {
+			t.Errorf("background image %s = %s, want %.2f", []string{"width", "height", "x"}[i], m[i+1], want*72/25.4)
+		}
+	}
+	if !strings.Contains(buf.String(), "(On top)Tj") {
+		t.Error("slide text not drawn over the background")
+	}
+}
+
+func TestRemoteImages(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "pic.png"), 400, 50)
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.ServeFile(w, r, filepath.Join(dir, strings.TrimPrefix(r.URL.Path, "/")))
+	}))
+	defer srv.Close()
+
+	slideFile := filepath.Join(dir, "talk.md")
+	content := "# Talk\n\n## One\n\n![Chart](" + srv.URL + "/pic.png)\n\n## Two\n\n![Again](" + srv.URL + "/pic.png)\n\n## Missing\n\n![Gone](" + srv.URL + "/gone.png)\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := NewConverter(WithQuiet(true), WithAllowRemoteImages(true))
+	res, err := conv.ConvertTo(slideFile, filepath.Join(dir, "out.pdf"))
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if len(res.Images) != 2 || res.Images[0].Path != srv.URL+"/pic.png" || res.Images[1].Alt != "Again" {
+		t.Errorf("Images = %+v, want the URL on slides 2 and 3", res.Images)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want one per URL", requests)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0].Message, "failed to download image") {
+		t.Errorf("Warnings = %+v, want the 404 reported", res.Warnings)
+	}
+	if conv.imageDir != "" {
+		t.Errorf("downloads not removed: %s", conv.imageDir)
+	}
+
+	requests = 0
+	res, err = NewConverter(WithQuiet(true)).ConvertTo(slideFile, filepath.Join(dir, "out.pdf"))
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if requests != 0 || len(res.Images) != 0 || len(res.Warnings) != 3 {
+		t.Errorf("disabled: %d requests, %d images, %d warnings; want 0, 0 and 3", requests, len(res.Images), len(res.Warnings))
+	}
+}
+
+func TestSVGImages(t *testing.T) {
+	dir := t.TempDir()
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 50"><rect x="0" y="0" width="200" height="50" fill="#336699"/></svg>`
+	if err := os.WriteFile(filepath.Join(dir, "diagram.svg"), []byte(svg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bitmap.bmp"), []byte("BM"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	slideFile := filepath.Join(dir, "talk.slide")
+	content := "Talk\n\n* Diagram\n\n.image diagram.svg\n\n* Bitmap\n\n.image bitmap.bmp\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	res, err := conv.ConvertTo(slideFile, filepath.Join(dir, "out.pdf"))
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if len(res.Images) != 1 || filepath.Base(res.Images[0].Path) != "diagram.svg" {
+		t.Errorf("Images = %+v, want diagram.svg", res.Images)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0].Message, `unsupported image format "BMP"`) {
+		t.Errorf("Warnings = %+v, want only the BMP reported", res.Warnings)
+	}
+	if conv.imageDir != "" {
+		t.Errorf("rasterized SVG not removed: %s", conv.imageDir)
+	}
+
+	// The PNG keeps the 4:1 aspect of the drawing at 150 dpi
+	conv = NewConverter()
+	conv.prepareImageFiles()
+	png, err := conv.rasterizeSVG(filepath.Join(dir, "diagram.svg"))
+	if err != nil {
+		t.Fatalf("rasterizeSVG() error = %v", err)
+	}
+	defer os.RemoveAll(conv.imageDir)
+	f, err := os.Open(png)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		t.Fatalf("DecodeConfig() error = %v", err)
+	}
+	if cfg.Width != 313 || cfg.Height != 79 {
+		t.Errorf("rasterized size = %dx%d, want 313x79", cfg.Width, cfg.Height)
+	}
+}
+
+func TestImageSizeHints(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "pic.png"), 400, 300)
+
+	tests := []struct {
+		name string
+		img  present.Image
+		w, h float64 // mm; 0 = check only that it fits
+	}{
+		{"width", present.Image{URL: "pic.png", Width: 200}, 200 * pxToMM, 150 * pxToMM},
+		{"height", present.Image{URL: "pic.png", Height: 150}, 200 * pxToMM, 150 * pxToMM},
+		{"both", present.Image{URL: "pic.png", Width: 300, Height: 100}, 300 * pxToMM, 100 * pxToMM},
+		{"oversized", present.Image{URL: "pic.png", Height: 3000}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(WithQuiet(true))
+			conv.slideDir = dir
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.pdf.SetCompression(false)
+			conv.pdf.AddPage()
+			y := conv.contentTop()
+			conv.renderImage(tt.img, y)
+			var buf bytes.Buffer
+			if err := conv.pdf.Output(&buf); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+
+			m := regexp.MustCompile(`q ([\d.]+) 0 0 ([\d.]+) `).FindStringSubmatch(buf.String())
+			if m == nil {
+				t.Fatal("image not drawn")
+			}
+			w, _ := strconv.ParseFloat(m[1], 64)
+			h, _ := strconv.ParseFloat(m[2], 64)
+			w, h = w*25.4/72, h*25.4/72
+			if tt.w > 0 && (math.Abs(w-tt.w) > 0.01 || math.Abs(h-tt.h) > 0.01) {
+				t.Errorf("image size = %.2fx%.2fmm, want %.2fx%.2fmm", w, h, tt.w, tt.h)
+			}
+			if h > conv.contentBottom()-y+0.01 || w > conv.contentWidth()+0.01 {
+				t.Errorf("image size = %.2fx%.2fmm, larger than the content area", w, h)
+			}
+			if tt.w == 0 && math.Abs(w/h-4.0/3) > 0.01 {
+				t.Errorf("image size = %.2fx%.2fmm, want the 4:3 aspect kept", w, h)
+			}
+		})
+	}
+}
+
+func TestImageCaptions(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "wide.png"), 400, 100)
+	createTestPNG(t, filepath.Join(dir, "tall.png"), 300, 400)
+
+	newConv := func() *Converter {
+		conv := NewConverter(WithQuiet(true))
+		conv.slideDir = dir
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.pdf.AddPage()
+		return conv
+	}
+
+	y := newConv().contentTop()
+	plain := newConv().renderHTMLImage(`<img src="wide.png" alt="">`, y)
+	captioned := newConv().renderHTMLImage(`<img src="wide.png" alt="Sales by quarter">`, y)
+	if captioned <= plain {
+		t.Errorf("y after captioned image = %.1f, want more than %.1f without a caption", captioned, plain)
+	}
+
+	// A .caption after an image that fills the slide still fits below it
+	conv := newConv()
+	conv.renderSlide(present.Section{Title: "Photo", Elem: []present.Elem{
+		present.Image{URL: "tall.png"},
+		present.Caption{Text: "The team in 2024"},
+	}})
+	if len(conv.Warnings()) != 0 {
+		t.Errorf("warnings = %v, want the image and its caption to fit", conv.Warnings())
+	}
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "(The team in 2024)Tj") {
+		t.Error(".caption not drawn")
+	}
+}
+
+func TestConvertStream(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "chart.png"), 400, 100)
+	content := "# Talk\n\n## Chart\n\n![](chart.png)\n"
+
+	conv := NewConverter(WithQuiet(true))
+	var out bytes.Buffer
+	if err := conv.ConvertStream(strings.NewReader(content), &out, dir); err != nil {
+		t.Fatalf("ConvertStream() error = %v", err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("%PDF")) {
+		t.Errorf("output starts with %q, want a PDF", out.Bytes()[:min(8, out.Len())])
+	}
+	if len(conv.Images()) != 1 || len(conv.Warnings()) != 0 {
+		t.Errorf("images %v, warnings %v; want chart.png found in the slide dir", conv.Images(), conv.Warnings())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("slide dir has %d files, want only the image", len(entries))
+	}
+
+	err := conv.ConvertStream(strings.NewReader("# Talk\n\n## Slide\n\n.nosuch x\n"), io.Discard, dir)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || filepath.Base(parseErr.File) != streamInputName {
+		t.Errorf("ConvertStream() error = %v, want a ParseError in %s", err, streamInputName)
+	}
+}
+
+func TestConvertBytes(t *testing.T) {
+	content := []byte("Talk\n18 Feb 2026\n\nJane Doe\n\n* Intro\n\n- one\n- two\n")
+	pdf, err := NewConverter(WithQuiet(true)).ConvertBytes(content, t.TempDir())
+	if err != nil {
+		t.Fatalf("ConvertBytes() error = %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF")) || len(pdf) <= 1024 {
+		t.Errorf("ConvertBytes() = %d bytes starting %q, want a PDF over 1KB", len(pdf), pdf[:min(8, len(pdf))])
+	}
+}
+
+func TestConvertDir(t *testing.T) {
+	in := t.TempDir()
+	out := filepath.Join(t.TempDir(), "pdf")
+	good := "Talk\n\n* Intro\n\n- one\n- two\n"
+	for name, content := range map[string]string{
+		"a.slide":   good,
+		"b.slide":   "Talk\n\n* Slide\n\n.nosuch x\n",
+		"c.slide":   good,
+		"notes.txt": "not a slide",
+	} {
+		if err := os.WriteFile(filepath.Join(in, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := ConvertDir(in, out, WithQuiet(true))
+	if err == nil || !strings.Contains(err.Error(), "b.slide") {
+		t.Errorf("ConvertDir() error = %v, want the failure of b.slide", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("ConvertDir() = %d results, want 3", len(results))
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		r := results[i]
+		if filepath.Base(r.Input) != name+".slide" || r.Output != filepath.Join(out, name+".pdf") {
+			t.Errorf("result %d = %s -> %s, want %s.slide", i, r.Input, r.Output, name)
+		}
+		_, statErr := os.Stat(r.Output)
+		if failed := name == "b"; (r.Err != nil) != failed || (statErr != nil) != failed {
+			t.Errorf("%s: error %v, output %v; want failed = %v", name, r.Err, statErr, failed)
+		}
+	}
+
+	if _, err := ConvertDir(t.TempDir(), out); err == nil {
+		t.Error("ConvertDir() of a directory without slides succeeded")
+	}
+
+	// Each file gets its own debug directory, and its warnings name it
+	var log bytes.Buffer
+	debug := t.TempDir()
+	overflow := "Talk\n\n* Slide\n\n" + strings.Repeat("- item\n", 30)
+	for _, name := range []string{"a.slide", "c.slide"} {
+		if err := os.WriteFile(filepath.Join(in, name), []byte(overflow), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ConvertDir(in, out, WithLogOutput(&log), WithDebugDir(debug))
+	for _, name := range []string{"a", "c"} {
+		if _, err := os.Stat(filepath.Join(debug, name, debugSourceFile)); err != nil {
+			t.Errorf("debug source of %s.slide: %v", name, err)
+		}
+		if want := filepath.Join(in, name+".slide") + ": Warning: slide 2"; !strings.Contains(log.String(), want) {
+			t.Errorf("log has no %q:\n%s", want, log.String())
+		}
+	}
+}
+
+func TestWithMaxCodeLines(t *testing.T) {
+	code := strings.Repeat("x := 1\n", 40)
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{"default", nil, defaultMaxCodeLines},
+		{"custom", []Option{WithMaxCodeLines(8)}, 8},
+		{"content area", []Option{WithMaxCodeLines(0)}, 23},
+		{"larger font", []Option{WithCodeFontSize(16)}, 16},
+		{"continuation", []Option{WithMaxCodeLines(8), WithContinuation(true)}, 23},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewConverter(append(tt.opts, WithQuiet(true))...)
+			if _, err := conv.initPDF(); err != nil {
+				t.Fatalf("initPDF() error = %v", err)
+			}
+			conv.pdf.AddPage()
+
+			const startY = 50.0
+			want := startY + float64(tt.want)*conv.codeLineHeight() + 2*conv.codePadV + codeBlockGap
+			for _, render := range []func(string, float64) float64{func(s string, y float64) float64 {
+				return conv.renderCodePlain(s, 1, y)
+			}, func(s string, y float64) float64 {
+				return conv.renderCodeText(s, "go", y)
+			}} {
+				if endY := render(code, startY); math.Abs(endY-want) > 0.01 {
+					t.Errorf("returned y = %.1f, want %.1f for %d lines", endY, want, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithNotes(t *testing.T) {
+	section := present.Section{
+		Title: "Plan",
+		Elem:  []present.Elem{present.Text{Lines: []string{"Visible text"}}},
+		Notes: []string{"Mention the deadline"},
+	}
+	render := func(opts ...Option) (*Converter, string) {
+		conv := NewConverter(append(opts, WithQuiet(true))...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.renderSlide(section)
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		return conv, buf.String()
+	}
+
+	conv, pdf := render()
+	bottom := conv.contentBottom()
+	if strings.Contains(pdf, "(Mention the deadline)Tj") {
+		t.Error("notes drawn by default")
+	}
+
+	conv, pdf = render(WithNotes("append"))
+	if !strings.Contains(pdf, "(Mention the deadline)Tj") {
+		t.Error(`notes not drawn with WithNotes("append")`)
+	}
+	if conv.pdf.PageNo() != 1 || conv.slideNotes != nil || conv.contentBottom() != bottom {
+		t.Errorf("pages = %d, slideNotes = %v after the slide; want 1 page and the notes cleared", conv.pdf.PageNo(), conv.slideNotes)
+	}
+
+	conv, pdf = render(WithNotes("append"), WithNotes("pages"))
+	if conv.pdf.PageNo() != 1 || strings.Contains(pdf, "(Mention the deadline)Tj") {
+		t.Error(`WithNotes("pages") should replace "append"`)
+	}
+	if !conv.notesPages {
+		t.Error(`WithNotes("pages") did not enable notes pages`)
+	}
+
+	// Long notes are cut to a third of the content area, which shrinks to
+	// keep the slide content above them
+	conv = NewConverter(WithQuiet(true), WithNotes("append"))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.AddPage()
+	conv.slideNotes = conv.layoutSlideNotes(strings.Split(strings.Repeat("note\n", 40), "\n"))
+	if n := len(conv.slideNotes); n == 0 || n >= 40 {
+		t.Errorf("layoutSlideNotes() = %d lines, want the notes truncated", n)
+	}
+	if len(conv.Warnings()) != 1 {
+		t.Errorf("warnings = %v, want the truncation reported", conv.Warnings())
+	}
+	if conv.contentBottom() >= bottom {
+		t.Errorf("contentBottom() = %.1f with notes, want above %.1f", conv.contentBottom(), bottom)
+	}
+}
+
+func TestSubsections(t *testing.T) {
+	flat := "# Talk\n\n## Plan\n\nFirst part.\n\nSecond part.\n"
+	nested := "# Talk\n\n## Plan\n\n### Part one\n\nFirst part.\n\n### Part two\n\nSecond part.\n"
+
+	size := func(content string) int {
+		pdf, err := NewConverter(WithQuiet(true)).ConvertBytes([]byte(content), t.TempDir())
+		if err != nil {
+			t.Fatalf("ConvertBytes() error = %v", err)
+		}
+		return len(pdf)
+	}
+	if flatSize, nestedSize := size(flat), size(nested); nestedSize <= flatSize {
+		t.Errorf("PDF with subsections = %d bytes, want more than %d without", nestedSize, flatSize)
+	}
+
+	// Headings come before their indented content, in order
+	conv := NewConverter(WithQuiet(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.renderSlide(present.Section{Title: "Plan", Elem: []present.Elem{
+		present.Section{Title: "Part one", Elem: []present.Elem{present.Text{Lines: []string{"First"}}}},
+		present.Section{Title: "Part two", Elem: []present.Elem{
+			present.Section{Title: "Detail", Elem: []present.Elem{present.Text{Lines: []string{"Deep"}}}},
+		}},
+	}})
+	if conv.subsectionIndent != 0 {
+		t.Errorf("subsectionIndent = %.1f after the slide, want 0", conv.subsectionIndent)
+	}
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	re := regexp.MustCompile(`BT ([\d.]+) ([\d.]+) Td \((Part one|First|Part two|Detail|Deep) ?\)Tj`)
+	var got []string
+	var xs []float64
+	for _, m := range re.FindAllStringSubmatch(buf.String(), -1) {
+		x, _ := strconv.ParseFloat(m[1], 64)
+		got = append(got, m[3])
+		xs = append(xs, x)
+	}
+	if want := []string{"Part one", "First", "Part two", "Detail", "Deep"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("drawn %v, want %v", got, want)
+	}
+	if !(xs[0] < xs[1] && xs[0] == xs[2] && xs[2] < xs[3] && xs[3] < xs[4]) {
+		t.Errorf("x positions %v, want content indented below each heading", xs)
+	}
+}
+
+func TestRenderHTMLHr(t *testing.T) {
+	conv := NewConverter(WithQuiet(true))
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.pdf.AddPage()
+
+	const startY = 60.0
+	if got := conv.renderHTMLHr(startY); got != startY+ruleGap {
+		t.Errorf("renderHTMLHr() = %.1f, want %.1f", got, startY+ruleGap)
+	}
+
+	// Rules between paragraphs are drawn in order, each moving y down
+	plain := conv.renderHTML(present.HTML{HTML: "<p>Above</p>\n<p>Below</p>"}, startY)
+	ruled := conv.renderHTML(present.HTML{HTML: "<p>Above</p>\n<hr>\n<p>Below</p>\n<hr/>"}, startY)
+	if math.Abs(ruled-plain-2*ruleGap) > 0.01 {
+		t.Errorf("y after two rules = %.1f, want %.1f", ruled, plain+2*ruleGap)
+	}
+
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	x0, x1 := conv.contentX()*conv.pdf.GetConversionRatio(), (conv.contentX()+conv.contentWidth())*conv.pdf.GetConversionRatio()
+	want := fmt.Sprintf("%.2f %.2f m %.2f %.2f l S", x0, (conv.pageHeight-startY)*conv.pdf.GetConversionRatio(), x1, (conv.pageHeight-startY)*conv.pdf.GetConversionRatio())
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("PDF has no rule %q across the content area", want)
+	}
+}
+
+func TestTextFontStyles(t *testing.T) {
+	render := func(opts ...Option) string {
+		conv := NewConverter(opts...)
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.pdf.AddPage()
+		fragments := parseHTMLFormatting(`<strong>bold</strong> <em>slanted</em> <strong><em>both</em></strong> Жирный`)
+		conv.renderFormattedText(fragments, conv.contentX(), 50, conv.contentWidth(), 11)
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		return buf.String()
+	}
+
+	// The cp1251 and the UTF-8 fonts have real variants: nothing is skewed
+	// or drawn twice
+	for _, tt := range []struct {
+		opts  []Option
+		fonts []string
+	}{
+		{nil, []string{"DejaVuSansCondensed-Bold", "DejaVuSansCondensed-Oblique", "DejaVuSansCondensed-BoldOblique"}},
+		{[]Option{WithFontSubsetting(true)}, []string{"utf8helveticaB", "utf8helveticaI", "utf8helveticaBI"}},
+	} {
+		pdf := render(tt.opts...)
+		for _, name := range tt.fonts {
+			if !strings.Contains(pdf, "/BaseFont /"+name+"\n") {
+				t.Errorf("font %s not embedded", name)
+			}
+		}
+		if strings.Count(pdf, "(bold )Tj") > 1 || strings.Contains(pdf, " cm") {
+			t.Error("bold or italic simulated although the font has the variant")
+		}
+	}
+
+	// The variants are embedded only once used
+	conv := NewConverter()
+	if _, err := conv.initPDF(); err != nil {
+		t.Fatalf("initPDF() error = %v", err)
+	}
+	conv.pdf.SetCompression(false)
+	conv.pdf.AddPage()
+	conv.renderFormattedText(parseHTMLFormatting("plain"), conv.contentX(), 50, conv.contentWidth(), 11)
+	var buf bytes.Buffer
+	if err := conv.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "DejaVuSansCondensed") {
+		t.Error("unused bold and italic fonts embedded")
+	}
+
+	// A custom body font has no variants, so the styles are simulated
+	ttf, err := inflateFont(dejavuSansMonoZ)
+	if err != nil {
+		t.Fatalf("inflateFont() error = %v", err)
+	}
+	conv = NewConverter(WithBodyFontTTF(ttf))
+	if conv.realTextStyles() {
+		t.Error("realTextStyles() = true with a custom body font")
+	}
+}
+
+func TestWithBodyFont(t *testing.T) {
+	if got := GetAvailableFonts(); !reflect.DeepEqual(got, []string{"dejavu", "go", "helvetica"}) {
+		t.Errorf("GetAvailableFonts() = %v", got)
+	}
+
+	content := []byte("# Доклад\n\n## Слайд\n\nТекст и **жирный** текст.\n")
+	for _, tt := range []struct {
+		font    string
+		regular string
+		bold    string
+		absent  string
+	}{
+		{"helvetica", "ArialMT", "DejaVuSansCondensed-Bold", "GoRegular"},
+		{"Go", "GoRegular", "Go-Bold", "ArialMT"},
+		{"dejavu", "DejaVuSansCondensed", "DejaVuSansCondensed-Bold", "ArialMT"},
+	} {
+		conv := NewConverter(WithQuiet(true), WithBodyFont(tt.font))
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF(%s) error = %v", tt.font, err)
+		}
+		conv.pdf.SetCompression(false)
+		conv.pdf.AddPage()
+		conv.renderFormattedText(parseHTMLFormatting("Текст <strong>жирный</strong>"), conv.contentX(), 50, conv.contentWidth(), 11)
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output(%s) error = %v", tt.font, err)
+		}
+		pdf := buf.String()
+		for _, name := range []string{tt.regular, tt.bold} {
+			if !strings.Contains(pdf, "/BaseFont /"+name+"\n") {
+				t.Errorf("%s: font %s not embedded", tt.font, name)
+			}
+		}
+		if strings.Contains(pdf, "/BaseFont /"+tt.absent+"\n") {
+			t.Errorf("%s: font %s embedded", tt.font, tt.absent)
+		}
+
+		if _, err := NewConverter(WithQuiet(true), WithBodyFont(tt.font)).ConvertBytes(content, t.TempDir()); err != nil {
+			t.Errorf("ConvertBytes(%s) error = %v", tt.font, err)
+		}
+	}
+
+	_, err := NewConverter(WithBodyFont("comic")).ConvertBytes(content, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), `unknown body font "comic"`) {
+		t.Errorf("ConvertBytes() error = %v, want unknown body font", err)
+	}
+}
+
+func TestEmptyTitles(t *testing.T) {
+	// drawnY renders into a fresh PDF and returns the PDF-space Y of text
+	drawnY := func(render func(c *Converter), text string) float64 {
+		t.Helper()
+		conv := NewConverter(WithQuiet(true))
+		if _, err := conv.initPDF(); err != nil {
+			t.Fatalf("initPDF() error = %v", err)
+		}
+		conv.pdf.SetCompression(false)
+		render(conv)
+		var buf bytes.Buffer
+		if err := conv.pdf.Output(&buf); err != nil {
+			t.Fatalf("Output() error = %v", err)
+		}
+		m := regexp.MustCompile(`BT [\d.]+ ([\d.]+) Td \(` + regexp.QuoteMeta(text) + ` ?\)Tj`).FindStringSubmatch(buf.String())
+		if m == nil {
+			t.Fatalf("%q not drawn", text)
+		}
+		y, _ := strconv.ParseFloat(m[1], 64)
+		return y
+	}
+
+	// An untitled slide starts its content near the top
+	slide := func(title string) func(c *Converter) {
+		return func(c *Converter) {
+			c.renderSlide(present.Section{Title: title, Elem: []present.Elem{present.Text{Lines: []string{"Body"}}}})
+			if want := title == ""; c.untitled != want {
+				t.Errorf("untitled = %v for title %q, want %v", c.untitled, title, want)
+			}
+		}
+	}
+	titled, untitled := drawnY(slide("Plan"), "Body"), drawnY(slide(""), "Body")
+	if diff := (untitled - titled) / 72 * 25.4; math.Abs(diff-(contentTop-untitledTop)) > 0.5 {
+		t.Errorf("untitled content is %.1fmm higher, want %.1fmm", diff, contentTop-untitledTop)
+	}
+
+	// Without a title, the subtitle moves up on the title slide
+	cover := func(title string) func(c *Converter) {
+		return func(c *Converter) { c.renderTitleSlide(&present.Doc{Title: title, Subtitle: "Subtitle"}) }
+	}
+	if withTitle, without := drawnY(cover("Talk"), "Subtitle"), drawnY(cover(""), "Subtitle"); without <= withTitle {
+		t.Errorf("subtitle Y = %.1f without a title, want above %.1f", without, withTitle)
+	}
+
+	// A deck with an empty section title converts
+	if _, err := NewConverter(WithQuiet(true)).ConvertBytes([]byte("# Talk\n\n## \n\nNo title here.\n"), t.TempDir()); err != nil {
+		t.Errorf("ConvertBytes() error = %v", err)
+	}
+}
+
+func TestExportThumbnail(t *testing.T) {
+	dir := t.TempDir()
+	slideFile := filepath.Join(dir, "talk.slide")
+	content := "Thumbnail Talk\nA subtitle\n2 Jan 2024\n\nJane Doe\n\n* Slide\n\nBody\n"
+	if err := os.WriteFile(slideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		opts  []Option
+		width int
+	}{
+		{"default", nil, 320},
+		{"dark dejavu", []Option{WithTheme("dark"), WithBodyFont("dejavu")}, 200},
+		{"widescreen", []Option{WithPageSize("16:9")}, 480},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".png")
+			conv := NewConverter(append([]Option{WithQuiet(true)}, tt.opts...)...)
+			if err := conv.ExportThumbnail(slideFile, out, tt.width); err != nil {
+				t.Fatalf("ExportThumbnail() error = %v", err)
+			}
+			f, err := os.Open(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			img, err := png.Decode(f)
+			if err != nil {
+				t.Fatalf("thumbnail is not a PNG: %v", err)
+			}
+
+			b := img.Bounds()
+			wantH := int(math.Round(float64(tt.width) * conv.pageHeight / conv.pageWidth))
+			if b.Dx() != tt.width || b.Dy() != wantH {
+				t.Errorf("thumbnail size = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.width, wantH)
+			}
+
+			// The title is drawn in the title color over the background
+			bg := color.RGBAModel.Convert(img.At(b.Dx()/2, 2)).(color.RGBA)
+			text := 0
+			for y := b.Dy() / 3; y < b.Dy()/2; y++ {
+				for x := range b.Dx() {
+					if color.RGBAModel.Convert(img.At(x, y)).(color.RGBA) != bg {
+						text++
+					}
+				}
+			}
+			if text == 0 {
+				t.Error("no title text drawn on the thumbnail")
+			}
+		})
+	}
+
+	conv := NewConverter(WithQuiet(true))
+	if err := conv.ExportThumbnail(slideFile, filepath.Join(dir, "bad.png"), 0); err == nil {
+		t.Error("ExportThumbnail() with zero width: want error")
+	}
+	if err := conv.ExportThumbnail(filepath.Join(dir, "missing.slide"), filepath.Join(dir, "bad.png"), 100); err == nil {
+		t.Error("ExportThumbnail() of a missing file: want error")
+	}
+
+	// A title word wider than the slide is split instead of running off
+	// the edges
+	longFile := filepath.Join(dir, "long.slide")
+	if err := os.WriteFile(longFile, []byte(strings.Repeat("Supercalifragilistic", 4)+"\n\nJane Doe\n\n* Slide\n\nBody\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	longPNG := filepath.Join(dir, "long.png")
+	if err := conv.ExportThumbnail(longFile, longPNG, 200); err != nil {
+		t.Fatalf("ExportThumbnail() error = %v", err)
+	}
+	f, err := os.Open(longPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("thumbnail is not a PNG: %v", err)
+	}
+	b := img.Bounds()
+	for y := range b.Dy() {
+		for _, x := range []int{0, b.Dx() - 1} {
+			if img.At(x, y) != img.At(x, 0) {
+				t.Fatalf("title drawn at the edge of the thumbnail (%d, %d)", x, y)
+			}
+		}
+	}
+}
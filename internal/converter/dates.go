@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"strings"
+	"time"
+)
+
+// defaultDateLayout is the layout of deck dates without WithDateFormat
+const defaultDateLayout = "January 2, 2006"
+
+// monthNames are the localized month names for WithLanguage: full names as
+// used after a day number, full names on their own ("January 2006") and
+// abbreviations
+type monthNames struct {
+	full, standalone, short [12]string
+}
+
+// dateLocales maps a WithLanguage code to its month names and the date layout
+// used when no WithDateFormat is given
+var dateLocales = map[string]struct {
+	months monthNames
+	layout string
+}{
+	"en": {
+		months: monthNames{
+			full:       [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+			standalone: [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+			short:      [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		},
+		layout: defaultDateLayout,
+	},
+	"ru": {
+		months: monthNames{
+			full:       [12]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+			standalone: [12]string{"январь", "февраль", "март", "апрель", "май", "июнь", "июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь"},
+			short:      [12]string{"янв", "фев", "мар", "апр", "мая", "июн", "июл", "авг", "сен", "окт", "ноя", "дек"},
+		},
+		layout: "2 January 2006",
+	},
+}
+
+// Placeholders standing in for month names while a layout is formatted; the
+// time package copies them through as literal text
+const (
+	monthFullMark  = "\x00"
+	monthShortMark = "\x01"
+)
+
+// formatDate formats a deck date for display with the WithDateFormat layout,
+// spelling month names in the WithLanguage language. A WithDateFormat locale
+// name stands for the long form and month names of that language.
+func (c *Converter) formatDate(t time.Time) string {
+	locale, ok := dateLocales[c.language]
+	if !ok {
+		locale = dateLocales["en"]
+	}
+	layout := c.dateFormat
+	if named, ok := dateLocales[strings.ToLower(layout)]; ok {
+		locale, layout = named, ""
+	}
+	if layout == "" {
+		layout = locale.layout
+	}
+
+	layout = strings.ReplaceAll(layout, "January", monthFullMark)
+	layout = strings.ReplaceAll(layout, "Jan", monthShortMark)
+	full := locale.months.standalone
+	if layoutHasDay(layout) {
+		full = locale.months.full
+	}
+	month := t.Month() - 1
+	return strings.NewReplacer(
+		monthFullMark, full[month],
+		monthShortMark, locale.months.short[month],
+	).Replace(t.Format(layout))
+}
+
+// layoutHasDay reports whether a date layout prints the day of the month,
+// which decides the grammatical case of month names in some languages
+func layoutHasDay(layout string) bool {
+	layout = strings.ReplaceAll(layout, "2006", "")
+	return strings.Contains(layout, "2")
+}
+
+// resolveLanguage warns about a WithLanguage code without month names and
+// falls back to English
+func (c *Converter) resolveLanguage() {
+	if c.language == "" {
+		c.language = "en"
+	}
+	c.language = strings.ToLower(c.language)
+	if _, ok := dateLocales[c.language]; !ok {
+		c.warnf("unknown language %q, dates use English month names", c.language)
+		c.language = "en"
+	}
+}
@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/present"
+)
+
+// debugSourceFile is the WithDebugDir file holding the preprocessed source
+const debugSourceFile = "preprocessed.slide"
+
+// writeDebugSource writes the slide source as handed to the present parser
+// into the WithDebugDir directory
+func (c *Converter) writeDebugSource(content []byte) error {
+	if c.debugDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.debugDir, 0755); err != nil {
+		return fmt.Errorf("failed to create debug dir: %w", err)
+	}
+	return c.writeDebugFile(debugSourceFile, content)
+}
+
+// writeDebugSections writes one file per section, slide-NN.txt numbered like
+// the slides, listing the elements the present parser produced. HTML
+// elements are dumped as is, since most rendering works on that HTML.
+func (c *Converter) writeDebugSections(doc *present.Doc) error {
+	if c.debugDir == "" {
+		return nil
+	}
+	for i, section := range doc.Sections {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# %s\n", section.Title)
+		for j, elem := range section.Elem {
+			fmt.Fprintf(&b, "\n--- #%d %s ---\n", j, elem.TemplateName())
+			switch e := elem.(type) {
+			case present.HTML:
+				b.WriteString(string(e.HTML))
+			case present.Text:
+				b.WriteString(strings.Join(e.Lines, "\n"))
+			case present.List:
+				b.WriteString(strings.Join(e.Bullet, "\n"))
+			case present.Code:
+				b.WriteString(e.Cmd)
+			case codeWithLanguage:
+				b.WriteString(e.Cmd + " (" + e.Language + ")")
+			default:
+				fmt.Fprintf(&b, "%+v", e)
+			}
+			b.WriteString("\n")
+		}
+		name := fmt.Sprintf("slide-%02d.txt", i+2)
+		if err := c.writeDebugFile(name, []byte(b.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDebugFile writes a file into the WithDebugDir directory
+func (c *Converter) writeDebugFile(name string, data []byte) error {
+	if err := os.WriteFile(filepath.Join(c.debugDir, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write debug file: %w", err)
+	}
+	return nil
+}
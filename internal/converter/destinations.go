@@ -0,0 +1,198 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/present"
+)
+
+// registerDestinations chooses the named destination of every slide:
+// "slide-N" for each slide (N is the slide number, like for registerAnchors),
+// plus the section's {#anchor} or, without one, its slugified title. Names
+// already taken get a "-2", "-3", ... suffix.
+func (c *Converter) registerDestinations(doc *present.Doc) {
+	c.destNames = make(map[int][]string)
+	c.destPages = make(map[string]int)
+	taken := make(map[string]bool)
+
+	add := func(name string, slide int) {
+		if name == "" {
+			return
+		}
+		unique := name
+		for n := 2; taken[unique]; n++ {
+			unique = fmt.Sprintf("%s-%d", name, n)
+		}
+		taken[unique] = true
+		c.destNames[slide] = append(c.destNames[slide], unique)
+	}
+
+	add("slide-1", 1)
+	for slide := 2; slide < len(doc.Sections)+2+c.extraSlides(); slide++ {
+		add(fmt.Sprintf("slide-%d", slide), slide)
+	}
+	for i, section := range doc.Sections {
+		name := section.ID
+		if name == "" {
+			name = slugify(section.Title)
+		}
+		add(name, i+2)
+	}
+}
+
+// placeDestinations records the page of the current slide's destinations
+func (c *Converter) placeDestinations() {
+	for _, name := range c.destNames[c.currentSlideNumber] {
+		c.destPages[name] = c.pdf.PageNo()
+	}
+}
+
+// slugify turns a title into a destination name: lower case letters and
+// digits with runs of anything else replaced by "-"
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+var (
+	// pdfStartXrefRe matches the offset of the last cross-reference table
+	pdfStartXrefRe = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+
+	// pdfKidsRe matches the page list of the page tree root
+	pdfKidsRe = regexp.MustCompile(`/Kids \[([^\]]*)\]`)
+
+	// pdfRefRe matches an indirect object reference
+	pdfRefRe = regexp.MustCompile(`(\d+) 0 R`)
+)
+
+// addNamedDests appends an incremental update to a PDF written by gofpdf,
+// which has no API for named destinations: the catalog is rewritten with a
+// /Dests dictionary mapping each name to its page (1-based), so viewers open
+// file.pdf#nameddest=NAME at that page.
+func addNamedDests(pdf []byte, dests map[string]int) ([]byte, error) {
+	if len(dests) == 0 {
+		return pdf, nil
+	}
+
+	m := pdfStartXrefRe.FindSubmatch(pdf)
+	if m == nil {
+		return nil, errors.New("no startxref")
+	}
+	prevXref := string(m[1])
+
+	trailerAt := bytes.LastIndex(pdf, []byte("trailer"))
+	if trailerAt < 0 {
+		return nil, errors.New("no trailer")
+	}
+	trailer := pdf[trailerAt:]
+	size, err := trailerInt(trailer, `/Size (\d+)`)
+	if err != nil {
+		return nil, err
+	}
+	root, err := trailerInt(trailer, `/Root (\d+) 0 R`)
+	if err != nil {
+		return nil, err
+	}
+	info, err := trailerInt(trailer, `/Info (\d+) 0 R`)
+	if err != nil {
+		return nil, err
+	}
+
+	// The catalog dictionary, to be repeated with /Dests added
+	start := bytes.Index(pdf, []byte(fmt.Sprintf("\n%d 0 obj\n", root)))
+	if start < 0 {
+		return nil, errors.New("no catalog object")
+	}
+	start += len(fmt.Sprintf("\n%d 0 obj\n", root))
+	end := bytes.Index(pdf[start:], []byte("endobj"))
+	if end < 0 {
+		return nil, errors.New("unterminated catalog object")
+	}
+	catalog := strings.TrimSpace(string(pdf[start : start+end]))
+	if !strings.HasSuffix(catalog, ">>") {
+		return nil, errors.New("catalog is not a dictionary")
+	}
+
+	kids := pdfKidsRe.FindSubmatch(pdf)
+	if kids == nil {
+		return nil, errors.New("no page tree")
+	}
+	var pages []string
+	for _, ref := range pdfRefRe.FindAllSubmatch(kids[1], -1) {
+		pages = append(pages, string(ref[1]))
+	}
+
+	names := make([]string, 0, len(dests))
+	for name := range dests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out bytes.Buffer
+	out.Write(pdf)
+	if !bytes.HasSuffix(pdf, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+
+	destsObj := size
+	catalogAt := out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n%s\n/Dests %d 0 R\n>>\nendobj\n", root, strings.TrimSuffix(catalog, ">>"), destsObj)
+	destsAt := out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<<\n", destsObj)
+	for _, name := range names {
+		page := dests[name]
+		if page < 1 || page > len(pages) {
+			continue
+		}
+		fmt.Fprintf(&out, "/%s [%s 0 R /Fit]\n", pdfName(name), pages[page-1])
+	}
+	out.WriteString(">>\nendobj\n")
+
+	xrefAt := out.Len()
+	fmt.Fprintf(&out, "xref\n0 1\n0000000000 65535 f \n%d 1\n%010d 00000 n \n%d 1\n%010d 00000 n \n", root, catalogAt, destsObj, destsAt)
+	fmt.Fprintf(&out, "trailer\n<<\n/Size %d\n/Root %d 0 R\n/Info %d 0 R\n/Prev %s\n>>\nstartxref\n%d\n%%%%EOF\n", destsObj+1, root, info, prevXref, xrefAt)
+	return out.Bytes(), nil
+}
+
+// trailerInt extracts the number matched by pattern from a PDF trailer
+func trailerInt(trailer []byte, pattern string) (int, error) {
+	m := regexp.MustCompile(pattern).FindSubmatch(trailer)
+	if m == nil {
+		return 0, fmt.Errorf("trailer has no %s", strings.Fields(pattern)[0])
+	}
+	return strconv.Atoi(string(m[1]))
+}
+
+// pdfName encodes s as the body of a PDF name object, escaping delimiters,
+// whitespace and non-ASCII bytes as #XX
+func pdfName(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch < 33 || ch > 126 || strings.IndexByte("()<>[]{}/%#", ch) >= 0 {
+			fmt.Fprintf(&b, "#%02X", ch)
+		} else {
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
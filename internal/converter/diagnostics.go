@@ -0,0 +1,125 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/present"
+)
+
+// Warning is a diagnostic produced during conversion
+type Warning struct {
+	Slide   int              // 1-based slide number (1 is the title slide, 0 if not slide-specific)
+	Title   string           // Title of the slide
+	Message string           // Human-readable description
+	Dropped []DroppedElement // Elements cut off by slide overflow, if any
+}
+
+// DroppedElement describes a slide element that was not rendered because the
+// slide ran out of space
+type DroppedElement struct {
+	Index int    // Position in the section's element list
+	Type  string // Element kind: text, list, code, html, image, link, ...
+}
+
+// String formats the element as "type #index"
+func (d DroppedElement) String() string {
+	return fmt.Sprintf("%s #%d", d.Type, d.Index)
+}
+
+// String formats the warning the way it is printed to stderr
+func (w Warning) String() string {
+	if w.Slide == 0 {
+		return w.Message
+	}
+	return fmt.Sprintf("slide %d %q: %s", w.Slide, w.Title, w.Message)
+}
+
+// ImageInfo describes an image placed in the PDF
+type ImageInfo struct {
+	Slide int    // 1-based slide number
+	Title string // Title of the slide
+	Path  string // Resolved image file path
+	Alt   string // Alternative text from Markdown ![alt](src), "" if none
+}
+
+// Images returns the images placed by the last conversion with their
+// alternative text. gofpdf cannot write tagged PDF, so alt text is not
+// embedded as /Alt structure; this is the place to audit it instead.
+func (c *Converter) Images() []ImageInfo {
+	return c.images
+}
+
+// Warnings returns the diagnostics collected by the last conversion
+func (c *Converter) Warnings() []Warning {
+	return c.warnings
+}
+
+// warnf records a warning for the current slide and prints it unless quiet
+func (c *Converter) warnf(format string, args ...any) {
+	c.addWarning(Warning{Message: fmt.Sprintf(format, args...)})
+}
+
+// addWarning fills in the current slide, records and prints a warning
+func (c *Converter) addWarning(w Warning) {
+	w.Slide = c.currentSlideNumber
+	w.Title = c.currentSlideTitle
+	c.warnings = append(c.warnings, w)
+	if !c.quiet {
+		fmt.Fprintf(c.logOutput(), "Warning: %s\n", w)
+	}
+}
+
+// debugf prints a layout message for the current slide in verbose mode
+func (c *Converter) debugf(format string, args ...any) {
+	if !c.verbose || c.quiet {
+		return
+	}
+	w := Warning{Slide: c.currentSlideNumber, Title: c.currentSlideTitle, Message: fmt.Sprintf(format, args...)}
+	fmt.Fprintf(c.logOutput(), "Debug: %s\n", w)
+}
+
+// logOutput returns where diagnostics are printed
+func (c *Converter) logOutput() io.Writer {
+	if c.logWriter != nil {
+		return c.logWriter
+	}
+	return os.Stderr
+}
+
+// droppedElements describes the elements that were not rendered
+func droppedElements(elems []present.Elem, from int) []DroppedElement {
+	var dropped []DroppedElement
+	for i := from; i < len(elems); i++ {
+		dropped = append(dropped, DroppedElement{Index: i, Type: elems[i].TemplateName()})
+	}
+	return dropped
+}
+
+// formatDropped joins dropped elements into a readable list
+func formatDropped(dropped []DroppedElement) string {
+	parts := make([]string, len(dropped))
+	for i, d := range dropped {
+		parts[i] = d.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// checkOverflow enforces WithMaxSlideOverflow
+func (c *Converter) checkOverflow() error {
+	if c.maxOverflow < 0 {
+		return nil
+	}
+	var failed []string
+	for _, w := range c.warnings {
+		if len(w.Dropped) > c.maxOverflow {
+			failed = append(failed, fmt.Sprintf("slide %d %q: %s", w.Slide, w.Title, formatDropped(w.Dropped)))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("slide overflow exceeds limit of %d dropped elements:\n  %s", c.maxOverflow, strings.Join(failed, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// FileResult is the outcome of converting one file with ConvertDir
+type FileResult struct {
+	Input  string  // Slide file
+	Output string  // PDF file
+	Result *Result // Summary of the conversion (nil if it failed)
+	Err    error   // Why the conversion failed (nil if it succeeded)
+}
+
+// ConvertDir converts every .slide file in inDir to a PDF of the same name in
+// outDir, which is created if needed. Files are converted in parallel, up to
+// GOMAXPROCS at a time, each by its own Converter configured with opts. The
+// diagnostics of a file are printed once it is converted, each line prefixed
+// with its path; writes to a WithLogOutput writer are serialized, so it need
+// not be safe for concurrent use. WithDebugDir files go to a subdirectory
+// per file, named like its PDF. A failed file does not stop the others: the
+// results list every file in name order, and the error joins the failures.
+func ConvertDir(inDir, outDir string, opts ...Option) ([]FileResult, error) {
+	inputs, err := filepath.Glob(filepath.Join(inDir, "*.slide"))
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no .slide files in %s", inDir)
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	results := make([]FileResult, len(inputs))
+	for i, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+		results[i] = FileResult{Input: input, Output: filepath.Join(outDir, name+".pdf")}
+	}
+
+	// Converters share nothing but read-only tables, and each writes its
+	// fonts into its own temp directory, so they can run side by side
+	jobs := make(chan *FileResult)
+	var wg sync.WaitGroup
+	var logMu sync.Mutex
+	for range min(runtime.GOMAXPROCS(0), len(results)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				conv := NewConverter(opts...)
+				if conv.debugDir != "" {
+					name := strings.TrimSuffix(filepath.Base(r.Output), ".pdf")
+					conv.debugDir = filepath.Join(conv.debugDir, name)
+				}
+				out := conv.logOutput()
+				var log bytes.Buffer
+				conv.logWriter = &log
+				r.Result, r.Err = conv.ConvertTo(r.Input, r.Output)
+
+				logMu.Lock()
+				writePrefixed(out, r.Input, &log)
+				logMu.Unlock()
+			}
+		}()
+	}
+	for i := range results {
+		jobs <- &results[i]
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Input, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// writePrefixed copies the lines of log to w, each prefixed with "path: "
+func writePrefixed(w io.Writer, path string, log io.Reader) {
+	s := bufio.NewScanner(log)
+	for s.Scan() {
+		fmt.Fprintf(w, "%s: %s\n", path, s.Text())
+	}
+}
@@ -0,0 +1,60 @@
+package converter
+
+import _ "embed"
+
+// Font Awesome 4.7 (SIL Open Font License) provides monochrome pictograms for
+// the emoji outside the Basic Multilingual Plane, which gofpdf cannot encode:
+// each emoji is drawn as the icon at a BMP code point listed in emojiGlyphs.
+// Like the symbol font, only the glyphs used are embedded.
+//
+//go:embed font/fontawesome.z
+var fontAwesomeZ []byte
+
+// emojiFontFamily is the family name of the emoji fallback font
+const emojiFontFamily = "Emoji"
+
+// emojiGlyphs maps emoji to the Font Awesome icon drawn for them
+var emojiGlyphs = map[rune]rune{
+	// Faces and people
+	'😀': '\uf118', '😁': '\uf118', '😃': '\uf118', '😄': '\uf118', '😉': '\uf118', '😊': '\uf118', '🙂': '\uf118',
+	'😐': '\uf11a', '😑': '\uf11a',
+	'😞': '\uf119', '😢': '\uf119', '🙁': '\uf119',
+	'👤': '\uf007', '👥': '\uf0c0', '👀': '\uf06e',
+	'👍': '\uf164', '👎': '\uf165',
+	'👉': '\uf0a4', '👈': '\uf0a5', '👆': '\uf0a6', '👇': '\uf0a7',
+
+	// Hearts and stars
+	'💙': '\uf004', '💚': '\uf004', '💛': '\uf004', '💜': '\uf004', '🖤': '\uf004',
+	'🌟': '\uf005',
+
+	// Objects
+	'🚀': '\uf135', '💡': '\uf0eb', '🔥': '\uf06d', '🐛': '\uf188', '🐞': '\uf188',
+	'🔧': '\uf0ad', '🛠': '\uf0ad', '🧪': '\uf0c3', '🧩': '\uf12e', '📦': '\uf1b2',
+	'🏆': '\uf091', '🎯': '\uf140', '🎓': '\uf19d', '🎁': '\uf06b', '🎨': '\uf1fc',
+	'🎮': '\uf11b', '🎵': '\uf001', '📷': '\uf030', '💰': '\uf0d6', '🛒': '\uf07a',
+	'🔑': '\uf084', '🔒': '\uf023', '🔓': '\uf09c', '🛡': '\uf132', '🔔': '\uf0f3',
+	'🔗': '\uf0c1', '🏷': '\uf02b', '🔖': '\uf02e', '📌': '\uf08d', '📍': '\uf041',
+	'🏁': '\uf11e', '🚩': '\uf024', '🚫': '\uf05e', '🗑': '\uf1f8', '🔋': '\uf240',
+	'🔍': '\uf002', '🔎': '\uf002', '🔄': '\uf021', '🔁': '\uf01e',
+	'🔊': '\uf028', '🔇': '\uf026', '📣': '\uf0a1', '📢': '\uf0a1',
+
+	// Office and communication
+	'📝': '\uf040', '📁': '\uf07b', '📂': '\uf07c', '📄': '\uf0f6', '📚': '\uf02d', '📖': '\uf02d',
+	'📅': '\uf073', '📆': '\uf073', '📊': '\uf080', '📈': '\uf201', '💾': '\uf0c7', '🖨': '\uf02f',
+	'📤': '\uf093', '📥': '\uf019', '📧': '\uf0e0', '📨': '\uf0e0', '📞': '\uf095',
+	'💬': '\uf075', '🗨': '\uf0e5',
+	'💻': '\uf109', '🖥': '\uf108', '📱': '\uf10b',
+
+	// Places, nature and travel
+	'🌍': '\uf0ac', '🌎': '\uf0ac', '🌏': '\uf0ac', '🌐': '\uf0ac', '🏠': '\uf015',
+	'🌙': '\uf186', '🌳': '\uf1bb', '🍺': '\uf0fc', '🚗': '\uf1b9',
+	'🔴': '\uf111', '🔵': '\uf111',
+	'🕐': '\uf017', '🕑': '\uf017', '🕒': '\uf017', '🕓': '\uf017', '🕔': '\uf017', '🕕': '\uf017',
+	'🕖': '\uf017', '🕗': '\uf017', '🕘': '\uf017', '🕙': '\uf017', '🕚': '\uf017', '🕛': '\uf017',
+}
+
+// isEmojiModifier reports whether r only changes the look of the emoji before
+// it (a skin tone), which the monochrome icons cannot show
+func isEmojiModifier(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
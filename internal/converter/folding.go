@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// foldText returns the line replacing a folded region of code: "..." as a
+// comment in the syntax of the FOLD marker, "//" or "#"
+func foldText(comment string) string {
+	return comment + " ..."
+}
+
+// isFoldText reports whether line is a foldText line
+func isFoldText(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == foldText("//") || line == foldText("#")
+}
+
+var (
+	// foldStartRe matches a line opening a folded region: // FOLD or # FOLD
+	foldStartRe = regexp.MustCompile(`^(\s*)(//|#)\s*FOLD\s*$`)
+
+	// foldEndRe matches the line closing a folded region
+	foldEndRe = regexp.MustCompile(`^\s*(//|#)\s*ENDFOLD\s*$`)
+)
+
+// foldCode collapses every region between a FOLD and an ENDFOLD marker line
+// into a single foldText line at the indentation of the FOLD marker, commented
+// out like the marker, when WithCodeFolding is on. A region left open runs to
+// the end of the code.
+func (c *Converter) foldCode(code string) string {
+	if !c.codeFolding || !strings.Contains(code, "FOLD") {
+		return code
+	}
+
+	var out []string
+	folding := false
+	for _, line := range strings.Split(code, "\n") {
+		switch {
+		case folding:
+			folding = !foldEndRe.MatchString(line)
+		case foldStartRe.MatchString(line):
+			m := foldStartRe.FindStringSubmatch(line)
+			out = append(out, m[1]+foldText(m[2]))
+			folding = true
+		default:
+			out = append(out, line)
+		}
+	}
+	if folding {
+		c.warnf("code fold not closed by ENDFOLD, folding to the end of the block")
+	}
+	return strings.Join(out, "\n")
+}
+
+// isFoldLine reports whether a highlighted line is the foldText left by
+// foldCode, which is drawn dimmed instead of highlighted
+func (c *Converter) isFoldLine(tokens []Token) bool {
+	return c.codeFolding && isFoldText(tokensText(tokens))
+}
+
+// renderFoldLine draws a foldText line in the dimmed line-number color
+func (c *Converter) renderFoldLine(tokens []Token, x, y, lineHeight float64) {
+	c.pdf.SetTextColor(c.theme.CodeLineNumber.R, c.theme.CodeLineNumber.G, c.theme.CodeLineNumber.B)
+	c.setCodeFont("", c.codeFontSize)
+	c.pdf.SetXY(x, y)
+	c.pdf.Cell(0, lineHeight, c.translator(tokensText(tokens)))
+}
+
+// tokensText joins the text of highlighted tokens
+func tokensText(tokens []Token) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString(token.Value)
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fontFS holds the cp1251 fonts: each is a gofpdf NAME.json definition and
+// the compressed TrueType data NAME.z it refers to
+//
+//go:embed font/cp1251.map font/*_1251.json font/*_1251.z
+var fontFS embed.FS
+
+// defaultBodyFont is the text font family used without WithBodyFont
+const defaultBodyFont = "helvetica"
+
+// textFonts are the WithBodyFont families: the base name of the font files
+// of each style ("" regular, "B" bold, "I" italic and "BI" bold italic).
+// Arial has no free Cyrillic bold and italic variants, so helvetica borrows
+// those of DejaVu Sans Condensed.
+var textFonts = map[string]map[string]string{
+	"helvetica": {"": "helvetica_1251", "B": "dejavusans_bold_1251", "I": "dejavusans_italic_1251", "BI": "dejavusans_bolditalic_1251"},
+	"dejavu":    {"": "dejavusans_1251", "B": "dejavusans_bold_1251", "I": "dejavusans_italic_1251", "BI": "dejavusans_bolditalic_1251"},
+	"go":        {"": "go_1251", "B": "go_bold_1251", "I": "go_italic_1251", "BI": "go_bolditalic_1251"},
+}
+
+// GetAvailableFonts returns the names of the embedded text font families
+func GetAvailableFonts() []string {
+	names := make([]string, 0, len(textFonts))
+	for name := range textFonts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeEmbeddedFont writes the cp1251 definition of the embedded font NAME
+// and its data into the font directory dir, for gofpdf to read
+func writeEmbeddedFont(dir, name string) error {
+	for _, file := range []string{name + ".json", name + ".z"} {
+		data, err := fontFS.ReadFile("font/" + file)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, file), data, 0644)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write font file %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// addEmbeddedFont registers the embedded font NAME as family and style, from
+// the cp1251 files writeEmbeddedFont wrote or, for UTF-8 fonts, the original
+// TrueType data: gofpdf then embeds only the glyphs actually used, and text
+// needs no translation.
+func (c *Converter) addEmbeddedFont(family, style, name string) error {
+	if !c.utf8Fonts() {
+		c.pdf.AddFont(family, style, name+".json")
+		return nil
+	}
+	z, err := fontFS.ReadFile("font/" + name + ".z")
+	if err != nil {
+		return fmt.Errorf("failed to load font %s: %w", name, err)
+	}
+	ttf, err := inflateFont(z)
+	if err != nil {
+		return fmt.Errorf("failed to load font %s: %w", name, err)
+	}
+	c.pdf.AddUTF8FontFromBytes(family, style, ttf)
+	return nil
+}
+
+// addTextStyle registers the bold or italic variant of the text font the
+// first time it is used: gofpdf embeds every font registered, and these are
+// large
+func (c *Converter) addTextStyle(style string) {
+	name, ok := textFonts[c.bodyFont][style]
+	if !ok || style == "" || c.pdf.GetFontDesc(c.bodyFont, style).Ascent != 0 {
+		return
+	}
+	if err := c.addEmbeddedFont(c.bodyFont, style, name); err != nil {
+		c.pdf.SetError(err)
+	}
+}
+
+// checkBodyFont reports an unknown WithBodyFont family
+func (c *Converter) checkBodyFont() error {
+	if _, ok := textFonts[c.bodyFont]; !ok {
+		return fmt.Errorf("unknown body font %q (available: %s)", c.bodyFont, strings.Join(GetAvailableFonts(), ", "))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"maps"
+	"strings"
+
+	"golang.org/x/tools/present"
+)
+
+const (
+	minGlobalFontScale  = 0.6     // smallest WithAutoGlobalFontScale scale (about 11pt body text)
+	globalFontScaleStep = 0.05    // scale decrement while searching for one that fits
+	minAutoFitScale     = 2.0 / 3 // smallest WithAutoFit scale (12pt list text)
+)
+
+// setFontScale scales body text and code of every slide; codeFontSize is the
+// unscaled code font size
+func (c *Converter) setFontScale(scale, codeFontSize float64) {
+	c.fontScale = scale
+	c.textScale = scale
+	c.codeFontSize = codeFontSize * scale
+}
+
+// globalFontScale returns the WithAutoGlobalFontScale scale for doc: 1 if
+// every slide fits, otherwise the largest one at which the fullest slide
+// fits (but at least minGlobalFontScale). The slides are laid out in a
+// scratch PDF; the first guess shrinks by the fullest slide's overflow
+// ratio, and smaller scales are tried until everything fits.
+func (c *Converter) globalFontScale(doc *present.Doc, codeFontSize float64) (float64, error) {
+	pdf, translator := c.pdf, c.translator
+	images, warnings, badAnchors := c.images, c.warnings, maps.Clone(c.badAnchors)
+	quiet, verbose, slide, title := c.quiet, c.verbose, c.currentSlideNumber, c.currentSlideTitle
+	defer func() {
+		c.pdf, c.translator = pdf, translator
+		c.images, c.warnings, c.badAnchors = images, warnings, badAnchors
+		c.quiet, c.verbose, c.currentSlideNumber, c.currentSlideTitle = quiet, verbose, slide, title
+		c.titleOverflow, c.untitled, c.footnotes = 0, false, nil
+	}()
+	c.quiet, c.verbose = true, false
+
+	cleanup, err := c.initPDF()
+	if err != nil {
+		return 1, err
+	}
+	defer cleanup()
+
+	c.setFontScale(1, codeFontSize)
+	worst := c.fullestSlide(doc)
+	if worst <= 1 {
+		return 1, nil
+	}
+
+	scale := max(1/worst, minGlobalFontScale)
+	for scale > minGlobalFontScale {
+		c.setFontScale(scale, codeFontSize)
+		if c.fullestSlide(doc) <= 1 {
+			break
+		}
+		scale = max(scale-globalFontScaleStep, minGlobalFontScale)
+	}
+	return scale, nil
+}
+
+// fullestSlide lays out the content of every section on scratch pages and
+// returns the largest ratio of content height to the available height
+// (above 1 for a slide that overflows)
+func (c *Converter) fullestSlide(doc *present.Doc) float64 {
+	worst := 0.0
+	for i, section := range doc.Sections {
+		if isBlankSection(section) {
+			continue
+		}
+		c.currentSlideNumber = i + 2
+		c.currentSlideTitle = section.Title
+		c.footnotes = nil
+		c.pdf.AddPage()
+
+		n := c.titleLines(c.smarten(section.Title))
+		c.titleOverflow = float64(max(n-1, 0)) * titleLine
+		c.untitled = strings.TrimSpace(section.Title) == ""
+		y := c.contentTop()
+		for _, elem := range section.Elem {
+			y = c.renderElement(elem, y)
+		}
+		worst = max(worst, (y-c.contentTop())/(c.contentBottom()-c.contentTop()))
+	}
+	return worst
+}
+
+// autoFitSlide shrinks text and code of the current slide for WithAutoFit
+// when its elements overflow: to the largest scale at which they fit, but at
+// least minAutoFitScale of the deck's size. The chosen scale is reported.
+// The returned function restores the deck's size.
+func (c *Converter) autoFitSlide(elems []present.Elem) func() {
+	if !c.autoFit || c.scratch == nil {
+		return func() {}
+	}
+	base, codeFontSize := c.fontScale, c.codeFontSize/c.fontScale
+	fill := c.dryRenderFill(elems)
+	if fill <= 1 {
+		return func() {}
+	}
+
+	scale := max(1/fill, minAutoFitScale)
+	for {
+		c.setFontScale(base*scale, codeFontSize)
+		if scale <= minAutoFitScale || c.dryRenderFill(elems) <= 1 {
+			break
+		}
+		scale = max(scale-globalFontScaleStep, minAutoFitScale)
+	}
+	c.warnf("text and code shrunk to %.0f%% to fit", scale*100)
+	return func() { c.setFontScale(base, codeFontSize) }
+}
+
+// dryRenderFill lays out elems from the top of the content area in the
+// scratch PDF and returns the ratio of their height to the available height
+func (c *Converter) dryRenderFill(elems []present.Elem) float64 {
+	y := c.contentTop()
+	for _, elem := range elems {
+		y = c.dryRender(elem, y)
+	}
+	return (y - c.contentTop()) / (c.contentBottom() - c.contentTop())
+}
@@ -0,0 +1,223 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/present"
+)
+
+const (
+	footerFontSize = 10.0
+	footerBottom   = 12.0 // footer baseline cell offset from the region bottom (mm)
+	footerHeight   = 6.0
+
+	stampFontSize = 7.0 // WithGenerationStamp font size (pt)
+	stampBottom   = 5.0 // stamp cell offset from the region bottom (mm), below the footer
+
+	cornerNumberSize  = 40.0 // WithCornerSlideNumber font size (pt)
+	cornerNumberInset = 4.0  // corner number distance from the slide edges (mm)
+	cornerNumberFade  = 0.7  // how far the corner number is blended into the background
+
+	navDotRadius  = 1.0 // WithNavDots dot radius (mm)
+	navDotSpacing = 4.0 // distance between dot centers (mm)
+	navDotBottom  = 3.0 // dot center offset from the region bottom (mm)
+	navDotFade    = 0.6 // how far the other slides' dots are blended into the background
+)
+
+// footerVars holds the values substituted into a footer template
+type footerVars struct {
+	Title   string // Deck title
+	Section string // Current slide title
+	Slide   int    // 1-based slide number
+	Total   int    // Number of slides
+	Date    string // Formatted deck date
+}
+
+// expandFooter substitutes {title}, {section}, {n}, {total} and {date} in a
+// footer template and splits it into left, center and right segments.
+// "a|b|c" gives all three, "a|b" gives left and right, and a template
+// without "|" is centered.
+func expandFooter(template string, vars footerVars) (left, center, right string) {
+	r := strings.NewReplacer(
+		"{title}", vars.Title,
+		"{section}", vars.Section,
+		"{n}", strconv.Itoa(vars.Slide),
+		"{total}", strconv.Itoa(vars.Total),
+		"{date}", vars.Date,
+	)
+	parts := strings.Split(r.Replace(template), "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	switch len(parts) {
+	case 1:
+		return "", parts[0], ""
+	case 2:
+		return parts[0], "", parts[1]
+	default:
+		return parts[0], parts[1], strings.Join(parts[2:], " ")
+	}
+}
+
+// footerDoc captures the deck-level footer values before rendering
+func (c *Converter) footerDoc(doc *present.Doc) {
+	c.deckTitle = doc.Title
+	c.deckDate = ""
+	if !doc.Time.IsZero() {
+		c.deckDate = c.formatDate(doc.Time)
+	}
+	c.totalSlides = len(doc.Sections) + 1
+}
+
+// navDotsY returns the center line of the WithNavDots strip: at the bottom
+// edge, or between the footer and the generation stamp when there is one
+func (c *Converter) navDotsY() float64 {
+	if c.stamp {
+		return c.region.Y + c.region.H - stampBottom - navDotRadius
+	}
+	return c.region.Y + c.region.H - navDotBottom
+}
+
+// renderNavDots draws one dot per slide, centered at the bottom of a content
+// slide, each linking to its slide and the current one highlighted. Decks with
+// more slides than fit across the footer line get no dots.
+func (c *Converter) renderNavDots() {
+	if !c.navDots {
+		return
+	}
+	x, w := c.footerSpan()
+	if float64(c.totalSlides)*navDotSpacing > w {
+		return
+	}
+
+	other := blendRGB(c.theme.SlideText, c.theme.SlideBackground, navDotFade)
+	y := c.navDotsY()
+	cx := x + (w-float64(c.totalSlides-1)*navDotSpacing)/2
+	for n := 1; n <= c.totalSlides; n++ {
+		col := other
+		if n == c.currentSlideNumber {
+			col = c.theme.LinkColor
+		}
+		c.pdf.SetFillColor(col.R, col.G, col.B)
+		c.pdf.Circle(cx, y, navDotRadius, "F")
+		if id, ok := c.anchors[fmt.Sprintf("slide-%d", n)]; ok && n != c.currentSlideNumber {
+			c.linkArea(cx-navDotSpacing/2, y-navDotSpacing/2, navDotSpacing, navDotSpacing, id, "")
+		}
+		cx += navDotSpacing
+	}
+}
+
+// renderFooter draws the WithFooter template at the bottom of a content slide
+func (c *Converter) renderFooter() {
+	if c.footer == "" {
+		return
+	}
+	left, center, right := expandFooter(c.footer, footerVars{
+		Title:   c.deckTitle,
+		Section: c.currentSlideTitle,
+		Slide:   c.currentSlideNumber,
+		Total:   c.totalSlides,
+		Date:    c.deckDate,
+	})
+
+	c.setTextFont("", footerFontSize)
+	c.pdf.SetTextColor(c.theme.Footer.R, c.theme.Footer.G, c.theme.Footer.B)
+	y := c.region.Y + c.region.H - footerBottom
+	x, w := c.footerSpan()
+	for _, seg := range []struct{ text, align string }{
+		{left, "L"},
+		{center, "C"},
+		{right, "R"},
+	} {
+		if seg.text == "" {
+			continue
+		}
+		c.pdf.SetXY(x, y)
+		c.pdf.CellFormat(w, footerHeight, c.translator(seg.text), "", 0, seg.align, false, 0, "")
+	}
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+}
+
+// renderPageNumber draws the WithPageNumbers "n / total" counter
+// right-aligned on the footer line of a content slide, unless the WithFooter
+// template has a right part of its own
+func (c *Converter) renderPageNumber() {
+	if !c.pageNumbers {
+		return
+	}
+	if c.footer != "" {
+		if _, _, right := expandFooter(c.footer, footerVars{}); right != "" {
+			return
+		}
+	}
+	c.setTextFont("", footerFontSize)
+	col := c.theme.CodeLineNumber
+	c.pdf.SetTextColor(col.R, col.G, col.B)
+	x, w := c.footerSpan()
+	c.pdf.SetXY(x, c.region.Y+c.region.H-footerBottom)
+	label := fmt.Sprintf("%d / %d", c.currentSlideNumber, c.totalSlides)
+	c.pdf.CellFormat(w, footerHeight, label, "", 0, "R", false, 0, "")
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+}
+
+// cornerNumberRect returns where the WithCornerSlideNumber number goes: in
+// the bottom margin below the content, in the bottom-right corner unless the
+// logo is there
+func (c *Converter) cornerNumberRect() (rect, bool) {
+	if !c.cornerNumber {
+		return rect{}, false
+	}
+	prev, _ := c.pdf.GetFontSize()
+	defer c.pdf.SetFontSize(prev)
+	c.setTextFont("", cornerNumberSize)
+	r := rect{
+		W: c.pdf.GetStringWidth(strconv.Itoa(c.currentSlideNumber)) + 2*c.pdf.GetCellMargin(),
+		H: cornerNumberSize * ptToMM,
+	}
+	r.Y = c.region.Y + c.region.H - cornerNumberInset - r.H
+	r.X = c.region.X + c.region.W - cornerNumberInset - r.W
+	if c.logo != nil && c.logo.w > 0 && c.logo.corner == "bottom-right" {
+		r.X = c.region.X + cornerNumberInset
+	}
+	return r, true
+}
+
+// renderCornerNumber draws the slide number large and muted in a corner, for
+// the audience to refer to slides by number
+func (c *Converter) renderCornerNumber() {
+	r, ok := c.cornerNumberRect()
+	if !ok {
+		return
+	}
+	c.setTextFont("", cornerNumberSize)
+	col := blendRGB(c.theme.SlideText, c.theme.SlideBackground, cornerNumberFade)
+	c.pdf.SetTextColor(col.R, col.G, col.B)
+	c.pdf.SetXY(r.X, r.Y)
+	c.pdf.CellFormat(r.W, r.H, strconv.Itoa(c.currentSlideNumber), "", 0, "C", false, 0, "")
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+}
+
+// generationStamp returns the WithGenerationStamp text, e.g. "Generated
+// 2026-02-15 by present2pdf 1.4.0"
+func (c *Converter) generationStamp() string {
+	stamp := "Generated " + c.generated.Format("2006-01-02") + " by present2pdf"
+	if c.toolVersion != "" {
+		stamp += " " + c.toolVersion
+	}
+	return stamp
+}
+
+// renderGenerationStamp draws the WithGenerationStamp text centered at the
+// bottom edge of the slide in col
+func (c *Converter) renderGenerationStamp(col RGB) {
+	if !c.stamp {
+		return
+	}
+	c.setTextFont("", stampFontSize)
+	c.pdf.SetTextColor(col.R, col.G, col.B)
+	c.pdf.SetXY(c.contentX(), c.region.Y+c.region.H-stampBottom)
+	c.pdf.CellFormat(c.contentWidth(), 4, c.translator(c.generationStamp()), "", 0, "C", false, 0, "")
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+}
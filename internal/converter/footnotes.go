@@ -0,0 +1,86 @@
+package converter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	footnoteMarkScale  = 0.6 // footnote mark size relative to the text
+	footnoteFontSize   = 10.0
+	footnoteLineHeight = 5.0
+)
+
+// linkFootnotes numbers the links among fragments for WithLinksAsFootnotes
+// and returns the numbers keyed by the index of each link's last fragment,
+// where the mark is drawn
+func (c *Converter) linkFootnotes(fragments []TextFragment) map[int]int {
+	if !c.linksAsFootnotes {
+		return nil
+	}
+	notes := make(map[int]int)
+	for i, fragment := range fragments {
+		if fragment.URL == "" || strings.HasPrefix(fragment.URL, "#") || strings.TrimSpace(fragment.Text) == "" {
+			continue
+		}
+		if i+1 < len(fragments) && fragments[i+1].URL == fragment.URL {
+			continue
+		}
+		notes[i] = c.footnote(fragment.URL)
+	}
+	return notes
+}
+
+// footnote returns the number of url in the current slide's references,
+// adding it if needed
+func (c *Converter) footnote(url string) int {
+	for i, u := range c.footnotes {
+		if u == url {
+			return i + 1
+		}
+	}
+	c.footnotes = append(c.footnotes, url)
+	return len(c.footnotes)
+}
+
+// footnoteMarkWidth returns the width of the superscript mark for footnote n
+// after text of the given font size
+func (c *Converter) footnoteMarkWidth(n int, fontSize float64) float64 {
+	c.setTextFont("", fontSize*footnoteMarkScale)
+	return c.pdf.GetStringWidth(strconv.Itoa(n))
+}
+
+// drawFootnoteMark draws footnote number n as a superscript at x on the line
+// starting at y and returns its width. The caller restores its font.
+func (c *Converter) drawFootnoteMark(n int, x, y, lineHeight, fontSize float64) float64 {
+	w := c.footnoteMarkWidth(n, fontSize)
+	c.pdf.SetXY(x, y)
+	c.pdf.CellFormat(w, lineHeight*footnoteMarkScale, strconv.Itoa(n), "", 0, "L", false, 0, "")
+	return w
+}
+
+// renderFootnotes lists the current slide's link footnotes under a
+// "References" heading at the bottom of the content area, or right below the
+// content when it reaches further down
+func (c *Converter) renderFootnotes(y float64) {
+	if len(c.footnotes) == 0 {
+		return
+	}
+	height := float64(len(c.footnotes)+1) * footnoteLineHeight
+	top := math.Max(y, c.contentBottom()-height)
+	if top+height > c.region.Y+c.region.H {
+		top = c.contentBottom() - height // content overflowed the slide
+	}
+
+	c.pdf.SetTextColor(c.theme.Footer.R, c.theme.Footer.G, c.theme.Footer.B)
+	c.setTextFont("", footnoteFontSize)
+	c.pdf.SetXY(c.contentX(), top)
+	c.pdf.CellFormat(c.contentWidth(), footnoteLineHeight, c.translator("References"), "", 0, c.textAlign(), false, 0, "")
+	for i, url := range c.footnotes {
+		text := c.translator(strconv.Itoa(i+1) + ". " + url)
+		c.pdf.SetXY(c.contentX(), top+float64(i+1)*footnoteLineHeight)
+		c.linkCell(c.pdf.GetStringWidth(text), footnoteLineHeight, text, url)
+	}
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+}
@@ -0,0 +1,105 @@
+package converter
+
+import "strings"
+
+// splitFrontMatter separates a leading YAML front-matter block
+//
+//	---
+//	key: value
+//	---
+//
+// from the slide content. Only flat "key: value" pairs are supported; blank
+// lines and "#" comments are ignored. If the content does not start with a
+// complete front-matter block, it is returned unchanged with a nil map.
+func splitFrontMatter(content []byte) (map[string]string, []byte) {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, content
+	}
+
+	rest := text[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end == -1 {
+		return nil, content
+	}
+
+	// The closing delimiter must be a line on its own
+	after := rest[end+len("\n---"):]
+	if after != "" && !strings.HasPrefix(after, "\n") {
+		return nil, content
+	}
+
+	meta := make(map[string]string)
+	for _, line := range strings.Split(rest[:end], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		value = strings.Trim(value, `"'`)
+		meta[strings.TrimSpace(key)] = value
+	}
+
+	return meta, []byte(strings.TrimPrefix(after, "\n"))
+}
+
+// deckSettings are the converter settings a deck's front matter can change
+type deckSettings struct {
+	theme                 Theme
+	autoTheme             bool
+	codeTheme             string
+	pageWidth, pageHeight float64
+	language              string
+}
+
+// saveSettings records the settings configured by the options
+func (c *Converter) saveSettings() {
+	c.configured = deckSettings{c.theme, c.autoTheme, c.codeTheme, c.pageWidth, c.pageHeight, c.language}
+}
+
+// restoreSettings undoes what the front matter of a previous deck changed,
+// so a reused Converter starts every conversion from its options
+func (c *Converter) restoreSettings() {
+	s := c.configured
+	c.theme, c.autoTheme, c.codeTheme = s.theme, s.autoTheme, s.codeTheme
+	c.pageWidth, c.pageHeight, c.language = s.pageWidth, s.pageHeight, s.language
+}
+
+// applyFrontMatter applies recognized front-matter keys as converter
+// overrides. Settings passed explicitly as options take precedence.
+func (c *Converter) applyFrontMatter(meta map[string]string) {
+	for key, value := range meta {
+		if c.explicit[key] {
+			continue
+		}
+		switch key {
+		case "theme":
+			if value == autoThemeName {
+				c.autoTheme = true
+				continue
+			}
+			if _, ok := availableThemes[value]; !ok {
+				c.warnf("front matter: invalid %s %q ignored", key, value)
+				continue
+			}
+			c.theme = availableThemes[value]
+		case "code-theme":
+			c.codeTheme = value
+		case "page-size":
+			size, ok := lookupPageSize(value)
+			if !ok {
+				c.warnf("front matter: invalid %s %q ignored", key, value)
+				continue
+			}
+			c.pageWidth, c.pageHeight = size[0], size[1]
+		case "lang":
+			c.language = value
+		default:
+			c.warnf("front matter: unknown key %q ignored", key)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"math"
+	"reflect"
+)
+
+// grayRGB returns the gray with the same relative luminance as col, so
+// contrast ratios are unchanged by WithGrayscale
+func grayRGB(col RGB) RGB {
+	l := relativeLuminance(col)
+	s := 12.92 * l
+	if l > 0.0031308 {
+		s = 1.055*math.Pow(l, 1/2.4) - 0.055
+	}
+	v := int(math.Round(s * 255))
+	return RGB{v, v, v}
+}
+
+// grayscaleTheme returns t with every color replaced by its gray
+func grayscaleTheme(t Theme) Theme {
+	v := reflect.ValueOf(&t).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if col, ok := v.Field(i).Interface().(RGB); ok {
+			v.Field(i).Set(reflect.ValueOf(grayRGB(col)))
+		}
+	}
+	return t
+}
+
+// applyGrayscale turns the theme and the title gradient gray for
+// WithGrayscale; code token colors are converted as they are highlighted
+func (c *Converter) applyGrayscale() {
+	if !c.grayscale {
+		return
+	}
+	c.theme = grayscaleTheme(c.theme)
+	if c.gradient != nil {
+		c.gradient = &[2]RGB{grayRGB(c.gradient[0]), grayRGB(c.gradient[1])}
+	}
+}
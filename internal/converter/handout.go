@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	handoutMargin = 10.0 // page margin around the slide grid (mm)
+	handoutGap    = 8.0  // gap between slides in the grid (mm)
+)
+
+// slideTransform maps slide coordinates onto a reduced slot of a handout page
+type slideTransform struct {
+	X, Y  float64 // Top-left corner of the slot on the page (mm)
+	Scale float64 // Slide-to-page scale factor
+}
+
+// handoutLayout returns the physical page orientation and grid for n slides
+// per page: 2 slides stack on a portrait page, 4 form a 2x2 landscape grid
+func handoutLayout(n int) (orientation string, size gofpdf.SizeType, cols, rows int) {
+	if n == 2 {
+		return "P", gofpdf.SizeType{Wd: a4Height, Ht: a4Width}, 1, 2
+	}
+	return "L", gofpdf.SizeType{Wd: a4Width, Ht: a4Height}, 2, 2
+}
+
+// handoutSlot computes where slide number i (0-based) goes on its page
+func (c *Converter) handoutSlot(i int) slideTransform {
+	_, size, cols, rows := handoutLayout(c.handout)
+	slot := i % c.handout
+	col, row := slot%cols, slot/cols
+
+	cellW := (size.Wd - 2*handoutMargin - float64(cols-1)*handoutGap) / float64(cols)
+	cellH := (size.Ht - 2*handoutMargin - float64(rows-1)*handoutGap) / float64(rows)
+	scale := math.Min(cellW/c.pageWidth, cellH/c.pageHeight)
+
+	// Center the scaled slide in its cell
+	x := handoutMargin + float64(col)*(cellW+handoutGap) + (cellW-c.pageWidth*scale)/2
+	y := handoutMargin + float64(row)*(cellH+handoutGap) + (cellH-c.pageHeight*scale)/2
+	return slideTransform{X: x, Y: y, Scale: scale}
+}
+
+// beginSlide starts a new slide: a new page normally, or the next slot of a
+// handout page, with drawing scaled into that slot
+func (c *Converter) beginSlide() {
+	if c.thumbnail != nil {
+		c.beginTransform(*c.thumbnail)
+		return
+	}
+	if c.handout == 0 {
+		c.pdf.AddPage()
+		c.placeAnchors()
+		c.placeDestinations()
+		return
+	}
+
+	i := c.currentSlideNumber - 1
+	if i%c.handout == 0 {
+		orientation, size, _, _ := handoutLayout(c.handout)
+		c.pdf.AddPageFormat(orientation, size)
+	}
+	c.placeAnchors()
+	c.placeDestinations()
+	c.beginTransform(c.handoutSlot(i))
+}
+
+// beginTransform scales drawing of the slide into the slot t
+func (c *Converter) beginTransform(t slideTransform) {
+	c.transform = &t
+	c.pdf.TransformBegin()
+	c.pdf.TransformTranslate(t.X, t.Y)
+	c.pdf.TransformScale(t.Scale*100, t.Scale*100, 0, 0)
+}
+
+// endSlide finishes a slide started with beginSlide; on handout pages it
+// draws a thin frame separating the slide from its neighbours (and around
+// notes page thumbnails)
+func (c *Converter) endSlide() {
+	if c.transform == nil {
+		return
+	}
+	t := *c.transform
+	c.transform = nil
+	c.pdf.TransformEnd()
+
+	c.pdf.SetDrawColor(160, 160, 160)
+	c.pdf.SetLineWidth(0.2)
+	c.pdf.Rect(t.X, t.Y, c.pageWidth*t.Scale, c.pageHeight*t.Scale, "D")
+}
+
+// linkCell draws a cell at the current position that links to url (if not
+// empty); "#anchor" URLs jump to a slide within the document. gofpdf places
+// link areas in page coordinates, ignoring transforms, so on handout pages the
+// area is mapped into the slide's slot explicitly.
+func (c *Converter) linkCell(w, h float64, text, url string) {
+	link, internal := c.internalLink(url)
+	if internal {
+		url = ""
+	}
+	if c.transform == nil || (url == "" && link == 0) {
+		c.pdf.CellFormat(w, h, text, "", 0, "L", false, link, url)
+		return
+	}
+	x, y := c.pdf.GetXY()
+	c.pdf.CellFormat(w, h, text, "", 0, "L", false, 0, "")
+	c.linkArea(x, y, w, h, link, url)
+}
+
+// linkArea places a link area over the slide rectangle (x, y, w, h), to the
+// internal link or else to url, mapped into the slot on handout pages
+func (c *Converter) linkArea(x, y, w, h float64, link int, url string) {
+	if t := c.transform; t != nil {
+		x, y, w, h = t.X+x*t.Scale, t.Y+y*t.Scale, w*t.Scale, h*t.Scale
+	}
+	if link != 0 {
+		c.pdf.Link(x, y, w, h, link)
+	} else {
+		c.pdf.LinkString(x, y, w, h, url)
+	}
+}
@@ -0,0 +1,88 @@
+package converter
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ptToMM converts a font size in points to millimeters
+const ptToMM = 25.4 / 72
+
+// iconShortcodeRe matches an icon shortcode such as :warning:
+var iconShortcodeRe = regexp.MustCompile(`:([A-Za-z0-9_+-]+):`)
+
+// expandIcons splits plain-text fragments around the WithIconSet shortcodes
+// they contain and turns each shortcode into an icon fragment. Unknown
+// shortcodes, inline code and links are left as text.
+func (c *Converter) expandIcons(fragments []TextFragment) []TextFragment {
+	if len(c.icons) == 0 {
+		return fragments
+	}
+
+	var result []TextFragment
+	for _, f := range fragments {
+		if f.Code || f.URL != "" {
+			result = append(result, f)
+			continue
+		}
+
+		last := 0
+		for _, loc := range iconShortcodeRe.FindAllStringSubmatchIndex(f.Text, -1) {
+			path, ok := c.icons[f.Text[loc[2]:loc[3]]]
+			if !ok {
+				continue
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(c.slideDir, path)
+			}
+			if loc[0] > last {
+				text := f
+				text.Text = f.Text[last:loc[0]]
+				result = append(result, text)
+			}
+			icon := f
+			icon.Text = f.Text[loc[0]:loc[1]]
+			icon.Icon = path
+			result = append(result, icon)
+			last = loc[1]
+		}
+		if last < len(f.Text) {
+			text := f
+			text.Text = f.Text[last:]
+			result = append(result, text)
+		}
+	}
+	return result
+}
+
+// iconSize returns the size of an icon drawn in text: as tall as the text
+// font, with the width following the image aspect. ok is false (after a
+// warning) if the image cannot be loaded, so the shortcode is drawn as text.
+func (c *Converter) iconSize(path string) (w, h float64, ok bool) {
+	imgType, ok := imageType(path)
+	if !ok {
+		c.warnf("unsupported icon format %q: %s", imgType, path)
+		return 0, 0, false
+	}
+	info := c.pdf.RegisterImageOptions(path, gofpdf.ImageOptions{ImageType: imgType})
+	if c.pdf.Err() {
+		c.warnf("failed to load icon %s: %v", path, c.pdf.Error())
+		c.pdf.ClearError()
+		return 0, 0, false
+	}
+
+	h = 18 * c.textScale * ptToMM
+	if info.Height() > 0 {
+		w = h * info.Width() / info.Height()
+	}
+	return w, h, true
+}
+
+// drawIcon draws an icon of size w x h centered vertically in a text line
+// of height lineHeight at (x, y)
+func (c *Converter) drawIcon(path string, x, y, w, h, lineHeight float64) {
+	imgType, _ := imageType(path)
+	c.pdf.ImageOptions(path, x, y+(lineHeight-h)/2, w, h, false, gofpdf.ImageOptions{ImageType: imgType}, 0, "")
+}
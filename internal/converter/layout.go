@@ -0,0 +1,182 @@
+package converter
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	a4Width  = 297.0 // landscape A4 page width (mm)
+	a4Height = 210.0 // landscape A4 page height (mm)
+
+	slideMargin  = 20.0 // left/right/bottom content margin (mm)
+	titleTop     = 15.0 // slide title offset from the region top (mm)
+	titleLine    = 12.0 // height of a slide title line (mm)
+	titleLineTop = 36.0 // title underline offset from the region top (mm)
+	contentTop   = 45.0 // content start offset from the region top (mm)
+	untitledTop  = 20.0 // content start offset on a slide without a title (mm)
+)
+
+// pageSizes are the WithPageSize page sizes (width, height in mm). The wide
+// sizes keep the A4 height the slide layout is designed for and widen the
+// page to their aspect ratio.
+var pageSizes = map[string][2]float64{
+	"A4":   {a4Width, a4Height},
+	"16:9": {a4Height * 16 / 9, a4Height},
+	"4:3":  {a4Height * 4 / 3, a4Height},
+}
+
+// lookupPageSize returns the size of a WithPageSize name, ignoring case
+func lookupPageSize(name string) ([2]float64, bool) {
+	size, ok := pageSizes[strings.ToUpper(name)]
+	return size, ok
+}
+
+// GetAvailablePageSizes returns the names accepted by WithPageSize
+func GetAvailablePageSizes() []string {
+	names := make([]string, 0, len(pageSizes))
+	for name := range pageSizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rect is an axis-aligned rectangle in page coordinates (mm)
+type rect struct {
+	X, Y, W, H float64
+}
+
+// computeRegion derives the drawable slide region for a page of the given size.
+// With a positive aspect ratio (width / height) the region is the largest box of
+// that aspect that fits on the page, centered; otherwise it covers the full page.
+func computeRegion(pageW, pageH, aspect float64) rect {
+	if aspect <= 0 {
+		return rect{0, 0, pageW, pageH}
+	}
+	w, h := pageW, pageW/aspect
+	if h > pageH {
+		w, h = pageH*aspect, pageH
+	}
+	return rect{(pageW - w) / 2, (pageH - h) / 2, w, h}
+}
+
+// contentX returns the left edge of the slide content area, which is
+// centered when WithMaxContentWidth narrows it and moves right by the
+// indent of the subsection being rendered (left in RTL mode)
+func (c *Converter) contentX() float64 {
+	x := c.region.X + slideMargin + (c.region.W-2*slideMargin-c.columnWidth())/2
+	if c.rtl {
+		return x
+	}
+	return x + c.subsectionIndent
+}
+
+// contentWidth returns the width of the slide content area
+func (c *Converter) contentWidth() float64 {
+	return c.columnWidth() - c.subsectionIndent
+}
+
+// columnWidth returns the width of the content column, before subsection
+// indents
+func (c *Converter) columnWidth() float64 {
+	w := c.region.W - 2*slideMargin
+	if c.maxContentWidth > 0 && w > c.maxContentWidth {
+		return c.maxContentWidth
+	}
+	return w
+}
+
+// contentTop returns the Y where slide content starts (below the title,
+// moved down when the title wraps, or near the top without a title)
+func (c *Converter) contentTop() float64 {
+	if c.untitled {
+		return c.region.Y + untitledTop
+	}
+	return c.region.Y + contentTop + c.titleOverflow
+}
+
+// contentBottom returns the bottom boundary of the slide content area
+func (c *Converter) contentBottom() float64 {
+	return c.region.Y + c.region.H - slideMargin - c.slideNotesHeight()
+}
+
+// titleSlideY maps a Y position laid out for an A4 title slide onto the
+// current region, keeping the same relative vertical placement.
+func (c *Converter) titleSlideY(y float64) float64 {
+	return c.region.Y + y*c.region.H/a4Height
+}
+
+// fillBackground paints the page: letterbox margins in the theme's letterbox
+// color (when the region does not cover the page) and the region itself in bg.
+func (c *Converter) fillBackground(bg RGB) {
+	if c.region.W < c.pageWidth || c.region.H < c.pageHeight {
+		c.pdf.SetFillColor(c.theme.Letterbox.R, c.theme.Letterbox.G, c.theme.Letterbox.B)
+		c.pdf.Rect(0, 0, c.pageWidth, c.pageHeight, "F")
+	}
+	c.pdf.SetFillColor(bg.R, bg.G, bg.B)
+	c.pdf.Rect(c.region.X, c.region.Y, c.region.W, c.region.H, "F")
+}
+
+// gradientBands is the number of solid bands used to approximate a gradient
+const gradientBands = 120
+
+// fillGradientBackground paints the page like fillBackground, but fills the
+// region with a vertical gradient from top to bottom, drawn as thin bands
+func (c *Converter) fillGradientBackground(top, bottom RGB) {
+	c.fillBackground(top)
+
+	bandH := c.region.H / gradientBands
+	for i := 0; i < gradientBands; i++ {
+		t := float64(i) / (gradientBands - 1)
+		col := blendRGB(top, bottom, t)
+		c.pdf.SetFillColor(col.R, col.G, col.B)
+		// Overlap bands slightly so no hairline gaps show between them
+		c.pdf.Rect(c.region.X, c.region.Y+float64(i)*bandH, c.region.W, bandH+0.1, "F")
+	}
+}
+
+// blendRGB linearly interpolates between a (t=0) and b (t=1)
+func blendRGB(a, b RGB, t float64) RGB {
+	mix := func(x, y int) int {
+		return int(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return RGB{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B)}
+}
+
+// contrastRatio returns the WCAG contrast ratio (1..21) between two colors
+func contrastRatio(a, b RGB) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// readableColor returns col, lightened on a dark bg or darkened on a light
+// one in small steps until its contrast against bg reaches minContrast
+func readableColor(col, bg RGB, minContrast float64) RGB {
+	target := RGB{0, 0, 0}
+	if relativeLuminance(bg) < 0.5 {
+		target = RGB{255, 255, 255}
+	}
+	for t := 0.0; t <= 1; t += 0.05 {
+		if c := blendRGB(col, target, t); contrastRatio(c, bg) >= minContrast {
+			return c
+		}
+	}
+	return target
+}
+
+// relativeLuminance returns the WCAG relative luminance of a color
+func relativeLuminance(col RGB) float64 {
+	channel := func(v int) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(col.R) + 0.7152*channel(col.G) + 0.0722*channel(col.B)
+}
@@ -0,0 +1,131 @@
+package converter
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	logoMaxWidth  = 30.0 // largest logo width (mm)
+	logoMaxHeight = 10.0 // largest logo height (mm), fits above the title
+	logoInset     = 3.0  // logo distance from the slide edges (mm)
+	logoGap       = 2.0  // space kept between the logo and the footer text (mm)
+)
+
+// cornerLogo is the WithCornerLogo image and its placement
+type cornerLogo struct {
+	path   string // Image path as given to WithCornerLogo
+	corner string // top-left, top-right, bottom-left or bottom-right
+
+	// Set by prepareLogo for the current conversion
+	file      string  // Resolved image path
+	imageType string  // gofpdf image type
+	w, h      float64 // Scaled size (mm), zero if the logo is unusable
+}
+
+// validLogoCorners lists the corners accepted by WithCornerLogo
+var validLogoCorners = map[string]bool{
+	"top-left":     true,
+	"top-right":    true,
+	"bottom-left":  true,
+	"bottom-right": true,
+}
+
+// prepareLogo registers the corner logo once per document and computes its
+// size. A logo that cannot be used is reported and dropped.
+func (c *Converter) prepareLogo() {
+	if c.logo == nil {
+		return
+	}
+	c.logo.w, c.logo.h = 0, 0
+	if !validLogoCorners[c.logo.corner] {
+		c.warnf("corner logo: unknown corner %q (want top-left, top-right, bottom-left or bottom-right)", c.logo.corner)
+		return
+	}
+
+	path := c.logo.path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.slideDir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		c.warnf("corner logo: image not found: %s", path)
+		return
+	}
+	imgType, ok := imageType(path)
+	if !ok {
+		c.warnf("corner logo: unsupported image format %q: %s", imgType, path)
+		return
+	}
+
+	// gofpdf keeps registered images by name, so the logo is embedded once
+	// however many slides show it
+	info := c.pdf.RegisterImageOptions(path, gofpdf.ImageOptions{ImageType: imgType})
+	if c.pdf.Err() {
+		c.warnf("corner logo: failed to load image %s: %v", path, c.pdf.Error())
+		c.pdf.ClearError()
+		return
+	}
+	if info.Width() <= 0 || info.Height() <= 0 {
+		return
+	}
+
+	scale := math.Min(logoMaxWidth/info.Width(), logoMaxHeight/info.Height())
+	c.logo.imageType = imgType
+	c.logo.file = path
+	c.logo.w, c.logo.h = info.Width()*scale, info.Height()*scale
+}
+
+// logoRect returns where the logo goes on the current slide. Top corners
+// sit above the title; bottom corners share the footer line, which
+// renderFooter shortens to keep clear of the logo.
+func (c *Converter) logoRect() (rect, bool) {
+	if c.logo == nil || c.logo.w == 0 {
+		return rect{}, false
+	}
+	r := rect{W: c.logo.w, H: c.logo.h}
+	switch c.logo.corner {
+	case "top-left", "bottom-left":
+		r.X = c.region.X + logoInset
+	default:
+		r.X = c.region.X + c.region.W - logoInset - r.W
+	}
+	switch c.logo.corner {
+	case "top-left", "top-right":
+		r.Y = c.region.Y + logoInset
+	default:
+		// Centered on the footer line
+		r.Y = c.region.Y + c.region.H - footerBottom + footerHeight/2 - r.H/2
+	}
+	return r, true
+}
+
+// renderLogo draws the corner logo on the current slide
+func (c *Converter) renderLogo() {
+	r, ok := c.logoRect()
+	if !ok {
+		return
+	}
+	c.pdf.ImageOptions(c.logo.file, r.X, r.Y, r.W, r.H, false, gofpdf.ImageOptions{ImageType: c.logo.imageType}, 0, "")
+}
+
+// footerSpan returns the horizontal extent of the footer, narrowed so its
+// text does not run into a logo or the corner slide number in a bottom corner
+func (c *Converter) footerSpan() (x, w float64) {
+	x, w = c.contentX(), c.contentWidth()
+	for _, corner := range []func() (rect, bool){c.logoRect, c.cornerNumberRect} {
+		r, ok := corner()
+		if !ok || r.Y < c.contentBottom() {
+			continue
+		}
+		if left := r.X + r.W + logoGap; r.X < x+w/2 && left > x {
+			w -= left - x
+			x = left
+		} else if right := r.X - logoGap; r.X >= x+w/2 && right < x+w {
+			w = right - x
+		}
+	}
+	return x, w
+}
@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	notesThumbnailScale = 0.45 // slide thumbnail size on a notes page
+	notesMargin         = 10.0 // space above the thumbnail and below the notes (mm)
+	notesFontSize       = 14.0
+	notesLineHeight     = 7.0
+
+	slideNotesFontSize   = 11.0
+	slideNotesLineHeight = 5.0
+	slideNotesGap        = 4.0     // space between the slide content and appended notes (mm)
+	maxSlideNotesShare   = 1.0 / 3 // largest part of the content area appended notes take
+)
+
+// notesModes are the WithNotes modes
+var notesModes = []string{"ignore", "append", "pages"}
+
+// GetAvailableNotesModes returns the speaker notes modes of WithNotes
+func GetAvailableNotesModes() []string {
+	return slices.Clone(notesModes)
+}
+
+// layoutSlideNotes wraps the speaker notes of the current slide for
+// WithNotes("append"), truncating them to a third of the content area. The
+// content area ends above the returned lines once they are set as
+// c.slideNotes.
+func (c *Converter) layoutSlideNotes(notes []string) []string {
+	text := strings.TrimSpace(strings.Join(notes, "\n"))
+	if !c.appendNotes || text == "" {
+		return nil
+	}
+	c.setTextFont("", slideNotesFontSize)
+	lines := c.wrapText(text, c.contentWidth()-2*c.pdf.GetCellMargin())
+	maxLines := int((c.contentBottom() - c.contentTop()) * maxSlideNotesShare / slideNotesLineHeight)
+	if len(lines) > maxLines {
+		c.warnf("speaker notes truncated on the slide (%d of %d lines fit)", maxLines, len(lines))
+		lines = lines[:maxLines]
+	}
+	return lines
+}
+
+// slideNotesHeight returns the height taken by c.slideNotes at the bottom
+// of the content area, including the gap above them
+func (c *Converter) slideNotesHeight() float64 {
+	if len(c.slideNotes) == 0 {
+		return 0
+	}
+	return float64(len(c.slideNotes))*slideNotesLineHeight + slideNotesGap
+}
+
+// renderSlideNotes draws c.slideNotes below the content area, under a thin
+// rule and in the muted footer color
+func (c *Converter) renderSlideNotes() {
+	if len(c.slideNotes) == 0 {
+		return
+	}
+	x, w := c.contentX(), c.contentWidth()
+	top := c.contentBottom() + slideNotesGap
+	c.pdf.SetDrawColor(c.theme.Footer.R, c.theme.Footer.G, c.theme.Footer.B)
+	c.pdf.SetLineWidth(0.2)
+	c.pdf.Line(x, top-slideNotesGap/2, x+w, top-slideNotesGap/2)
+	c.pdf.SetTextColor(c.theme.Footer.R, c.theme.Footer.G, c.theme.Footer.B)
+	c.setTextFont("", slideNotesFontSize)
+	for i, line := range c.slideNotes {
+		c.pdf.SetXY(x, top+float64(i)*slideNotesLineHeight)
+		c.pdf.CellFormat(w, slideNotesLineHeight, c.translator(c.displayText(line)), "", 0, c.textAlign(), false, 0, "")
+	}
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+}
+
+// notesPage adds the notes page for the slide just rendered in WithNotesPages
+// mode
+func (c *Converter) notesPage(render func(), notes []string) {
+	if c.notesPages && c.handout == 0 {
+		c.renderNotesPage(render, notes)
+	}
+}
+
+// renderNotesPage adds a WithNotesPages page after a slide: a thumbnail of
+// the slide drawn again by render, followed by the speaker notes
+func (c *Converter) renderNotesPage(render func(), notes []string) {
+	c.pdf.AddPage()
+
+	// Redraw the slide scaled down, without repeating its diagnostics
+	quiet, verbose := c.quiet, c.verbose
+	warnings, images := len(c.warnings), len(c.images)
+	c.quiet, c.verbose = true, false
+	t := c.notesThumbnail()
+	c.thumbnail = &t
+	render()
+	c.endSlide()
+	c.thumbnail = nil
+	c.quiet, c.verbose = quiet, verbose
+	c.warnings, c.images = c.warnings[:warnings], c.images[:images]
+
+	text := strings.TrimSpace(strings.Join(notes, "\n"))
+	if text == "" {
+		return
+	}
+	x := c.region.X + slideMargin
+	w := c.region.W - 2*slideMargin
+	y := t.Y + c.pageHeight*t.Scale + notesMargin
+	c.pdf.SetTextColor(0, 0, 0)
+	c.setTextFont("", notesFontSize)
+	lines := c.wrapText(text, w-2*c.pdf.GetCellMargin())
+	maxLines := int((c.pageHeight - notesMargin - y) / notesLineHeight)
+	if len(lines) > maxLines {
+		c.warnf("speaker notes truncated on the notes page (%d of %d lines fit)", maxLines, len(lines))
+		lines = lines[:maxLines]
+	}
+	c.drawText(x, y, w, notesLineHeight, strings.Join(lines, "\n"), c.textAlign())
+}
+
+// notesThumbnail returns the slot of the slide thumbnail at the top center
+// of a notes page
+func (c *Converter) notesThumbnail() slideTransform {
+	return slideTransform{
+		X:     c.pageWidth * (1 - notesThumbnailScale) / 2,
+		Y:     notesMargin,
+		Scale: notesThumbnailScale,
+	}
+}
@@ -0,0 +1,154 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError is returned when the slide source cannot be parsed. It points at
+// the offending line of the input file where it can be determined.
+type ParseError struct {
+	File    string // Input file path
+	Line    int    // 1-based line number, 0 if unknown
+	Context string // Source lines around Line, the offending one marked with ">"
+	Err     error  // Error reported by the present parser
+}
+
+// Error formats the error with the line number and source context
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("failed to parse presentation: %v", e.Err)
+	}
+	msg := parseErrPosRe.ReplaceAllString(e.Err.Error(), "")
+	return fmt.Sprintf("failed to parse presentation: %s:%d: %s\n%s", e.File, e.Line, msg, e.Context)
+}
+
+// Unwrap returns the underlying parser error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// parseErrPosRe matches the "file:line: " prefix of positioned errors; the
+	// file name may contain spaces and colons
+	parseErrPosRe = regexp.MustCompile(`^.*?:(\d+): `)
+
+	// parseErrQuoteRe matches errors quoting the offending line, e.g.
+	// unexpected header line: "## Slide"
+	parseErrQuoteRe = regexp.MustCompile(`: ("(?:[^"\\]|\\.)*")$`)
+
+	// dateLikeRe matches lines that look like an attempt at a date
+	dateLikeRe = regexp.MustCompile(`(?i)^(\d{1,2}:\d{2} )?(\d{1,2}[ ./-]+[a-z]+[ ./-]+\d{4}|[a-z]+ \d{1,2},? \d{4}|\d{4}-\d{1,2}-\d{1,2}|\d{1,2}[./]\d{1,2}[./]\d{4})$`)
+)
+
+// newParseError locates the line a present parser error refers to. original
+// is the file as read from disk; parsed is the preprocessed content given to
+// the parser, whose line numbers may differ.
+func newParseError(original, parsed []byte, inputPath string, err error) error {
+	origLines := strings.Split(strings.ReplaceAll(string(original), "\r\n", "\n"), "\n")
+	parsedLines := strings.Split(string(parsed), "\n")
+
+	// The offending source line, as text. Its line number is shifted by the
+	// front matter the parser did not see.
+	offending := ""
+	line := 0
+	if m := parseErrPosRe.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n >= 1 && n <= len(parsedLines) {
+			offending = parsedLines[n-1]
+			_, body := splitFrontMatter(original)
+			line = n + len(origLines) - strings.Count(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n") - 1
+		}
+	} else if m := parseErrQuoteRe.FindStringSubmatch(err.Error()); m != nil {
+		offending, _ = strconv.Unquote(m[1])
+	}
+
+	if offending != "" {
+		// Map back to the original file, where other preprocessing may have
+		// moved the line: the matching line nearest the expected one, or
+		// without a line number the first match
+		best := -1
+		for i, l := range origLines {
+			if strings.TrimSpace(l) != strings.TrimSpace(offending) {
+				continue
+			}
+			if best < 0 || (line > 0 && abs(i+1-line) < abs(best+1-line)) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			line = best + 1
+		}
+	}
+	if line == 0 || line > len(origLines) {
+		return &ParseError{File: inputPath, Err: err}
+	}
+
+	return &ParseError{
+		File:    inputPath,
+		Line:    line,
+		Context: sourceContext(origLines, line, 2),
+		Err:     err,
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// sourceContext formats the lines around line (1-based), marking it with ">"
+func sourceContext(lines []string, line, radius int) string {
+	var b strings.Builder
+	for n := max(1, line-radius); n <= min(len(lines), line+radius); n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %4d | %s\n", marker, n, lines[n-1])
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// checkHeader warns about header lines that look like a date but are not in
+// a format present understands; present silently shows them as a subtitle
+func (c *Converter) checkHeader(content []byte) {
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	i := 0
+
+	// Skip front matter
+	if len(lines) > 0 && lines[0] == "---" {
+		for i = 1; i < len(lines) && lines[i] != "---"; i++ {
+		}
+		i++
+	}
+	// Skip to the title line
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+
+	// Header lines follow the title up to the first blank line
+	for i++; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		text := strings.TrimSpace(lines[i])
+		if !dateLikeRe.MatchString(text) || isPresentDate(text) {
+			continue
+		}
+		c.warnf("line %d: %q looks like a date but is not in the \"2 Jan 2006\" format; it will be shown as a subtitle", i+1, text)
+	}
+}
+
+// isPresentDate reports whether present parses text as the deck date
+func isPresentDate(text string) bool {
+	for _, layout := range []string{"15:04 2 Jan 2006", "2 Jan 2006"} {
+		if _, err := time.Parse(layout, text); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package converter
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// refDefinitionRe matches a Markdown link reference definition:
+	// [label]: https://example.com "optional title"
+	refDefinitionRe = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:\s*<?([^\s>]+)>?(?:\s+(?:"[^"]*"|'[^']*'|\([^)]*\)))?\s*$`)
+
+	// refLinkRe matches [text][label], [text][] and the shortcut form [label]
+	refLinkRe = regexp.MustCompile(`\[([^\[\]]+)\](?:\[([^\[\]]*)\])?`)
+)
+
+// isMarkdownDoc reports whether content uses the Markdown-enabled present
+// format (the title line starts with "# ")
+func isMarkdownDoc(content []byte) bool {
+	return strings.HasPrefix(strings.TrimLeft(string(content), " \r\n"), "# ")
+}
+
+// preprocessReferenceLinks rewrites Markdown reference-style links
+// ([text][ref], [text][] and [ref]) into inline links ([text](url)).
+//
+// The present package renders every text block of a slide as a separate
+// Markdown document, so a definition like "[ref]: https://..." only resolves
+// links in the same block. Resolving them up front makes definitions work
+// anywhere in the file. Definition lines are removed from the output.
+func preprocessReferenceLinks(content []byte) []byte {
+	if !isMarkdownDoc(content) {
+		return content
+	}
+
+	lines := strings.Split(string(content), "\n")
+	refs := make(map[string]string)
+	inCodeBlock := false
+	var kept []string
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+		}
+		if !inCodeBlock && !isIndentedCode(line) {
+			if m := refDefinitionRe.FindStringSubmatch(line); m != nil {
+				refs[normalizeRefLabel(m[1])] = m[2]
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+
+	if len(refs) == 0 {
+		return content
+	}
+
+	inCodeBlock = false
+	for i, line := range kept {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock || isIndentedCode(line) {
+			continue
+		}
+		kept[i] = resolveReferenceLinks(line, refs)
+	}
+
+	return []byte(strings.Join(kept, "\n"))
+}
+
+// resolveReferenceLinks replaces reference-style links in a single line
+func resolveReferenceLinks(line string, refs map[string]string) string {
+	matches := refLinkRe.FindAllStringSubmatchIndex(line, -1)
+	if matches == nil {
+		return line
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		text := line[m[2]:m[3]]
+		label := text
+		if m[4] >= 0 && m[5] > m[4] {
+			label = line[m[4]:m[5]] // [text][label]
+		}
+
+		// A shortcut [label] followed by "(" or ":" is an inline link or a
+		// definition, not a reference
+		if m[4] < 0 && end < len(line) && (line[end] == '(' || line[end] == ':') {
+			continue
+		}
+
+		url, ok := refs[normalizeRefLabel(label)]
+		if !ok {
+			continue
+		}
+		b.WriteString(line[last:start])
+		b.WriteString("[" + text + "](" + url + ")")
+		last = end
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// normalizeRefLabel folds case and whitespace like CommonMark label matching
+func normalizeRefLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// isIndentedCode reports whether a Markdown line is an indented code line
+func isIndentedCode(line string) bool {
+	return strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ")
+}
+
+// onlyMarkerRe matches an audience marker line: <!-- only: handout -->
+var onlyMarkerRe = regexp.MustCompile(`^\s*<!--\s*only:\s*(\S+)\s*-->\s*$`)
+
+// isolateAudienceMarkers puts blank lines around the audience markers of a
+// legacy deck, so that each parses as a paragraph of its own for
+// filterAudience instead of joining the text around it. Markdown keeps its
+// markers as HTML comments.
+func isolateAudienceMarkers(content []byte) []byte {
+	if !bytes.Contains(content, []byte("only:")) || isMarkdownDoc(content) {
+		return content
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var out []string
+	inCodeBlock := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+		}
+		// An indented marker is part of a code block
+		if inCodeBlock || !onlyMarkerRe.MatchString(line) || strings.TrimLeft(line, " \t") != line {
+			out = append(out, line)
+			continue
+		}
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+		out = append(out, line)
+		if i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			out = append(out, "")
+		}
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// timeMarkerRe matches a speaker timing marker: <!-- time: 2m -->
+var timeMarkerRe = regexp.MustCompile(`^\s*<!--\s*time:\s*(\S+)\s*-->\s*$`)
+
+// extractTimings records the duration of each section from its timing
+// marker (any time.ParseDuration value, e.g. 90s or 1m30s) and strips the
+// markers. Timings are indexed like doc.Sections.
+func (c *Converter) extractTimings(content []byte) []byte {
+	c.timings = nil
+	if !bytes.Contains(content, []byte("time:")) {
+		return content
+	}
+
+	heading := "* "
+	if isMarkdownDoc(content) {
+		heading = "## "
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var out []string
+	section := -1
+	inCodeBlock := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+		}
+		if !inCodeBlock && strings.HasPrefix(line, heading) {
+			section++
+		}
+		m := timeMarkerRe.FindStringSubmatch(line)
+		if inCodeBlock || m == nil {
+			out = append(out, line)
+			continue
+		}
+
+		d, err := time.ParseDuration(m[1])
+		switch {
+		case err != nil || d <= 0:
+			c.warnf("invalid timing %q ignored", m[1])
+		case section < 0:
+			c.warnf("timing %q before the first slide ignored", m[1])
+		default:
+			if c.timings == nil {
+				c.timings = make(map[int]time.Duration)
+			}
+			c.timings[section] += d
+		}
+	}
+	return []byte(strings.Join(out, "\n"))
+}
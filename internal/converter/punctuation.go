@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cp1251Fallbacks maps typographic characters missing from cp1251 to the
+// closest character it has. The cp1251 translator prints "." for anything
+// it cannot encode; dashes, quotes and ellipsis are all in cp1251 as is.
+var cp1251Fallbacks = strings.NewReplacer(
+	"\u2010", "-", // hyphen
+	"\u2011", "-", // non-breaking hyphen
+	"\u2012", "–", // figure dash
+	"\u2015", "—", // horizontal bar
+	"\u2212", "-", // minus sign
+	"\u201B", "‘", // single high-reversed-9 quote
+	"\u201F", "“", // double high-reversed-9 quote
+	"\u2032", "'", // prime
+	"\u2033", `"`, // double prime
+	"\u2002", " ", // en space
+	"\u2003", " ", // em space
+	"\u2009", " ", // thin space
+	"\u200A", " ", // hair space
+	"\u202F", " ", // narrow no-break space
+)
+
+// smartDashes replaces "---" and "--" with em and en dashes and "..." with an
+// ellipsis. Longer runs come first so "---" is not read as "--" + "-".
+var smartDashes = strings.NewReplacer("---", "—", "--", "–", "...", "…")
+
+// smartPunctuation converts straight quotes to curly ones and ASCII dashes
+// and dots to their typographic forms, the way Markdown typographers do.
+// prev is the character before s (' ' at the start of a text), which decides
+// whether a leading quote opens or closes; the last character of the result
+// is returned so the conversion can continue across formatted fragments.
+func smartPunctuation(s string, prev rune) (string, rune) {
+	s = smartDashes.Replace(s)
+
+	var b strings.Builder
+	for _, r := range s {
+		opening := unicode.IsSpace(prev) || strings.ContainsRune("([{–—“‘", prev)
+		switch {
+		case r == '"' && opening:
+			r = '“'
+		case r == '"':
+			r = '”'
+		case r == '\'' && opening:
+			r = '‘'
+		case r == '\'':
+			r = '’' // closing quote or apostrophe
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String(), prev
+}
+
+// smarten applies WithSmartPunctuation to a plain text
+func (c *Converter) smarten(s string) string {
+	if !c.smartPunctuation {
+		return s
+	}
+	s, _ = smartPunctuation(s, ' ')
+	return s
+}
+
+// smartenFragments applies WithSmartPunctuation to formatted text, leaving
+// inline code as written
+func (c *Converter) smartenFragments(fragments []TextFragment) []TextFragment {
+	if !c.smartPunctuation {
+		return fragments
+	}
+	prev := ' '
+	for i, f := range fragments {
+		if f.Code {
+			prev = 'x'
+			continue
+		}
+		fragments[i].Text, prev = smartPunctuation(f.Text, prev)
+	}
+	return fragments
+}
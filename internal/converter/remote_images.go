@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	remoteImageTimeout = 15 * time.Second // limit for downloading one remote image
+	maxRemoteImageSize = 20 << 20         // largest remote image downloaded (bytes)
+)
+
+// isHTTPURL reports whether an image reference is an http or https URL
+func isHTTPURL(ref string) bool {
+	lower := strings.ToLower(ref)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// renderRemoteImage renders an http(s) image, downloaded once per
+// conversion with WithAllowRemoteImages. The image is listed under its URL.
+// A download that is not allowed or fails is reported like a missing file.
+func (c *Converter) renderRemoteImage(url, alt string, size imageSize, y float64) float64 {
+	if !c.allowRemoteImages {
+		c.warnf("remote image not loaded (remote images are disabled): %s", url)
+		return y
+	}
+	local, err := c.localImage(url, c.downloadImage)
+	if err != nil {
+		c.warnf("failed to download image %s: %v", url, err)
+		return y
+	}
+
+	n := len(c.images)
+	y = c.renderImageFile(local, alt, size, y)
+	if len(c.images) > n {
+		c.images[n].Path = url
+	}
+	return y
+}
+
+// downloadImage saves an http(s) image into a temp file, giving up after
+// remoteImageTimeout or past maxRemoteImageSize bytes
+func (c *Converter) downloadImage(url string) (string, error) {
+	client := http.Client{Timeout: remoteImageTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %s", resp.Status)
+	}
+
+	// The extension is kept for imageType, in case the content is not sniffed
+	f, err := c.imageTempFile("image-*" + path.Ext(resp.Request.URL.Path))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxRemoteImageSize+1))
+	if err != nil {
+		return "", err
+	}
+	if n > maxRemoteImageSize {
+		return "", fmt.Errorf("larger than %d MB", maxRemoteImageSize>>20)
+	}
+	return f.Name(), nil
+}
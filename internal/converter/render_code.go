@@ -1,10 +1,12 @@
 package converter
 
 import (
+	"bytes"
 	"fmt"
-	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/lexers"
@@ -12,32 +14,57 @@ import (
 	"golang.org/x/tools/present"
 )
 
+const (
+	codeTabWidth        = 4    // number of columns a tab advances to in code blocks
+	defaultCodeFontSize = 11.0 // code block font size (pt)
+	defaultMaxCodeLines = 20   // lines shown before a code block is truncated
+	defaultCodePadH     = 5.0  // code text inset from the left and right of the block (mm)
+	defaultCodePadV     = 2.5  // code text inset from the top and bottom of the block (mm)
+	codeBlockGap        = 7.0  // space below a code block (mm)
+	minCommentContrast  = 4.5  // lowest contrast of comments against the code background (WCAG AA)
+)
+
 // Token represents a syntax-highlighted token
 type Token struct {
-	Type  chroma.TokenType
-	Value string
-	Color [3]int // RGB color
+	Type   chroma.TokenType
+	Value  string
+	Color  [3]int // RGB color
+	Italic bool   // the style sets the token in italics
 }
 
-// renderCode renders code block
-func (c *Converter) renderCode(code present.Code, y float64) float64 {
-	// Extract code lines from Raw content
-	codeText := string(code.Raw)
+// codeWithLanguage is a .code or .play block given a language with -lang=X
+type codeWithLanguage struct {
+	present.Code
+	Language string
+}
 
-	// Detect language from filename if available
-	language := "go" // default to Go
-	if code.FileName != "" {
-		language = detectLanguage(code.FileName)
-	}
+// renderCode renders code block, in the explicit language if not empty
+func (c *Converter) renderCode(code present.Code, explicit string, y float64) float64 {
+	// Extract code lines from Raw content. Snippets selected from the middle of
+	// a file (e.g. .code file.go /^\tfunc/,/^\t}/) keep their original
+	// indentation, so strip the common leading whitespace.
+	codeText := dedent(codeSnippet(string(code.Raw)))
+	language := c.codeLanguage(explicit, code.FileName, codeText)
 
-	// Highlight the code
-	tokens, err := c.highlightCode(codeText, language)
-	if err != nil {
-		// Fallback to plain rendering if highlighting fails
-		return c.renderCodePlain(codeText, y)
-	}
+	return c.renderCodeText(codeText, language, y)
+}
 
-	return c.renderHighlightedCode(tokens, y)
+var (
+	// omitLineRe matches a line of a .code file hidden from the slide: one
+	// ending in an OMIT marker, such as "// START OMIT"
+	omitLineRe = regexp.MustCompile(`(?m)^.*OMIT[ \t]*(\n|$)`)
+
+	// hlCommentRe matches a "// HL" highlight marker at the end of a line
+	hlCommentRe = regexp.MustCompile(`(?m)[ \t]*// HL[a-zA-Z0-9_]*[ \t]*$`)
+)
+
+// codeSnippet cleans up the source of a .code or .play directive the way
+// present shows it: lines with OMIT markers are dropped (present only drops
+// those without trailing blanks) and "// HL" markers removed
+func codeSnippet(raw string) string {
+	raw = omitLineRe.ReplaceAllString(raw, "")
+	raw = hlCommentRe.ReplaceAllString(raw, "")
+	return trimBlankLines(raw)
 }
 
 // renderMarkdownCodeBlock renders markdown code blocks (```)
@@ -49,103 +76,176 @@ func (c *Converter) renderMarkdownCodeBlock(content string, y float64) float64 {
 	if len(match) < 3 {
 		// No valid code block found, render as plain text
 		c.setTextFont("", 21)
-		c.pdf.SetXY(20, y)
-		c.pdf.MultiCell(257, 11, c.translator(content), "", "L", false)
-		return y + 15
-	}
-
-	language := match[1]
-	if language == "" {
-		language = "go" // default
+		n := c.drawText(c.contentX(), y, c.contentWidth(), 11, content, "L")
+		return y + float64(n)*11 + 4
 	}
-	codeText := strings.TrimSpace(match[2])
 
-	// Highlight the code
-	tokens, err := c.highlightCode(codeText, language)
-	if err != nil {
-		// Fallback to plain rendering
-		return c.renderCodePlain(codeText, y)
-	}
+	codeText := dedent(trimBlankLines(match[2]))
+	language := c.codeLanguage(match[1], "", codeText)
 
-	return c.renderHighlightedCode(tokens, y)
+	return c.renderCodeText(codeText, language, y)
 }
 
-// renderHighlightedCode renders syntax-highlighted tokens as a code block
-func (c *Converter) renderHighlightedCode(tokens []Token, y float64) float64 {
+// renderHighlightedCode renders syntax-highlighted tokens as a code block,
+// numbering its lines from first
+func (c *Converter) renderHighlightedCode(tokens []Token, first int, y float64) float64 {
 	// Split tokens into lines
 	lines := splitTokensIntoLines(tokens)
 
 	// Calculate code block height
-	codeHeight := float64(len(lines)) * 6
-	if codeHeight > 120 {
-		codeHeight = 120
-	}
-
-	// Background for code
-	c.pdf.SetFillColor(c.theme.CodeBackground.R, c.theme.CodeBackground.G, c.theme.CodeBackground.B)
-	c.pdf.Rect(20, y, 257, codeHeight+5, "F")
+	lineHeight := c.codeLineHeight()
+	shown := c.shownCodeLines(len(lines))
+	codeHeight := float64(shown) * lineHeight
 
 	// Render lines with syntax highlighting
-	lineY := y + 2
-	maxLines := 20
+	numberX, lineY := c.beginCodeBlock(y, codeHeight)
+	gutter := c.lineNumberGutter(first - 1 + numberedCodeLines(shown, len(lines)))
+	textX := numberX + gutter
 	for i, line := range lines {
-		if i >= maxLines {
-			if !c.quiet {
-				fmt.Fprintf(os.Stderr, "Warning: code block truncated on slide %d \"%s\" (max %d lines, has %d)\n", c.currentSlideNumber, c.currentSlideTitle, maxLines, len(lines))
-			}
+		if i == shown-1 && shown < len(lines) {
 			c.pdf.SetTextColor(c.theme.CodeLineNumber.R, c.theme.CodeLineNumber.G, c.theme.CodeLineNumber.B)
-			c.setCodeFont("", 11)
-			c.pdf.SetXY(25, lineY)
-			c.pdf.Cell(0, 6, c.translator("..."))
+			c.setCodeFont("", c.codeFontSize)
+			c.pdf.SetXY(textX, lineY)
+			c.pdf.Cell(0, lineHeight, c.translator("..."))
 			break
 		}
-		c.renderHighlightedLine(line, 25, lineY)
-		lineY += 6
+		c.renderLineNumber(first+i, numberX, lineY, gutter)
+		if c.isFoldLine(line) {
+			c.renderFoldLine(line, textX, lineY, lineHeight)
+		} else {
+			c.renderHighlightedLine(line, textX, lineY)
+		}
+		lineY += lineHeight
 	}
 
-	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
-	return y + codeHeight + 12
+	return c.endCodeBlock(y, codeHeight)
 }
 
-// renderCodePlain renders code without syntax highlighting (fallback)
-func (c *Converter) renderCodePlain(code string, y float64) float64 {
-	lines := strings.Split(code, "\n")
+// renderCodePlain renders code without syntax highlighting (fallback),
+// numbering its lines from first
+func (c *Converter) renderCodePlain(code string, first int, y float64) float64 {
+	lines := strings.Split(normalizeCode(code), "\n")
 
-	// Background for code
-	c.pdf.SetFillColor(c.theme.CodeBackground.R, c.theme.CodeBackground.G, c.theme.CodeBackground.B)
-	codeHeight := float64(len(lines)) * 6
-	if codeHeight > 120 {
-		codeHeight = 120
-	}
-
-	c.pdf.Rect(20, y, 257, codeHeight+5, "F")
+	lineHeight := c.codeLineHeight()
+	shown := c.shownCodeLines(len(lines))
+	codeHeight := float64(shown) * lineHeight
+	numberX, lineY := c.beginCodeBlock(y, codeHeight)
+	gutter := c.lineNumberGutter(first - 1 + numberedCodeLines(shown, len(lines)))
+	textX := numberX + gutter
 
 	// Code text - use JetBrains Mono for monospace with Cyrillic support
-	c.setCodeFont("", 11)
+	c.setCodeFont("", c.codeFontSize)
 	c.pdf.SetTextColor(c.theme.CodeText.R, c.theme.CodeText.G, c.theme.CodeText.B)
 
-	lineY := y + 2
-	maxLines := 20
 	for i, line := range lines {
-		if i >= maxLines {
-			if !c.quiet {
-				fmt.Fprintf(os.Stderr, "Warning: code block truncated on slide %d \"%s\" (max %d lines, has %d)\n", c.currentSlideNumber, c.currentSlideTitle, maxLines, len(lines))
-			}
-			c.pdf.SetXY(25, lineY)
-			c.pdf.Cell(0, 6, c.translator("..."))
+		if i == shown-1 && shown < len(lines) {
+			c.pdf.SetXY(textX, lineY)
+			c.pdf.Cell(0, lineHeight, c.translator("..."))
 			break
 		}
-		c.pdf.SetXY(25, lineY)
-		c.pdf.Cell(0, 6, c.translator(line))
-		lineY += 6
+		c.renderLineNumber(first+i, numberX, lineY, gutter)
+		c.setCodeFont("", c.codeFontSize)
+		if c.codeFolding && isFoldText(line) {
+			c.pdf.SetTextColor(c.theme.CodeLineNumber.R, c.theme.CodeLineNumber.G, c.theme.CodeLineNumber.B)
+		}
+		c.pdf.SetXY(textX, lineY)
+		c.pdf.Cell(0, lineHeight, c.translator(line))
+		c.pdf.SetTextColor(c.theme.CodeText.R, c.theme.CodeText.G, c.theme.CodeText.B)
+		lineY += lineHeight
 	}
 
+	return c.endCodeBlock(y, codeHeight)
+}
+
+// beginCodeBlock draws the background of a code block at y holding
+// codeHeight of text, clips drawing to the area inside the horizontal
+// padding and returns where the first line of text starts
+func (c *Converter) beginCodeBlock(y, codeHeight float64) (textX, textY float64) {
+	boxHeight := codeHeight + 2*c.codePadV
+	c.pdf.SetFillColor(c.theme.CodeBackground.R, c.theme.CodeBackground.G, c.theme.CodeBackground.B)
+	x, w := c.contentX()+c.codeIndent, c.contentWidth()-c.codeIndent
+	c.pdf.Rect(x, y, w, boxHeight, "F")
+	c.pdf.ClipRect(x+c.codePadH, y, w-2*c.codePadH, boxHeight, false)
+	return x + c.codePadH, y + c.codePadV
+}
+
+// endCodeBlock ends the clipping of beginCodeBlock and returns the Y position
+// below the block
+func (c *Converter) endCodeBlock(y, codeHeight float64) float64 {
+	c.pdf.ClipEnd()
 	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
-	return y + codeHeight + 12
+	return y + codeHeight + 2*c.codePadV + codeBlockGap
+}
+
+// shownCodeLines returns how many lines of an n-line code block are drawn.
+// Blocks longer than codeLineLimit are truncated and their last drawn line is
+// "...", so the returned count always matches the height of the block.
+func (c *Converter) shownCodeLines(n int) int {
+	limit := c.codeLineLimit()
+	if n <= limit {
+		return n
+	}
+	c.warnf("code block truncated (max %d lines, has %d)", limit, n)
+	return limit
+}
+
+// codeLineLimit returns the most lines a code block shows: as many as fit in
+// the content area of a slide, and no more than WithMaxCodeLines unless long
+// blocks are split across WithContinuation pages
+func (c *Converter) codeLineLimit() int {
+	limit := int((c.contentBottom() - c.contentTop() - 2*c.codePadV) / c.codeLineHeight())
+	if c.maxCodeLines > 0 && !c.continuation {
+		limit = min(limit, c.maxCodeLines)
+	}
+	return max(limit, 2)
+}
+
+// numberedCodeLines returns how many of the shown lines of an n-line code
+// block get a line number: all of them, but not the "..." of a truncated block
+func numberedCodeLines(shown, n int) int {
+	if shown < n {
+		return shown - 1
+	}
+	return shown
+}
+
+// lineNumberGutter returns the width reserved left of the code for
+// WithCodeLineNumbers: the digits of the last line number plus one column of
+// space. It is 0 without line numbers.
+func (c *Converter) lineNumberGutter(last int) float64 {
+	if !c.codeLineNumbers {
+		return 0
+	}
+	c.setCodeFont("", c.codeFontSize)
+	digits := len(strconv.Itoa(max(last, 1)))
+	return c.pdf.GetStringWidth(strings.Repeat("0", digits+1))
+}
+
+// renderLineNumber draws line number n right-aligned in the gutter at x
+func (c *Converter) renderLineNumber(n int, x, y, gutter float64) {
+	if gutter == 0 {
+		return
+	}
+	c.setCodeFont("", c.codeFontSize)
+	c.pdf.SetTextColor(c.theme.CodeLineNumber.R, c.theme.CodeLineNumber.G, c.theme.CodeLineNumber.B)
+	// Cells draw their text one cell margin in: start the number so it ends
+	// one column short of the gutter edge
+	label := strconv.Itoa(n)
+	w := c.pdf.GetStringWidth(label)
+	c.pdf.SetXY(x+gutter-c.pdf.GetStringWidth("0")-w-c.pdf.GetCellMargin(), y)
+	c.pdf.Cell(w, c.codeLineHeight(), label)
+	c.pdf.SetTextColor(c.theme.CodeText.R, c.theme.CodeText.G, c.theme.CodeText.B)
+}
+
+// codeLineHeight returns the height of a code line (mm), 6mm at the default
+// 11pt and proportional to the code font size
+func (c *Converter) codeLineHeight() float64 {
+	return c.codeFontSize * 6 / defaultCodeFontSize
 }
 
 // renderHighlightedLine renders a line of syntax-highlighted tokens
-func (c *Converter) renderHighlightedLine(tokens []Token, x, y float64) {
+// and returns the X position after the last token
+func (c *Converter) renderHighlightedLine(tokens []Token, x, y float64) float64 {
 	currentX := x
 
 	for _, token := range tokens {
@@ -156,14 +256,23 @@ func (c *Converter) renderHighlightedLine(tokens []Token, x, y float64) {
 		value := c.translator(token.Value)
 
 		// Use JetBrains Mono for code - monospace font with Cyrillic support
-		c.setCodeFont("", 11)
+		c.setCodeFont("", c.codeFontSize)
 
 		// Get width of the text to advance X position
 		width := c.pdf.GetStringWidth(value)
-		c.pdf.Cell(width, 6, value)
+		if token.Italic {
+			c.pdf.TransformBegin()
+			c.pdf.TransformSkew(italicSkew, 0, currentX, y)
+		}
+		c.pdf.Cell(width, c.codeLineHeight(), value)
+		if token.Italic {
+			c.pdf.TransformEnd()
+		}
 
 		currentX += width
 	}
+
+	return currentX
 }
 
 // highlightCode performs syntax highlighting on code
@@ -175,32 +284,54 @@ func (c *Converter) highlightCode(code, language string) ([]Token, error) {
 	}
 	lexer = chroma.Coalesce(lexer)
 
-	// Get style
+	// Get style (resolveCodeTheme has replaced unknown names)
 	style := styles.Get(c.codeTheme)
-	if style == nil {
-		style = styles.Fallback
-	}
 
 	// Tokenize
-	iterator, err := lexer.Tokenise(nil, code)
+	lexed, err := tokenise(lexer, normalizeCode(code))
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert to our Token format with colors
 	var tokens []Token
-	for _, token := range iterator.Tokens() {
+	for _, token := range lexed {
 		color := getTokenColor(token.Type, style)
+		// Styles often dim comments too much for the code background
+		if token.Type.InCategory(chroma.Comment) {
+			col := readableColor(RGB{color[0], color[1], color[2]}, c.theme.CodeBackground, minCommentContrast)
+			color = [3]int{col.R, col.G, col.B}
+		}
+		if c.grayscale {
+			col := grayRGB(RGB{color[0], color[1], color[2]})
+			color = [3]int{col.R, col.G, col.B}
+		}
 		tokens = append(tokens, Token{
-			Type:  token.Type,
-			Value: token.Value,
-			Color: color,
+			Type:   token.Type,
+			Value:  token.Value,
+			Color:  color,
+			Italic: style.Get(token.Type).Italic == chroma.Yes,
 		})
 	}
 
 	return tokens, nil
 }
 
+// tokenise runs a lexer over code. Some lexers panic on pathological input;
+// the panic is returned as an error so the code is rendered plain instead.
+func tokenise(lexer chroma.Lexer, code string) (tokens []chroma.Token, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s lexer panicked: %v", lexer.Config().Name, r)
+		}
+	}()
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return nil, err
+	}
+	return iterator.Tokens(), nil
+}
+
 // getTokenColor returns RGB color for a token type based on style
 func getTokenColor(tokenType chroma.TokenType, style *chroma.Style) [3]int {
 	entry := style.Get(tokenType)
@@ -234,6 +365,108 @@ func getTokenColor(tokenType chroma.TokenType, style *chroma.Style) [3]int {
 	}
 }
 
+// normalizeCode converts CRLF/CR line endings to LF and expands tabs to
+// spaces, so that every character reaching the PDF has a real glyph width.
+// Tabs and carriage returns have no visible glyph in the embedded fonts but
+// are still measured by GetStringWidth, which shifts subsequent tokens.
+func normalizeCode(code string) string {
+	code = strings.ReplaceAll(code, "\r\n", "\n")
+	code = strings.ReplaceAll(code, "\r", "\n")
+	if !strings.Contains(code, "\t") {
+		return code
+	}
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line, codeTabWidth)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dedent removes the leading whitespace common to all non-blank lines,
+// preserving relative indentation. Blank lines do not affect the result.
+func dedent(code string) string {
+	lines := strings.Split(code, "\n")
+
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix = indent
+			first = false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	if prefix == "" {
+		return code
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+		if strings.TrimSpace(lines[i]) == "" {
+			lines[i] = strings.TrimLeft(lines[i], " \t")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// trimBlankLines removes leading and trailing blank lines while keeping the
+// indentation of the first non-blank line (unlike strings.TrimSpace)
+func trimBlankLines(code string) string {
+	lines := strings.Split(code, "\n")
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return strings.Join(lines[start:end], "\n")
+}
+
+// expandCodeTabs expands the tabs of a code block to the WithLanguageTabWidth
+// width of its language, before normalizeCode would apply the default
+func (c *Converter) expandCodeTabs(code, language string) string {
+	width, ok := c.tabWidths[strings.ToLower(language)]
+	if !ok || !strings.Contains(code, "\t") {
+		return code
+	}
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// expandTabs replaces tabs in a single line with spaces up to the next tab stop
+func expandTabs(line string, width int) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := width - col%width
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
+
 // splitTokensIntoLines splits tokens into lines
 func splitTokensIntoLines(tokens []Token) [][]Token {
 	if len(tokens) == 0 {
@@ -252,11 +485,9 @@ func splitTokensIntoLines(tokens []Token) [][]Token {
 				currentLine = nil
 			}
 			if part != "" {
-				currentLine = append(currentLine, Token{
-					Type:  token.Type,
-					Value: part,
-					Color: token.Color,
-				})
+				t := token
+				t.Value = part
+				currentLine = append(currentLine, t)
 			}
 		}
 	}
@@ -267,49 +498,154 @@ func splitTokensIntoLines(tokens []Token) [][]Token {
 	return lines
 }
 
+var (
+	languageExtensionsMu sync.RWMutex
+	languageExtensions   = make(map[string]string)
+)
+
+// RegisterLanguageExtension maps a file extension (with or without the
+// leading dot, e.g. ".jsonnet") to a chroma lexer name. Registered extensions
+// take precedence over the built-in ones. It is safe for concurrent use.
+func RegisterLanguageExtension(ext, lexerName string) {
+	languageExtensionsMu.Lock()
+	defer languageExtensionsMu.Unlock()
+	languageExtensions[strings.TrimPrefix(ext, ".")] = lexerName
+}
+
+var (
+	// codeDirectiveRe matches a .code or .play directive line
+	codeDirectiveRe = regexp.MustCompile(`^\.(?:code|play)\s`)
+
+	// codeLangRe matches a -lang=X flag on a .code or .play directive line
+	codeLangRe = regexp.MustCompile(`^(\.(?:code|play)\s(?:.*?\s)?)-lang=(\S+)\s+(.*)$`)
+)
+
+// extractCodeLanguages strips the -lang=X flag from .code and .play lines
+// (which the present parser would reject) and returns the language of each
+// directive in source order, "" for those without the flag.
+func extractCodeLanguages(content []byte) ([]string, []byte) {
+	if !bytes.Contains(content, []byte("-lang=")) {
+		return nil, content
+	}
+
+	var languages []string
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if !codeDirectiveRe.MatchString(line) {
+			continue
+		}
+		language := ""
+		if m := codeLangRe.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + m[3]
+			language = m[2]
+		}
+		languages = append(languages, language)
+	}
+	return languages, []byte(strings.Join(lines, "\n"))
+}
+
+// applyCodeLanguages gives the code blocks of doc the languages
+// extractCodeLanguages took out, matching them to directives by position, so
+// identical directives can differ in language
+func (c *Converter) applyCodeLanguages(doc *present.Doc) {
+	if len(c.codeLanguages) == 0 {
+		return
+	}
+	n := 0
+	var apply func(elems []present.Elem)
+	apply = func(elems []present.Elem) {
+		for i, elem := range elems {
+			switch e := elem.(type) {
+			case present.Code:
+				if n < len(c.codeLanguages) && c.codeLanguages[n] != "" {
+					elems[i] = codeWithLanguage{Code: e, Language: c.codeLanguages[n]}
+				}
+				n++
+			case present.Section:
+				apply(e.Elem)
+			}
+		}
+	}
+	for _, section := range doc.Sections {
+		apply(section.Elem)
+	}
+}
+
+// codeLanguage picks the language of a code block, in order of precedence:
+// the explicit language (.code -lang=X or a fenced block's tag), the
+// filename extension, the language chroma recognizes from the code itself
+// (e.g. a shebang line), and finally WithDefaultCodeLanguage.
+func (c *Converter) codeLanguage(explicit, filename, code string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if language, ok := languageForFile(filename); ok {
+		return language
+	}
+	if lexer := lexers.Analyse(code); lexer != nil {
+		return strings.ToLower(lexer.Config().Name)
+	}
+	return c.defaultLanguage
+}
+
 // detectLanguage detects programming language from filename
 func detectLanguage(filename string) string {
+	if language, ok := languageForFile(filename); ok {
+		return language
+	}
+	return "go" // default to Go
+}
+
+// languageForFile returns the language for a filename extension and whether
+// the extension is known
+func languageForFile(filename string) (string, bool) {
 	ext := ""
 	if idx := strings.LastIndex(filename, "."); idx != -1 {
 		ext = filename[idx+1:]
 	}
 
+	languageExtensionsMu.RLock()
+	lexerName, ok := languageExtensions[ext]
+	languageExtensionsMu.RUnlock()
+	if ok {
+		return lexerName, true
+	}
+
 	switch ext {
 	case "go":
-		return "go"
+		return "go", true
 	case "py":
-		return "python"
+		return "python", true
 	case "js":
-		return "javascript"
+		return "javascript", true
 	case "ts":
-		return "typescript"
+		return "typescript", true
 	case "java":
-		return "java"
+		return "java", true
 	case "c":
-		return "c"
+		return "c", true
 	case "cpp", "cc", "cxx":
-		return "cpp"
+		return "cpp", true
 	case "rs":
-		return "rust"
+		return "rust", true
 	case "rb":
-		return "ruby"
+		return "ruby", true
 	case "php":
-		return "php"
+		return "php", true
 	case "sh", "bash":
-		return "bash"
+		return "bash", true
 	case "html":
-		return "html"
+		return "html", true
 	case "css":
-		return "css"
+		return "css", true
 	case "json":
-		return "json"
+		return "json", true
 	case "xml":
-		return "xml"
+		return "xml", true
 	case "yaml", "yml":
-		return "yaml"
+		return "yaml", true
 	case "sql":
-		return "sql"
-	default:
-		return "go" // default to Go
+		return "sql", true
 	}
+	return "", false
 }
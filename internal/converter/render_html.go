@@ -2,6 +2,7 @@ package converter
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 
 	"golang.org/x/tools/present"
@@ -14,6 +15,9 @@ type TextFragment struct {
 	Italic bool
 	Code   bool   // inline code (monospace font + background)
 	URL    string // non-empty for clickable links
+	Icon   string // image path drawn instead of Text (a WithIconSet shortcode)
+	Kbd    bool   // keyboard key (<kbd>): small monospace text in a key cap
+	Mark   bool   // highlighted text (<mark>)
 }
 
 // renderHTML renders HTML element (used in Markdown-enabled presentations)
@@ -23,9 +27,10 @@ func (c *Converter) renderHTML(html present.HTML, y float64) float64 {
 	// Check if content contains multiple element types
 	// Note: use "<pre><code" (without >) to match both <pre><code> and <pre><code class="...">
 	hasCode := strings.Contains(htmlContent, "<pre><code")
-	hasLists := strings.Contains(htmlContent, "<ul>") || strings.Contains(htmlContent, "<ol>")
+	hasLists := strings.Contains(htmlContent, "<ul>") || strings.Contains(htmlContent, "<ol")
 	hasParagraphs := strings.Contains(htmlContent, "<p>")
 	hasBlockquote := strings.Contains(htmlContent, "<blockquote>")
+	hasRule := strings.Contains(htmlContent, "<hr")
 
 	// Count how many different types we have
 	typeCount := 0
@@ -41,9 +46,13 @@ func (c *Converter) renderHTML(html present.HTML, y float64) float64 {
 	if hasBlockquote {
 		typeCount++
 	}
+	if hasRule {
+		typeCount++
+	}
 
-	// If content has multiple element types, render them in order
-	if typeCount > 1 {
+	// If content has multiple element types, render them in order. Rules
+	// always go this way, as one element may hold several of them.
+	if typeCount > 1 || hasRule {
 		return c.renderHTMLMixed(htmlContent, y)
 	}
 
@@ -73,15 +82,23 @@ func (c *Converter) renderHTML(html present.HTML, y float64) float64 {
 	return c.renderHTMLPlainText(htmlContent, y)
 }
 
-// renderHTMLMixed renders HTML content with mixed paragraphs, lists, code blocks, and blockquotes in order
+// renderHTMLMixed renders HTML content with mixed paragraphs, lists, code blocks, blockquotes and rules in order
 func (c *Converter) renderHTMLMixed(html string, y float64) float64 {
 	// Split by major HTML tags while preserving them
 	// Blockquote is listed first to take priority over inner <p> tags
-	re := regexp.MustCompile(`(?s)(<blockquote>.*?</blockquote>|<pre><code.*?</code></pre>|<p>.*?</p>|<ul>.*?</ul>|<ol>.*?</ol>)`)
-	matches := re.FindAllString(html, -1)
+	re := regexp.MustCompile(`(?s)(<blockquote>.*?</blockquote>|<pre><code.*?</code></pre>|<p>.*?</p>|<ul>.*?</ul>|<ol[ >].*?</ol>|<hr\s*/?>)`)
 
-	for _, match := range matches {
-		match = strings.TrimSpace(match)
+	end := 0
+	for _, loc := range re.FindAllStringIndex(html, -1) {
+		// Skip blocks inside a nested list, which the list already covers
+		if loc[0] < end {
+			continue
+		}
+		end = loc[1]
+		if strings.HasPrefix(html[loc[0]:], "<ul>") || strings.HasPrefix(html[loc[0]:], "<ol") {
+			end = htmlListEnd(html, loc[0])
+		}
+		match := strings.TrimSpace(html[loc[0]:end])
 		if match == "" {
 			continue
 		}
@@ -93,14 +110,48 @@ func (c *Converter) renderHTMLMixed(html string, y float64) float64 {
 			y = c.renderHTMLCode(match, y)
 		} else if strings.HasPrefix(match, "<p>") {
 			y = c.renderHTMLParagraphs(match, y)
-		} else if strings.HasPrefix(match, "<ul>") || strings.HasPrefix(match, "<ol>") {
+		} else if strings.HasPrefix(match, "<ul>") || strings.HasPrefix(match, "<ol") {
 			y = c.renderHTMLList(match, y)
+		} else if strings.HasPrefix(match, "<hr") {
+			y = c.renderHTMLHr(y)
 		}
 	}
 
 	return y
 }
 
+// ruleGap is the space below a horizontal rule (mm)
+const ruleGap = 5.0
+
+// renderHTMLHr renders a thematic break (<hr>) as a thin line across the
+// content area in the title line color
+func (c *Converter) renderHTMLHr(y float64) float64 {
+	c.pdf.SetDrawColor(c.theme.SlideTitleLine.R, c.theme.SlideTitleLine.G, c.theme.SlideTitleLine.B)
+	c.pdf.SetLineWidth(0.3)
+	c.pdf.Line(c.contentX(), y, c.contentX()+c.contentWidth(), y)
+	return y + ruleGap
+}
+
+// htmlListEnd returns the end of the list starting at start in html, past
+// the </ul> or </ol> that closes it rather than that of a nested list
+func htmlListEnd(html string, start int) int {
+	depth := 0
+	for _, m := range listTagRe.FindAllStringSubmatchIndex(html[start:], -1) {
+		if tag := strings.ToLower(html[start+m[4] : start+m[5]]); tag == "li" {
+			continue
+		}
+		if m[3] > m[2] {
+			depth--
+		} else {
+			depth++
+		}
+		if depth == 0 {
+			return start + m[1]
+		}
+	}
+	return len(html)
+}
+
 // renderHTMLParagraphs renders multiple HTML paragraphs
 func (c *Converter) renderHTMLParagraphs(html string, y float64) float64 {
 	// Extract all paragraphs
@@ -124,58 +175,281 @@ func (c *Converter) renderHTMLParagraphs(html string, y float64) float64 {
 			}
 
 			// Parse HTML formatting
-			fragments := parseHTMLFormatting(paragraphHTML)
+			fragments := c.parseFormatting(paragraphHTML)
 
 			// Render formatted text
 			c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
-			y = c.renderFormattedText(fragments, 20, y, 257, 11)
-			y += 5 // Extra spacing between paragraphs
+			for _, line := range c.paragraphLines(fragments) {
+				y = c.renderFormattedText(line, c.contentX(), y, c.contentWidth(), 11*c.fontScale)
+			}
+			y += c.paragraphSpacing
 		}
 	}
 
 	return y
 }
 
+// brTagRe matches a <br> line break tag
+var brTagRe = regexp.MustCompile(`(?i)<br\s*/?>\n?`)
+
+// paragraphLines splits the fragments of a paragraph at its source line
+// breaks and <br> tags with WithPreserveLineBreaks, so each line is laid out
+// on its own; otherwise the paragraph is reflowed as a whole
+func (c *Converter) paragraphLines(fragments []TextFragment) [][]TextFragment {
+	if !c.preserveLineBreaks {
+		return [][]TextFragment{fragments}
+	}
+	lines := [][]TextFragment{nil}
+	for _, fragment := range fragments {
+		for i, text := range strings.Split(brTagRe.ReplaceAllString(fragment.Text, "\n"), "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if strings.TrimSpace(text) != "" {
+				part := fragment
+				part.Text = text
+				lines[len(lines)-1] = append(lines[len(lines)-1], part)
+			}
+		}
+	}
+	return lines
+}
+
 // renderHTMLList renders HTML list
 func (c *Converter) renderHTMLList(html string, y float64) float64 {
-	// Extract list items
-	re := regexp.MustCompile(`(?s)<li>(.*?)</li>`)
-	matches := re.FindAllStringSubmatch(html, -1)
+	return c.renderListItems(c.parseHTMLList(html), y)
+}
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			itemHTML := strings.TrimSpace(match[1])
+// listItem is a list entry with its nesting depth (0 for top-level items)
+type listItem struct {
+	Fragments []TextFragment
+	Depth     int
+	Ordered   bool // item of an ordered list, drawn with Number instead of a bullet
+	Number    int
+}
 
-			// Parse HTML formatting
-			fragments := parseHTMLFormatting(itemHTML)
+var (
+	// listTagRe matches the tags that structure a (possibly nested) HTML list
+	listTagRe = regexp.MustCompile(`(?i)<(/?)(ul|ol|li)\b[^>]*>`)
 
-			// Render bullet
-			c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
-			c.setTextFont("", 18)
-			c.pdf.SetXY(25, y)
-			c.pdf.Cell(8, 9, c.translator("• "))
+	// listStartRe matches the start attribute of an <ol> tag
+	listStartRe = regexp.MustCompile(`(?i)\bstart="?(-?\d+)`)
+)
 
-			// Render formatted text
-			y = c.renderFormattedText(fragments, 30, y, 247, 9)
-			y += 3
+// parseHTMLList extracts the items of an HTML list, descending into lists
+// nested inside items. The text of an item ends where its sub-list starts.
+// Items of <ol> lists are numbered from the list's start attribute (or 1).
+func (c *Converter) parseHTMLList(html string) []listItem {
+	var items []listItem
+	depth := -1
+	var text strings.Builder
+	inItem := false
+
+	// The open lists, innermost last: whether each is ordered and the
+	// number of its next item
+	var ordered []bool
+	var next []int
+
+	flush := func() {
+		if inItem {
+			if t := strings.TrimSpace(text.String()); t != "" {
+				item := listItem{Fragments: c.parseFormatting(t), Depth: max(depth, 0)}
+				if n := len(ordered); n > 0 && ordered[n-1] {
+					item.Ordered, item.Number = true, next[n-1]
+					next[n-1]++
+				}
+				items = append(items, item)
+			}
 		}
+		text.Reset()
+		inItem = false
 	}
 
-	return y + 6
+	last := 0
+	for _, m := range listTagRe.FindAllStringSubmatchIndex(html, -1) {
+		text.WriteString(html[last:m[0]])
+		last = m[1]
+
+		closing := m[3] > m[2]
+		switch tag := strings.ToLower(html[m[4]:m[5]]); {
+		case tag == "li" && !closing:
+			flush()
+			inItem = true
+		case tag == "li":
+			flush()
+		case !closing: // <ul> or <ol>
+			flush()
+			depth++
+			start := 1
+			if attr := listStartRe.FindStringSubmatch(html[m[0]:m[1]]); attr != nil {
+				start, _ = strconv.Atoi(attr[1])
+			}
+			ordered, next = append(ordered, tag == "ol"), append(next, start)
+		default:
+			flush()
+			depth--
+			if n := len(ordered); n > 0 {
+				ordered, next = ordered[:n-1], next[:n-1]
+			}
+		}
+	}
+	text.WriteString(html[last:])
+	flush()
+	return items
+}
+
+// renderListItems renders bulleted list items. With WithListAutoFit the font
+// shrinks (down to minListFontSize) until the whole list fits on the slide.
+func (c *Converter) renderListItems(items []listItem, y float64) float64 {
+	scale := c.fontScale
+	if c.listAutoFit {
+		scale = c.fitListScale(items, y)
+	}
+	c.textScale = scale
+	defer func() { c.textScale = c.fontScale }()
+
+	lineHeight := 9 * scale
+	c.setTextFont("", 18*scale)
+	widen := c.numberGutterWidening(items)
+	for _, item := range items {
+		// Render bullet
+		c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+		c.setTextFont("", 18*scale)
+		var textX, extra float64
+		if item.Ordered {
+			extra = widen[item.Depth]
+			textX = c.renderItemNumber(item.Number, item.Depth, extra, y, lineHeight)
+		} else {
+			textX = c.renderBullet(item.Depth, y, lineHeight)
+		}
+
+		// Render formatted text
+		y = c.renderFormattedText(item.Fragments, textX, y, c.listTextWidth(item.Depth)-extra, lineHeight)
+		y += 3 * scale
+	}
+
+	return y + 6*scale
+}
+
+// defaultListBullets are the bullet glyphs for list depths 0, 1 and 2+
+var defaultListBullets = []string{"•", "◦", "▪"}
+
+// defaultListIndent is the indent per list nesting level (mm)
+const defaultListIndent = 8.0
+
+// listNumberGap is the space between an ordered list number and its text (mm)
+const listNumberGap = 1.5
+
+// minListFontSize is the smallest font size WithListAutoFit shrinks lists to
+const minListFontSize = 10.0
+
+// fitListScale returns the largest font scale (in 1pt steps from 18pt, or
+// the WithAutoGlobalFontScale size) at which items fit between y and the
+// bottom of the content area
+func (c *Converter) fitListScale(items []listItem, y float64) float64 {
+	start := 18 * c.fontScale
+	for size := start; size > minListFontSize; size-- {
+		height := 6 * size / 18
+		c.setTextFont("", size)
+		widen := c.numberGutterWidening(items)
+		for _, item := range items {
+			width := c.listTextWidth(item.Depth)
+			if item.Ordered {
+				width -= widen[item.Depth]
+			}
+			_, h := c.measureFragments(item.Fragments, size, width)
+			height += h + 3*size/18
+		}
+		if y+height <= c.contentBottom() {
+			return size / 18
+		}
+	}
+	return min(start, minListFontSize) / 18
+}
+
+// listTextWidth returns the width available to the text of an item at depth
+func (c *Converter) listTextWidth(depth int) float64 {
+	return c.contentWidth() - 10 - float64(depth)*c.listIndent
+}
+
+// bulletFor returns the bullet glyph for a nesting depth; depths beyond the
+// configured glyphs reuse the last one
+func (c *Converter) bulletFor(depth int) string {
+	return c.listBullets[min(depth, len(c.listBullets)-1)]
+}
+
+// renderBullet draws the bullet of an item at depth at y and returns the x
+// where the item text starts. Each level is indented by the list indent; in
+// right-to-left mode bullets sit at the right margin.
+func (c *Converter) renderBullet(depth int, y, lineHeight float64) float64 {
+	runs := c.symbolRuns(c.bulletFor(depth))
+	setFont := func() { c.setTextFont("", 18*c.textScale) }
+	indent := float64(depth) * c.listIndent
+	if c.rtl {
+		c.drawRuns(runs, c.contentX()+c.contentWidth()-5-indent-c.measureRuns(runs, setFont), y, lineHeight, "", setFont)
+		return c.contentX()
+	}
+	c.drawRuns(runs, c.contentX()+5+indent, y, lineHeight, "", setFont)
+	return c.contentX() + 10 + indent
+}
+
+// numberGutterWidening returns, for each depth of items, how much wider than
+// the bullet gutter the gutter of ordered items must be for the widest item
+// number there, in the current font; 0 if the numbers fit
+func (c *Converter) numberGutterWidening(items []listItem) map[int]float64 {
+	widen := make(map[int]float64)
+	for _, item := range items {
+		if !item.Ordered {
+			continue
+		}
+		width := c.pdf.GetStringWidth(c.translator(strconv.Itoa(item.Number)+".")) + 2*c.pdf.GetCellMargin()
+		widen[item.Depth] = max(widen[item.Depth], width-(10-listNumberGap))
+	}
+	return widen
+}
+
+// renderItemNumber draws the number of an ordered list item at depth at y,
+// right-aligned in the bullet gutter widened by extra, and returns the x
+// where the item text starts, like renderBullet
+func (c *Converter) renderItemNumber(number, depth int, extra, y, lineHeight float64) float64 {
+	c.setTextFont("", 18*c.textScale)
+	label := c.translator(strconv.Itoa(number) + ".")
+	indent := float64(depth) * c.listIndent
+	gutter := 10 - listNumberGap + extra
+	if c.rtl {
+		c.pdf.SetXY(c.contentX()+c.contentWidth()-gutter-indent, y)
+		c.pdf.CellFormat(gutter, lineHeight, label, "", 0, "L", false, 0, "")
+		return c.contentX()
+	}
+	c.pdf.SetXY(c.contentX()+indent, y)
+	c.pdf.CellFormat(gutter, lineHeight, label, "", 0, "R", false, 0, "")
+	return c.contentX() + 10 + indent + extra
 }
 
 // renderHTMLCode renders HTML code block
 func (c *Converter) renderHTMLCode(html string, y float64) float64 {
+	codeText, language, ok := parseHTMLCodeBlock(html)
+	if !ok {
+		return y
+	}
+
+	return c.renderCodeText(codeText, c.codeLanguage(language, "", codeText), y)
+}
+
+// parseHTMLCodeBlock extracts the decoded source text and language of a
+// <pre><code> block ("" if it has no language class). ok is false if html
+// contains no code block.
+func parseHTMLCodeBlock(html string) (codeText, language string, ok bool) {
 	// Extract code content - use (?s) flag to make . match newlines
 	// Updated regex to handle optional attributes in <code> tag
 	re := regexp.MustCompile(`(?s)<pre><code[^>]*>(.*?)</code></pre>`)
 	match := re.FindStringSubmatch(html)
 
 	if len(match) < 2 {
-		return y
+		return "", "", false
 	}
 
-	codeText := strings.TrimSpace(match[1])
+	codeText = trimBlankLines(match[1])
 
 	// Decode HTML entities (e.g., &quot; -> ", &lt; -> <, etc.)
 	codeText = decodeHTMLEntities(codeText)
@@ -185,21 +459,25 @@ func (c *Converter) renderHTMLCode(html string, y float64) float64 {
 	// by the present parser in markdown mode.
 	codeText = strings.ReplaceAll(codeText, "\u200C", "")
 
-	// Try to detect language from class attribute
-	language := "go" // default
+	// Fenced blocks nested in lists keep the list's indentation on every
+	// line; remove it while preserving relative indentation.
+	codeText = dedent(codeText)
+
+	// Take the language from the class attribute, if any
 	classRe := regexp.MustCompile(`<code class="language-(\w+)">`)
 	if classMatch := classRe.FindStringSubmatch(html); len(classMatch) > 1 {
 		language = classMatch[1]
 	}
 
-	// Highlight the code
-	tokens, err := c.highlightCode(codeText, language)
-	if err != nil {
-		// Fallback to plain rendering
-		return c.renderCodePlain(codeText, y)
-	}
+	return codeText, language, true
+}
 
-	return c.renderHighlightedCode(tokens, y)
+// quoteBlock is a paragraph or a code block inside a blockquote
+type quoteBlock struct {
+	html   string  // paragraph HTML ("" for code)
+	tokens []Token // highlighted code (nil for paragraphs or plain code)
+	code   string  // code text when highlighting failed
+	lines  int     // number of code lines
 }
 
 // renderHTMLBlockquote renders a Markdown blockquote (> text) as a styled block
@@ -211,78 +489,87 @@ func (c *Converter) renderHTMLBlockquote(html string, y float64) float64 {
 	}
 	inner := strings.TrimSpace(match[1])
 
-	// Extract paragraphs from inner content
-	paraRe := regexp.MustCompile(`(?s)<p>(.*?)</p>`)
-	paraMatches := paraRe.FindAllStringSubmatch(inner, -1)
+	// Extract paragraphs and code blocks from inner content
+	blockRe := regexp.MustCompile(`(?s)<pre><code.*?</code></pre>|<p>(.*?)</p>`)
+	blockMatches := blockRe.FindAllStringSubmatch(inner, -1)
 
-	var paragraphsHTML []string
-	if len(paraMatches) == 0 {
+	var blocks []quoteBlock
+	if len(blockMatches) == 0 {
 		// No <p> tags — treat whole inner content as one paragraph
 		text := stripHTMLTags(inner)
 		if t := strings.TrimSpace(text); t != "" {
-			paragraphsHTML = []string{t}
+			blocks = []quoteBlock{{html: t}}
 		}
 	} else {
-		for _, m := range paraMatches {
-			if len(m) > 1 {
-				if t := strings.TrimSpace(m[1]); t != "" {
-					paragraphsHTML = append(paragraphsHTML, t)
+		for _, m := range blockMatches {
+			if strings.HasPrefix(m[0], "<pre>") {
+				if block, ok := c.quoteCodeBlock(m[0]); ok {
+					blocks = append(blocks, block)
 				}
+			} else if t := strings.TrimSpace(m[1]); t != "" {
+				blocks = append(blocks, quoteBlock{html: t})
 			}
 		}
 	}
 
-	if len(paragraphsHTML) == 0 {
+	if len(blocks) == 0 {
 		return y
 	}
 
 	const (
-		borderWidth = 4.0  // mm
-		textX       = 28.0 // absolute X for text start (after left border)
-		textWidth   = 249.0
-		lineHeight  = 11.0
+		borderWidth = 4.0 // mm
+		textInset   = 8.0 // text offset from the block's left edge (after border)
 		paddingV    = 4.0 // vertical padding top and bottom
 		paraSpacing = 3.0 // spacing between paragraphs
 	)
+	lineHeight := 11 * c.fontScale
+	blockX := c.contentX()
+	textX := blockX + textInset
+	textWidth := c.contentWidth() - textInset
 
 	// Estimate total height using font metrics
-	c.setTextFont("", 18)
 	totalHeight := paddingV * 2
-	for i, paraHTML := range paragraphsHTML {
-		plainText := stripHTMLTags(paraHTML)
-		words := strings.Fields(plainText)
-		lineWidth := 0.0
-		lines := 1
-		for _, word := range words {
-			ww := c.pdf.GetStringWidth(c.translator(word + " "))
-			if lineWidth+ww > textWidth && lineWidth > 0 {
-				lines++
-				lineWidth = ww
-			} else {
-				lineWidth += ww
-			}
+	for i, block := range blocks {
+		if block.html == "" {
+			shown := min(block.lines, c.codeLineLimit())
+			totalHeight += float64(shown)*c.codeLineHeight() + 2*c.codePadV
+		} else {
+			c.setTextFont("", 18)
+			lines, _ := c.measureFormattedText(stripHTMLTags(block.html), 18*c.fontScale, textWidth)
+			totalHeight += float64(lines) * lineHeight
 		}
-		totalHeight += float64(lines) * lineHeight
-		if i < len(paragraphsHTML)-1 {
+		if i < len(blocks)-1 {
 			totalHeight += paraSpacing
 		}
 	}
 
 	// Draw background rectangle
 	c.pdf.SetFillColor(c.theme.BlockquoteBackground.R, c.theme.BlockquoteBackground.G, c.theme.BlockquoteBackground.B)
-	c.pdf.Rect(20, y, 257, totalHeight, "F")
+	c.pdf.Rect(blockX, y, c.contentWidth(), totalHeight, "F")
 
 	// Draw left border
 	c.pdf.SetFillColor(c.theme.BlockquoteBorder.R, c.theme.BlockquoteBorder.G, c.theme.BlockquoteBorder.B)
-	c.pdf.Rect(20, y, borderWidth, totalHeight, "F")
+	c.pdf.Rect(blockX, y, borderWidth, totalHeight, "F")
 
-	// Render paragraph text on top
+	// Render paragraphs and code blocks on top
 	textY := y + paddingV
-	for i, paraHTML := range paragraphsHTML {
-		fragments := parseHTMLFormatting(paraHTML)
-		c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
-		textY = c.renderFormattedText(fragments, textX, textY, textWidth, lineHeight)
-		if i < len(paragraphsHTML)-1 {
+	for i, block := range blocks {
+		if block.html == "" {
+			// Code blocks are indented like the quote text
+			c.codeIndent = textInset
+			if block.tokens != nil {
+				c.renderHighlightedCode(block.tokens, 1, textY)
+			} else {
+				c.renderCodePlain(block.code, 1, textY)
+			}
+			c.codeIndent = 0
+			textY += float64(min(block.lines, c.codeLineLimit()))*c.codeLineHeight() + 2*c.codePadV
+		} else {
+			fragments := c.parseFormatting(block.html)
+			c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+			textY = c.renderFormattedText(fragments, textX, textY, textWidth, lineHeight)
+		}
+		if i < len(blocks)-1 {
 			textY += paraSpacing
 		}
 	}
@@ -290,6 +577,23 @@ func (c *Converter) renderHTMLBlockquote(html string, y float64) float64 {
 	return y + totalHeight + 5
 }
 
+// quoteCodeBlock highlights a <pre><code> block found inside a blockquote
+func (c *Converter) quoteCodeBlock(html string) (quoteBlock, bool) {
+	codeText, language, ok := parseHTMLCodeBlock(html)
+	if !ok {
+		return quoteBlock{}, false
+	}
+	language = c.codeLanguage(language, "", codeText)
+	codeText = c.foldCode(c.expandCodeTabs(codeText, language))
+	tokens, err := c.highlightCode(codeText, language)
+	if err == nil {
+		return quoteBlock{tokens: tokens, lines: len(splitTokensIntoLines(tokens))}, true
+	}
+	c.warnf("syntax highlighting failed, rendering plain code: %v", err)
+	lines := strings.Count(normalizeCode(codeText), "\n") + 1
+	return quoteBlock{code: codeText, lines: lines}, true
+}
+
 // renderHTMLPlainText renders HTML as plain text (fallback)
 func (c *Converter) renderHTMLPlainText(html string, y float64) float64 {
 	text := stripHTMLTags(html)
@@ -299,11 +603,76 @@ func (c *Converter) renderHTMLPlainText(html string, y float64) float64 {
 		return y
 	}
 
-	c.setTextFont("", 18)
-	c.pdf.SetXY(20, y)
-	c.pdf.MultiCell(257, 9, c.translator(text), "", "L", false)
+	lineHeight := 9 * c.fontScale
+	c.setTextFont("", 18*c.fontScale)
+	n := c.drawText(c.contentX(), y, c.contentWidth(), lineHeight, text, "L")
 
-	return y + 12
+	return y + float64(n)*lineHeight + 3
+}
+
+// parseFormatting parses HTML text into fragments, turning bare URLs into
+// links when auto-linking is enabled
+func (c *Converter) parseFormatting(html string) []TextFragment {
+	fragments := c.smartenFragments(parseHTMLFormatting(html))
+	if c.autoLink {
+		fragments = linkifyFragments(fragments)
+	}
+	return c.expandIcons(fragments)
+}
+
+// bareURLRe matches an http(s) URL in plain text
+var bareURLRe = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// linkifyFragments splits plain-text fragments around bare URLs and marks the
+// URL parts as links. Fragments that already are links or inline code are
+// left untouched.
+func linkifyFragments(fragments []TextFragment) []TextFragment {
+	var result []TextFragment
+	for _, f := range fragments {
+		if f.URL != "" || f.Code {
+			result = append(result, f)
+			continue
+		}
+
+		last := 0
+		for _, loc := range bareURLRe.FindAllStringIndex(f.Text, -1) {
+			url := trimURLPunctuation(f.Text[loc[0]:loc[1]])
+			end := loc[0] + len(url)
+			if loc[0] > last {
+				plain := f
+				plain.Text = f.Text[last:loc[0]]
+				result = append(result, plain)
+			}
+			link := f
+			link.Text = url
+			link.URL = url
+			result = append(result, link)
+			last = end
+		}
+		if last < len(f.Text) {
+			rest := f
+			rest.Text = f.Text[last:]
+			result = append(result, rest)
+		}
+	}
+	return result
+}
+
+// trimURLPunctuation drops sentence punctuation that trails a bare URL,
+// keeping a closing parenthesis only if the URL contains an opening one
+func trimURLPunctuation(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,;:!?'", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
 }
 
 // parseHTMLFormatting parses HTML text and extracts fragments with formatting
@@ -317,9 +686,14 @@ func parseHTMLFormatting(html string) []TextFragment {
 	re := regexp.MustCompile(`([^<]+)|(<[^>]+>)`)
 	matches := re.FindAllString(html, -1)
 
-	bold := false
-	italic := false
-	code := false
+	// Nesting depths, so <b>a <b>b</b> c</b> keeps "c" bold and a stray
+	// closing tag cannot go negative
+	bold, italic, code, kbd, mark := 0, 0, 0, 0, 0
+	closeTag := func(depth *int) {
+		if *depth > 0 {
+			*depth--
+		}
+	}
 	currentURL := ""
 	var currentText strings.Builder
 
@@ -328,10 +702,12 @@ func parseHTMLFormatting(html string) []TextFragment {
 			text := decodeHTMLEntities(currentText.String())
 			fragments = append(fragments, TextFragment{
 				Text:   text,
-				Bold:   bold,
-				Italic: italic,
-				Code:   code,
+				Bold:   bold > 0,
+				Italic: italic > 0,
+				Code:   code > 0,
 				URL:    currentURL,
+				Kbd:    kbd > 0,
+				Mark:   mark > 0,
 			})
 			currentText.Reset()
 		}
@@ -348,20 +724,29 @@ func parseHTMLFormatting(html string) []TextFragment {
 			lowerMatch := strings.ToLower(match)
 			switch {
 			case lowerMatch == "<strong>" || lowerMatch == "<b>":
-				bold = true
+				bold++
 			case lowerMatch == "</strong>" || lowerMatch == "</b>":
-				bold = false
+				closeTag(&bold)
 			case lowerMatch == "<em>" || lowerMatch == "<i>":
-				italic = true
+				italic++
 			case lowerMatch == "</em>" || lowerMatch == "</i>":
-				italic = false
+				closeTag(&italic)
 			case lowerMatch == "<code>":
-				code = true
+				code++
 			case lowerMatch == "</code>":
-				code = false
+				closeTag(&code)
+			case lowerMatch == "<kbd>":
+				kbd++
+			case lowerMatch == "</kbd>":
+				closeTag(&kbd)
+			case lowerMatch == "<mark>":
+				mark++
+			case lowerMatch == "</mark>":
+				closeTag(&mark)
 			case strings.HasPrefix(lowerMatch, "<a "):
 				if m := hrefRe.FindStringSubmatch(match); len(m) > 1 {
-					currentURL = m[1]
+					// Attribute values are entity-encoded (?a=1&amp;b=2)
+					currentURL = decodeHTMLEntities(m[1])
 				}
 			case lowerMatch == "</a>":
 				currentURL = ""
@@ -376,88 +761,140 @@ func parseHTMLFormatting(html string) []TextFragment {
 	return fragments
 }
 
+// italicSkew is the skew angle for simulated italics (degrees)
+const italicSkew = 12.0
+
 // renderFormattedText renders text with bold, italic formatting and clickable links
-// Bold/italic — visual simulation (Helvetica has no B/I variants for Cyrillic)
+// Bold and italic use the font variants; styles a font lacks (italic code,
+// custom body fonts) are simulated by skewing and by drawing twice.
+// The styles compose: bold code uses the bold monospace font, and a code link
+// keeps the code background with link color and underline.
 func (c *Converter) renderFormattedText(fragments []TextFragment, x, y, maxWidth, lineHeight float64) float64 {
-	const (
-		boldOffset = 0.2  // offset for bold simulation (mm)
-		italicSkew = 12.0 // skew angle for italic simulation (degrees)
-	)
+	const boldOffset = 0.2 // offset for bold simulation (mm)
 	currentX := x
 	currentY := y
 
-	c.setTextFont("", 18)
+	c.setTextFont("", 18*c.textScale)
+	notes := c.linkFootnotes(fragments)
 
-	for _, fragment := range fragments {
+	for i, fragment := range fragments {
 		isLink := fragment.URL != ""
 		isCode := fragment.Code
+		setFont := c.fragmentFont(fragment)
+
+		// Code has a bold but no italic font, keys neither
+		realStyles := c.realTextStyles() && !isCode && !fragment.Kbd
+		simItalic := fragment.Italic && (isCode || !realStyles)
+		simBold := fragment.Bold && !isCode && !realStyles
+
+		if fragment.Icon != "" {
+			if w, h, ok := c.iconSize(fragment.Icon); ok {
+				spaceWidth := c.measureRuns([]textRun{{Text: " "}}, setFont)
+				if currentX+w > x+maxWidth && currentX > x {
+					currentY += lineHeight
+					currentX = x
+				}
+				drawX := currentX
+				if c.rtl {
+					drawX = x + maxWidth - (currentX - x) - w
+				}
+				c.drawIcon(fragment.Icon, drawX, currentY, w, h, lineHeight)
+				currentX += w + spaceWidth
+				continue
+			}
+		}
 
-		if isCode {
-			c.setCodeFont("", 16)
-			c.pdf.SetTextColor(c.theme.InlineCodeText.R, c.theme.InlineCodeText.G, c.theme.InlineCodeText.B)
-		} else if isLink {
+		// Link color wins over the code, key and highlight text colors
+		switch {
+		case isLink:
 			c.pdf.SetTextColor(c.theme.LinkColor.R, c.theme.LinkColor.G, c.theme.LinkColor.B)
+		case isCode || fragment.Kbd:
+			c.pdf.SetTextColor(c.theme.InlineCodeText.R, c.theme.InlineCodeText.G, c.theme.InlineCodeText.B)
+		case fragment.Mark:
+			c.pdf.SetTextColor(c.theme.MarkText.R, c.theme.MarkText.G, c.theme.MarkText.B)
 		}
 
 		words := strings.Fields(fragment.Text)
-		for _, word := range words {
-			translatedWord := c.translator(word + " ")
-			wordWidth := c.pdf.GetStringWidth(translatedWord)
+		if fragment.Kbd && len(words) > 0 {
+			// A key cap is not broken across lines
+			words = []string{strings.Join(words, " ")}
+		}
+		for j, word := range words {
+			runs := c.symbolRuns(c.displayText(word + " "))
+			wordWidth := c.measureRuns(runs, setFont)
+
+			// The footnote mark follows the last word of a link
+			note := 0
+			markWidth := 0.0
+			if j == len(words)-1 {
+				if note = notes[i]; note > 0 {
+					markWidth = c.footnoteMarkWidth(note, 18*c.textScale)
+				}
+			}
 
-			if currentX+wordWidth > x+maxWidth && currentX > x {
+			if currentX+wordWidth+markWidth > x+maxWidth && currentX > x {
 				currentY += lineHeight
 				currentX = x
 			}
 
-			if isCode {
+			// Words are laid out from the right margin in right-to-left mode
+			drawX := currentX
+			if c.rtl {
+				drawX = x + maxWidth - (currentX - x) - wordWidth - markWidth
+			}
+
+			switch {
+			case isCode:
 				c.pdf.SetFillColor(c.theme.InlineCodeBackground.R, c.theme.InlineCodeBackground.G, c.theme.InlineCodeBackground.B)
-				c.pdf.Rect(currentX, currentY+0.5, wordWidth, lineHeight-1, "F")
-				c.pdf.SetTextColor(c.theme.InlineCodeText.R, c.theme.InlineCodeText.G, c.theme.InlineCodeText.B)
+				c.pdf.Rect(drawX, currentY+0.5, wordWidth, lineHeight-1, "F")
+			case fragment.Kbd:
+				c.drawKeyCap(runs, drawX, currentY, lineHeight, setFont)
+			case fragment.Mark:
+				c.pdf.SetFillColor(c.theme.MarkBackground.R, c.theme.MarkBackground.G, c.theme.MarkBackground.B)
+				c.pdf.Rect(drawX, currentY+0.5, wordWidth, lineHeight-1, "F")
 			}
 
 			drawWord := func() {
-				c.pdf.SetXY(currentX, currentY)
+				// A non-empty link makes the cell area a clickable hyperlink
+				c.drawRuns(runs, drawX, currentY, lineHeight, fragment.URL, setFont)
 				if isLink {
-					// CellFormat with linkStr makes the cell area a clickable hyperlink
-					c.pdf.CellFormat(wordWidth, lineHeight, translatedWord, "", 0, "L", false, 0, fragment.URL)
 					// Draw underline manually
 					c.pdf.SetDrawColor(c.theme.LinkColor.R, c.theme.LinkColor.G, c.theme.LinkColor.B)
 					c.pdf.SetLineWidth(0.2)
 					underlineY := currentY + lineHeight - 1
-					c.pdf.Line(currentX, underlineY, currentX+wordWidth, underlineY)
-				} else {
-					c.pdf.Cell(wordWidth, lineHeight, translatedWord)
+					c.pdf.Line(drawX, underlineY, drawX+wordWidth, underlineY)
 				}
 			}
 
-			if fragment.Italic {
+			if simItalic {
 				c.pdf.TransformBegin()
-				c.pdf.TransformSkew(italicSkew, 0, currentX, currentY)
+				c.pdf.TransformSkew(italicSkew, 0, drawX, currentY)
 			}
 
-			if fragment.Bold {
+			if simBold {
 				drawWord()
-				c.pdf.SetXY(currentX+boldOffset, currentY)
-				if isLink {
-					c.pdf.CellFormat(wordWidth, lineHeight, translatedWord, "", 0, "L", false, 0, fragment.URL)
-				} else {
-					c.pdf.Cell(wordWidth, lineHeight, translatedWord)
-				}
+				c.drawRuns(runs, drawX+boldOffset, currentY, lineHeight, fragment.URL, setFont)
 			} else {
 				drawWord()
 			}
 
-			if fragment.Italic {
+			if simItalic {
 				c.pdf.TransformEnd()
 			}
 
-			currentX += wordWidth
+			if note > 0 {
+				spaceWidth := c.measureRuns([]textRun{{Text: " "}}, setFont)
+				c.drawFootnoteMark(note, drawX+wordWidth-spaceWidth, currentY, lineHeight, 18*c.textScale)
+				setFont()
+			}
+
+			currentX += wordWidth + markWidth
 		}
 
-		if isCode {
-			c.setTextFont("", 18)
-			c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
-		} else if isLink {
+		if isCode || fragment.Kbd {
+			c.setTextFont("", 18*c.textScale)
+		}
+		if isCode || isLink || fragment.Kbd || fragment.Mark {
 			// Restore normal text color
 			c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
 		}
@@ -466,6 +903,79 @@ func (c *Converter) renderFormattedText(fragments []TextFragment, x, y, maxWidth
 	return currentY + lineHeight
 }
 
+// fragmentFont returns a function selecting the font of a text fragment
+func (c *Converter) fragmentFont(fragment TextFragment) func() {
+	if fragment.Code {
+		style := ""
+		if fragment.Bold {
+			style = "B"
+		}
+		return func() { c.setCodeFont(style, 16*c.textScale) }
+	}
+	if fragment.Kbd {
+		return func() { c.setCodeFont("", 13*c.textScale) }
+	}
+	style := ""
+	if fragment.Bold {
+		style += "B"
+	}
+	if fragment.Italic {
+		style += "I"
+	}
+	return func() { c.setTextFont(style, 18*c.textScale) }
+}
+
+// drawKeyCap draws the rounded, bordered box of a <kbd> key around runs
+// (which end with the space after the key) at (x, y) in a line of lineHeight
+func (c *Converter) drawKeyCap(runs []textRun, x, y, lineHeight float64, setFont func()) {
+	const padV = 1.5 // box inset from the line top and bottom (mm)
+	w := c.measureRuns(runs, setFont) - c.measureRuns([]textRun{{Text: " "}}, setFont)
+	c.pdf.SetFillColor(c.theme.KbdBackground.R, c.theme.KbdBackground.G, c.theme.KbdBackground.B)
+	c.pdf.SetDrawColor(c.theme.KbdBorder.R, c.theme.KbdBorder.G, c.theme.KbdBorder.B)
+	c.pdf.SetLineWidth(0.3)
+	c.pdf.RoundedRect(x-0.5, y+padV, w+1, lineHeight-2*padV, 1, "1234", "FD")
+}
+
+// measureFragments is measureText for formatted fragments: it returns how
+// many lines renderFormattedText lays them out on in width at font size (pt),
+// measuring each fragment in its own font and icons at their drawn width,
+// and the height of those lines
+func (c *Converter) measureFragments(fragments []TextFragment, size, width float64) (lines int, height float64) {
+	prevScale := c.textScale
+	c.textScale = size / 18
+	defer func() { c.textScale = prevScale }()
+
+	lines = 1
+	lineWidth := 0.0
+	wrap := func(w float64) {
+		if lineWidth+w > width && lineWidth > 0 {
+			lines++
+			lineWidth = 0
+		}
+		lineWidth += w
+	}
+	for _, fragment := range fragments {
+		setFont := c.fragmentFont(fragment)
+		if fragment.Icon != "" {
+			if w, _, ok := c.iconSize(fragment.Icon); ok {
+				wrap(w)
+				lineWidth += c.measureRuns([]textRun{{Text: " "}}, setFont)
+				continue
+			}
+		}
+		words := strings.Fields(fragment.Text)
+		if fragment.Kbd && len(words) > 0 {
+			words = []string{strings.Join(words, " ")}
+		}
+		for _, word := range words {
+			runs, _ := splitSymbolRuns(c.displayText(word + " "))
+			wrap(c.measureRuns(runs, setFont))
+		}
+	}
+	c.setTextFont("", size)
+	return lines, float64(lines) * size * textLeading
+}
+
 // stripHTMLTags removes HTML tags from string
 func stripHTMLTags(html string) string {
 	// Remove HTML tags
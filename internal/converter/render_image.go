@@ -1,8 +1,10 @@
 package converter
 
 import (
-	"fmt"
+	"bytes"
+	"io"
 	"math"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -13,71 +15,242 @@ import (
 )
 
 const (
-	imgContentX      = 20.0  // left content margin (mm)
-	imgContentWidth  = 257.0 // available content width (mm)
-	imgContentBottom = 190.0 // bottom boundary of slide content (mm)
+	pxToMM          = 25.4 / 96 // converts a length in CSS pixels (96 per inch) to millimeters
+	imageGap        = 5.0       // space below an image (mm)
+	captionFontSize = 14.0      // image caption font size (pt)
+	captionLine     = 6.5       // image caption line height (mm)
+	captionGap      = 4.0       // space below an image caption (mm)
 )
 
-// renderImage renders a present.Image element (.image directive, legacy format).
+// imageSize is the size requested for an image (mm); a zero side follows
+// the image's aspect ratio, and a zero size fits the image to the space left
+type imageSize struct {
+	W, H float64
+}
+
+// renderImage renders a present.Image element (.image directive, legacy
+// format), at the width and height given in pixels on the directive, if any
 func (c *Converter) renderImage(img present.Image, y float64) float64 {
+	size := imageSize{W: float64(img.Width) * pxToMM, H: float64(img.Height) * pxToMM}
+	if isHTTPURL(img.URL) {
+		return c.renderRemoteImage(img.URL, "", size, y)
+	}
 	imagePath := img.URL
 	if !filepath.IsAbs(imagePath) {
 		imagePath = filepath.Join(c.slideDir, imagePath)
 	}
-	return c.renderImageFile(imagePath, y)
+	return c.renderImageFile(imagePath, "", size, y)
 }
 
+var (
+	// imgAltRe matches the alt attribute of an <img> tag
+	imgAltRe = regexp.MustCompile(`(?i)\salt=["']([^"']*)["']`)
+
+	// imgSrcRe matches the src attribute of an <img> tag
+	imgSrcRe = regexp.MustCompile(`(?i)<img\s[^>]*src=["']([^"']+)["']`)
+)
+
 // renderHTMLImage renders an <img> HTML tag from markdown-converted content.
 func (c *Converter) renderHTMLImage(imgHTML string, y float64) float64 {
-	srcRe := regexp.MustCompile(`(?i)src=["']([^"']+)["']`)
-	match := srcRe.FindStringSubmatch(imgHTML)
+	match := imgSrcRe.FindStringSubmatch(imgHTML)
 	if len(match) < 2 {
 		return y
 	}
 	imagePath := match[1]
+	alt := ""
+	if m := imgAltRe.FindStringSubmatch(imgHTML); m != nil {
+		alt = decodeHTMLEntities(m[1])
+	}
+	if isHTTPURL(imagePath) {
+		return c.renderRemoteImage(decodeHTMLEntities(imagePath), alt, imageSize{}, y)
+	}
 	if !filepath.IsAbs(imagePath) {
 		imagePath = filepath.Join(c.slideDir, imagePath)
 	}
-	return c.renderImageFile(imagePath, y)
+	return c.renderImageFile(imagePath, alt, imageSize{}, y)
 }
 
 // renderImageFile places an image from a file path into the PDF, centered
-// horizontally and scaled to fit within the remaining slide content area.
-func (c *Converter) renderImageFile(imagePath string, y float64) float64 {
-	if _, err := os.Stat(imagePath); err != nil {
-		if !c.quiet {
-			fmt.Fprintf(os.Stderr, "Warning: slide %d %q: image not found: %s\n",
-				c.currentSlideNumber, c.currentSlideTitle, imagePath)
+// horizontally at the requested size or scaled to fit within the remaining
+// slide content area, and records it with its alt text.
+func (c *Converter) renderImageFile(imagePath, alt string, size imageSize, y float64) float64 {
+	if c.imagePlaceholder {
+		if _, err := os.Stat(imagePath); err != nil {
+			c.warnf("image not found: %s", imagePath)
+			return c.renderImagePlaceholder(imagePath, y)
 		}
+	}
+
+	// The alt text is drawn as a caption below the image; it and a .caption
+	// that follows the image need room
+	captions := c.captionHeight(alt) + c.captionHeight(c.captionBelow)
+	newY := c.placeImage(imagePath, size, y, c.contentBottom()-captions)
+	if newY != y {
+		c.images = append(c.images, ImageInfo{
+			Slide: c.currentSlideNumber,
+			Title: c.currentSlideTitle,
+			Path:  imagePath,
+			Alt:   alt,
+		})
+		newY = c.renderCaption(alt, newY)
+	}
+	return newY
+}
+
+// captionHeight returns the height renderCaption takes for text
+func (c *Converter) captionHeight(text string) float64 {
+	if text == "" {
+		return 0
+	}
+	c.setTextFont("I", captionFontSize*c.fontScale)
+	lines := c.wrapText(c.smarten(text), c.contentWidth()-2*c.pdf.GetCellMargin())
+	return float64(len(lines))*captionLine*c.fontScale + captionGap
+}
+
+// renderCaption draws an image caption (Markdown alt text or .caption)
+// centered at y in smaller, italic text and returns the y below it
+func (c *Converter) renderCaption(text string, y float64) float64 {
+	if text == "" {
 		return y
 	}
+	c.setTextFont("I", captionFontSize*c.fontScale)
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+	lineHeight := captionLine * c.fontScale
+	for _, line := range c.wrapText(c.smarten(text), c.contentWidth()-2*c.pdf.GetCellMargin()) {
+		// Without an italic font, slanted about the baseline like italic fragments
+		if !c.realTextStyles() {
+			c.pdf.TransformBegin()
+			c.pdf.TransformSkew(italicSkew, 0, c.contentX()+c.contentWidth()/2, y+lineHeight)
+		}
+		c.pdf.SetXY(c.contentX(), y)
+		c.pdf.CellFormat(c.contentWidth(), lineHeight, c.translator(c.displayText(line)), "", 0, "C", false, 0, "")
+		if !c.realTextStyles() {
+			c.pdf.TransformEnd()
+		}
+		y += lineHeight
+	}
+	return y + captionGap
+}
 
-	ext := strings.ToUpper(strings.TrimPrefix(filepath.Ext(imagePath), "."))
-	if ext == "JPG" {
-		ext = "JPEG"
+// backgroundStyleRe matches the style the present parser records for a
+// .background directive
+var backgroundStyleRe = regexp.MustCompile(`^background-image: url\('(.*)'\)$`)
+
+// backgroundImage returns the path of the section's .background image,
+// relative to the slide file, or "" if it has none
+func (c *Converter) backgroundImage(section present.Section) string {
+	ref := backgroundRef(section)
+	if ref == "" || filepath.IsAbs(ref) {
+		return ref
 	}
-	switch ext {
-	case "JPEG", "PNG", "GIF":
-	default:
-		if !c.quiet {
-			fmt.Fprintf(os.Stderr, "Warning: slide %d %q: unsupported image format %q: %s\n",
-				c.currentSlideNumber, c.currentSlideTitle, ext, imagePath)
+	return filepath.Join(c.slideDir, ref)
+}
+
+// backgroundRef returns the section's .background image as written, or ""
+// if it has none
+func backgroundRef(section present.Section) string {
+	for _, style := range section.Styles {
+		if m := backgroundStyleRe.FindStringSubmatch(style); m != nil {
+			return m[1]
 		}
-		return y
 	}
+	return ""
+}
 
+// renderBackgroundImage covers the slide region with an image, scaled to
+// fill it and cropped to the region. Missing or unsupported files are
+// skipped with a warning, leaving the theme background.
+func (c *Converter) renderBackgroundImage(imagePath string) {
+	if imagePath == "" {
+		return
+	}
+	if _, err := os.Stat(imagePath); err != nil {
+		c.warnf("background image not found: %s", imagePath)
+		return
+	}
+	ext, ok := imageType(imagePath)
+	if !ok {
+		c.warnf("unsupported image format %q: %s", ext, imagePath)
+		return
+	}
 	info := c.pdf.RegisterImageOptions(imagePath, gofpdf.ImageOptions{ImageType: ext})
 	if c.pdf.Err() {
-		if !c.quiet {
-			fmt.Fprintf(os.Stderr, "Warning: slide %d %q: failed to load image %s: %v\n",
-				c.currentSlideNumber, c.currentSlideTitle, imagePath, c.pdf.Error())
+		c.warnf("failed to load image %s: %v", imagePath, c.pdf.Error())
+		c.pdf.ClearError()
+		return
+	}
+	if info.Width() <= 0 || info.Height() <= 0 {
+		return
+	}
+
+	r := c.region
+	scale := math.Max(r.W/info.Width(), r.H/info.Height())
+	w, h := info.Width()*scale, info.Height()*scale
+	c.pdf.ClipRect(r.X, r.Y, r.W, r.H, false)
+	opts := gofpdf.ImageOptions{ImageType: ext, AllowNegativePosition: true}
+	c.pdf.ImageOptions(imagePath, r.X+(r.W-w)/2, r.Y+(r.H-h)/2, w, h, false, opts, 0, "")
+	c.pdf.ClipEnd()
+	c.debugf("background %s: %.0fx%.0f scaled by %.2f", filepath.Base(imagePath), info.Width(), info.Height(), scale)
+
+	c.images = append(c.images, ImageInfo{
+		Slide: c.currentSlideNumber,
+		Title: c.currentSlideTitle,
+		Path:  imagePath,
+	})
+}
+
+// imagePlaceholderHeight is the height of a WithMissingImagePlaceholder box (mm)
+const imagePlaceholderHeight = 40.0
+
+// renderImagePlaceholder draws a dashed box across the content width naming
+// a missing image
+func (c *Converter) renderImagePlaceholder(imagePath string, y float64) float64 {
+	x, w := c.contentX(), c.contentWidth()
+
+	c.pdf.SetDrawColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+	c.pdf.SetLineWidth(0.4)
+	c.pdf.SetDashPattern([]float64{3, 2}, 0)
+	c.pdf.Rect(x, y, w, imagePlaceholderHeight, "D")
+	c.pdf.SetDashPattern([]float64{}, 0)
+
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+	c.setTextFont("", 14)
+	c.drawText(x, y+imagePlaceholderHeight/2-4, w, 8, "image not found: "+filepath.Base(imagePath), "C")
+
+	return y + imagePlaceholderHeight + 5
+}
+
+// placeImage places an image from a file path into the PDF, centered
+// horizontally at the requested size or scaled to fit between y and bottom.
+// A requested size that does not fit is scaled down to fit.
+func (c *Converter) placeImage(imagePath string, size imageSize, y, bottom float64) float64 {
+	if _, err := os.Stat(imagePath); err != nil {
+		c.warnf("image not found: %s", imagePath)
+		return y
+	}
+
+	ext, ok := imageType(imagePath)
+	if ext == "SVG" {
+		png, err := c.localImage(imagePath, c.rasterizeSVG)
+		if err != nil {
+			c.warnf("failed to rasterize SVG %s: %v", imagePath, err)
+			return y
 		}
+		imagePath, ext = png, "PNG"
+	} else if !ok {
+		c.warnf("unsupported image format %q: %s", ext, imagePath)
+		return y
+	}
+
+	info := c.pdf.RegisterImageOptions(imagePath, gofpdf.ImageOptions{ImageType: ext})
+	if c.pdf.Err() {
+		c.warnf("failed to load image %s: %v", imagePath, c.pdf.Error())
 		c.pdf.ClearError()
 		return y
 	}
 
-	maxH := imgContentBottom - y
-	if maxH <= 5 {
+	maxH := bottom - y - imageGap
+	if maxH <= 0 {
 		return y
 	}
 
@@ -85,17 +258,194 @@ func (c *Converter) renderImageFile(imagePath string, y float64) float64 {
 	imgH := info.Height()
 
 	var w, h float64
-	if imgW > 0 && imgH > 0 {
-		scale := math.Min(imgContentWidth/imgW, maxH/imgH)
+	if (size.W > 0 || size.H > 0) && imgW > 0 && imgH > 0 {
+		w, h = size.W, size.H
+		if w == 0 {
+			w = h * imgW / imgH
+		} else if h == 0 {
+			h = w * imgH / imgW
+		}
+		scale := math.Min(1, math.Min(c.contentWidth()/w, maxH/h))
+		w, h = w*scale, h*scale
+		c.debugf("image %s: requested %.0fx%.0fmm, placed at %.0fx%.0fmm", filepath.Base(imagePath), size.W, size.H, w, h)
+	} else if imgW > 0 && imgH > 0 {
+		scale := math.Min(c.contentWidth()/imgW, maxH/imgH)
 		w = imgW * scale
 		h = imgH * scale
+		c.debugf("image %s: %.0fx%.0f scaled by %.2f to %.0fx%.0fmm", filepath.Base(imagePath), imgW, imgH, scale, w, h)
 	} else {
-		w = imgContentWidth
+		w = c.contentWidth()
 		h = 0
 	}
 
-	x := imgContentX + (imgContentWidth-w)/2
+	x := c.contentX() + (c.contentWidth()-w)/2
 	c.pdf.ImageOptions(imagePath, x, y, w, h, false, gofpdf.ImageOptions{ImageType: ext}, 0, "")
 
-	return y + h + 5
+	return y + h + imageGap
+}
+
+// imageType returns the gofpdf image type of a file and whether gofpdf can
+// embed it. The type is sniffed from the file content, so mislabeled files
+// work; the extension is used only when the content is not recognized.
+func imageType(path string) (string, bool) {
+	if f, err := os.Open(path); err == nil {
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(f, head)
+		f.Close()
+		switch http.DetectContentType(head[:n]) {
+		case "image/png":
+			return "PNG", true
+		case "image/jpeg":
+			return "JPEG", true
+		case "image/gif":
+			return "GIF", true
+		}
+	}
+
+	ext := strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))
+	if ext == "JPG" {
+		ext = "JPEG"
+	}
+	switch ext {
+	case "JPEG", "PNG", "GIF":
+		return ext, true
+	}
+	return ext, false
+}
+
+// imageFile is a local copy made of an image for embedding (a download or a
+// rasterized SVG), or the error that prevented it
+type imageFile struct {
+	path string
+	err  error
+}
+
+// prepareImageFiles resets the image copies of the previous conversion. The
+// returned function removes the files made during this one.
+func (c *Converter) prepareImageFiles() func() {
+	c.imageFiles = make(map[string]imageFile)
+	return func() {
+		if c.imageDir != "" {
+			os.RemoveAll(c.imageDir)
+			c.imageDir = ""
+		}
+	}
+}
+
+// localImage returns the local copy of the image ref, made by create on
+// first use. Failures are remembered too, so they are not retried.
+func (c *Converter) localImage(ref string, create func(string) (string, error)) (string, error) {
+	f, ok := c.imageFiles[ref]
+	if !ok {
+		f.path, f.err = create(ref)
+		if c.imageFiles != nil {
+			c.imageFiles[ref] = f
+		}
+	}
+	return f.path, f.err
+}
+
+// imageTempFile creates a file for an image copy in the conversion's temp
+// directory, which is created on first use
+func (c *Converter) imageTempFile(pattern string) (*os.File, error) {
+	if c.imageDir == "" {
+		dir, err := os.MkdirTemp("", "present2pdf-images-*")
+		if err != nil {
+			return nil, err
+		}
+		c.imageDir = dir
+	}
+	return os.CreateTemp(c.imageDir, pattern)
+}
+
+// imageRefs returns the image paths referenced by slide elements, including
+// the poster images of iframes and the .background images of sections
+func imageRefs(elems []present.Elem, posters map[string]string) []string {
+	var refs []string
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case present.Image:
+			refs = append(refs, e.URL)
+		case present.HTML:
+			for _, m := range imgSrcRe.FindAllStringSubmatch(string(e.HTML), -1) {
+				refs = append(refs, decodeHTMLEntities(m[1]))
+			}
+		case present.Iframe:
+			if poster, ok := posters[e.URL]; ok {
+				refs = append(refs, poster)
+			}
+		case present.Section:
+			if ref := backgroundRef(e); ref != "" {
+				refs = append(refs, ref)
+			}
+			refs = append(refs, imageRefs(e.Elem, posters)...)
+		}
+	}
+	return refs
+}
+
+// isRemoteURL reports whether an image reference points to the network or
+// embeds its data rather than naming a local file
+func isRemoteURL(ref string) bool {
+	lower := strings.ToLower(ref)
+	for _, prefix := range []string{"http://", "https://", "//", "data:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// iframePosterRe matches a poster=path argument on an .iframe directive line
+var iframePosterRe = regexp.MustCompile(`^(\s*\.iframe\s+(\S+).*?)\s+poster=(\S+)(.*)$`)
+
+// extractIframePosters strips the poster=path argument from .iframe lines
+// (which the present parser would reject) and returns a map from iframe URL
+// to poster image path.
+func extractIframePosters(content []byte) (map[string]string, []byte) {
+	if !bytes.Contains(content, []byte("poster=")) {
+		return nil, content
+	}
+
+	posters := make(map[string]string)
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		m := iframePosterRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		posters[m[2]] = m[3]
+		lines[i] = m[1] + m[4]
+	}
+	return posters, []byte(strings.Join(lines, "\n"))
+}
+
+// renderIframe renders an .iframe directive. A PDF cannot embed a live page,
+// so the poster image (if given via poster=path) is shown instead, followed by
+// a "Live demo" caption linking to the iframe URL.
+func (c *Converter) renderIframe(iframe present.Iframe, y float64) float64 {
+	const captionHeight = 9.0
+
+	if poster, ok := c.iframePosters[iframe.URL]; ok {
+		if !filepath.IsAbs(poster) {
+			poster = filepath.Join(c.slideDir, poster)
+		}
+		y = c.placeImage(poster, imageSize{}, y, c.contentBottom()-captionHeight)
+	}
+
+	label := c.translator("Live demo: " + iframe.URL)
+	c.setTextFont("", 14)
+	labelWidth := c.pdf.GetStringWidth(label)
+	x := c.contentX() + (c.contentWidth()-labelWidth)/2
+
+	c.pdf.SetTextColor(c.theme.LinkColor.R, c.theme.LinkColor.G, c.theme.LinkColor.B)
+	c.pdf.SetXY(x, y)
+	c.linkCell(labelWidth, captionHeight, label, iframe.URL)
+
+	c.pdf.SetDrawColor(c.theme.LinkColor.R, c.theme.LinkColor.G, c.theme.LinkColor.B)
+	c.pdf.SetLineWidth(0.2)
+	c.pdf.Line(x, y+captionHeight-1, x+labelWidth, y+captionHeight-1)
+
+	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+	return y + captionHeight + 5
 }
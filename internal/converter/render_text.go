@@ -16,34 +16,91 @@ func (c *Converter) renderText(text present.Text, y float64) float64 {
 		return c.renderMarkdownCodeBlock(content, y)
 	}
 
-	// Regular text rendering
-	c.setTextFont("", 21)
-	c.pdf.SetXY(20, y)
+	// For regular text, join with spaces unless WithPreserveLineBreaks keeps
+	// the author's lines; preformatted text keeps its punctuation like code
+	sep := " "
+	if c.preserveLineBreaks {
+		sep = "\n"
+	}
+	content = strings.Join(text.Lines, sep)
+	if !text.Pre {
+		content = c.smarten(content)
+	}
 
-	// For regular text, join with spaces
-	content = strings.Join(text.Lines, " ")
-	c.pdf.MultiCell(257, 11, c.translator(content), "", "L", false)
+	// Right-to-left text needs word-by-word layout, one source line at a time
+	// when line breaks are kept
+	if c.rtl {
+		for _, line := range strings.Split(content, "\n") {
+			fragments := []TextFragment{{Text: line}}
+			y = c.renderFormattedText(fragments, c.contentX(), y, c.contentWidth(), 11*c.fontScale)
+		}
+		return y + 4
+	}
 
-	return y + 15
+	// Regular text rendering
+	lineHeight := 11 * c.fontScale
+	c.setTextFont("", 21*c.fontScale)
+	n := c.drawText(c.contentX(), y, c.contentWidth(), lineHeight, content, "L")
+
+	return y + float64(n)*lineHeight + 4
 }
 
 // renderList renders list element
 func (c *Converter) renderList(list present.List, y float64) float64 {
 	c.setTextFont("", 18)
 
+	// Right-to-left, auto-fit, scaled, custom-bullet and nested lists need
+	// word-by-word layout
+	if c.rtl || c.listAutoFit || c.fontScale != 1 || c.bulletFor(0) != "•" || nestedList(list) {
+		items := c.legacyListItems(list)
+		return c.renderListItems(items, y)
+	}
+
 	bullet := "• "
 	for _, item := range list.Bullet {
-		c.pdf.SetXY(25, y)
-
-		fullText := bullet + item
+		fullText := bullet + c.smarten(item)
 
-		c.pdf.MultiCell(247, 9, c.translator(fullText), "", "L", false)
-		y += 12
+		n := c.drawText(c.contentX()+5, y, c.contentWidth()-10, 9, fullText, "L")
+		y += float64(n)*9 + 3
 	}
 
 	return y + 6
 }
 
+// nestedList reports whether a legacy list has sub-items. The present
+// parser appends an indented "  - sub" line to the item above it, so
+// sub-items are continuation lines starting with "- ".
+func nestedList(list present.List) bool {
+	for _, item := range list.Bullet {
+		if strings.Contains(item, "\n- ") {
+			return true
+		}
+	}
+	return false
+}
+
+// legacyListItems turns the items of a legacy list into list items, with
+// the "- " continuation lines of an item as its sub-items at depth 1. Other
+// continuation lines carry on the text of the item before them.
+func (c *Converter) legacyListItems(list present.List) []listItem {
+	var items []listItem
+	for _, bullet := range list.Bullet {
+		lines := strings.Split(bullet, "\n")
+		texts, depths := []string{lines[0]}, []int{0}
+		for _, line := range lines[1:] {
+			if sub, ok := strings.CutPrefix(line, "- "); ok {
+				texts, depths = append(texts, sub), append(depths, 1)
+			} else {
+				texts[len(texts)-1] += " " + line
+			}
+		}
+		for i, text := range texts {
+			items = append(items, listItem{Fragments: []TextFragment{{Text: c.smarten(text)}}, Depth: depths[i]})
+		}
+	}
+	return items
+}
+
 // renderLink renders a .link directive as a clickable hyperlink
 func (c *Converter) renderLink(link present.Link, y float64) float64 {
 	label := link.Label
@@ -61,13 +118,18 @@ func (c *Converter) renderLink(link present.Link, y float64) float64 {
 	translatedLabel := c.translator(label)
 	labelWidth := c.pdf.GetStringWidth(translatedLabel)
 
-	c.pdf.SetXY(20, y)
-	c.pdf.CellFormat(labelWidth, 11, translatedLabel, "", 0, "L", false, 0, urlStr)
+	x := c.contentX()
+	c.pdf.SetXY(x, y)
+	c.linkCell(labelWidth, 11, translatedLabel, urlStr)
 
 	// Draw underline
 	c.pdf.SetDrawColor(c.theme.LinkColor.R, c.theme.LinkColor.G, c.theme.LinkColor.B)
 	c.pdf.SetLineWidth(0.2)
-	c.pdf.Line(20, y+10, 20+labelWidth, y+10)
+	c.pdf.Line(x, y+10, x+labelWidth, y+10)
+
+	if c.linksAsFootnotes && urlStr != "" && !strings.HasPrefix(urlStr, "#") {
+		c.drawFootnoteMark(c.footnote(urlStr), x+labelWidth, y, 11, 18)
+	}
 
 	// Restore normal text color
 	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
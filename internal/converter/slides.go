@@ -3,91 +3,250 @@ package converter
 import (
 	"bytes"
 	"fmt"
-	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/tools/present"
 )
 
+// minTitleContrast is the lowest acceptable contrast ratio between the title
+// text and a gradient background (WCAG AA for large text)
+const minTitleContrast = 3.0
+
 // renderTitleSlide renders the title page
 func (c *Converter) renderTitleSlide(doc *present.Doc) {
-	c.pdf.AddPage()
+	c.renderCoverSlide(doc.Title, doc.Subtitle, doc.Authors, doc.Time, false)
+}
+
+// renderClosingSlide renders the WithClosingSlide page: the closing text
+// with the authors' contact details, laid out like the title page
+func (c *Converter) renderClosingSlide(doc *present.Doc) {
+	c.renderCoverSlide(c.closingTitle, c.closingSubtitle, doc.Authors, time.Time{}, true)
+}
+
+// coverTitleStep is the distance from the title slide title to the subtitle,
+// laid out for A4 (mm)
+const coverTitleStep = 25.0
+
+// renderCoverSlide renders a page in the title slide style. Empty parts are
+// left out, and without a title the rest moves up to stay centered; with
+// contacts, each author is followed by their links and email addresses.
+func (c *Converter) renderCoverSlide(title, subtitle string, authors []present.Author, date time.Time, contacts bool) {
+	c.beginSlide()
 
 	// Background
-	c.pdf.SetFillColor(c.theme.TitleBackground.R, c.theme.TitleBackground.G, c.theme.TitleBackground.B)
-	c.pdf.Rect(0, 0, 297, 210, "F")
+	if c.gradient != nil {
+		c.fillGradientBackground(c.gradient[0], c.gradient[1])
+		for _, bg := range c.gradient {
+			if contrastRatio(c.theme.TitleText, bg) < minTitleContrast {
+				c.warnf("title text has low contrast against gradient color rgb(%d, %d, %d)", bg.R, bg.G, bg.B)
+				break
+			}
+		}
+	} else {
+		c.fillBackground(c.theme.TitleBackground)
+	}
+	if c.logoOnTitle {
+		c.renderLogo()
+	}
+
+	x, w := c.contentX(), c.contentWidth()
 
 	// Title
-	c.pdf.SetTextColor(c.theme.TitleText.R, c.theme.TitleText.G, c.theme.TitleText.B)
-	c.setTextFont("B", 54)
-	c.pdf.SetXY(20, 70)
-	c.pdf.MultiCell(257, 23, c.translator(doc.Title), "", "C", false)
+	shift := 0.0
+	if strings.TrimSpace(title) == "" {
+		shift = coverTitleStep / 2
+	} else {
+		c.pdf.SetTextColor(c.theme.TitleText.R, c.theme.TitleText.G, c.theme.TitleText.B)
+		c.setHeadingFont("B", 54)
+		c.drawText(x, c.titleSlideY(70), w, 23, c.smarten(title), "C")
+	}
 
 	// Subtitle
-	if doc.Subtitle != "" {
+	if subtitle != "" {
 		c.pdf.SetTextColor(c.theme.TitleSubtext.R, c.theme.TitleSubtext.G, c.theme.TitleSubtext.B)
 		c.setTextFont("", 30)
-		c.pdf.SetXY(20, 95)
-		c.pdf.MultiCell(257, 15, c.translator(doc.Subtitle), "", "C", false)
+		c.drawText(x, c.titleSlideY(95-shift), w, 15, c.smarten(subtitle), "C")
 	}
 
 	// Authors
-	if len(doc.Authors) > 0 {
+	if len(authors) > 0 {
 		c.pdf.SetTextColor(c.theme.TitleSubtext.R, c.theme.TitleSubtext.G, c.theme.TitleSubtext.B)
 		c.setTextFont("", 21)
-		y := 130.0
-		for _, author := range doc.Authors {
+		y := c.titleSlideY(130 - shift)
+		for _, author := range authors {
 			authorText := c.extractAuthorText(author)
 			if authorText != "" {
-				c.pdf.SetXY(20, y)
-				c.pdf.MultiCell(257, 12, c.translator(authorText), "", "C", false)
+				c.drawText(x, y, w, 12, authorText, "C")
 				y += 15
 			}
+			if contacts {
+				y = c.renderAuthorContacts(author, y)
+			}
 		}
 	}
 
 	// Date
-	if !doc.Time.IsZero() {
+	if !date.IsZero() {
 		c.pdf.SetTextColor(c.theme.TitleDate.R, c.theme.TitleDate.G, c.theme.TitleDate.B)
 		c.setTextFont("I", 18)
-		c.pdf.SetXY(20, 180)
-		c.pdf.MultiCell(257, 9, c.translator(doc.Time.Format("January 2, 2006")), "", "C", false)
+		c.drawText(x, c.titleSlideY(180-shift), w, 9, c.formatDate(date), "C")
 	}
+
+	c.renderGenerationStamp(c.theme.TitleSubtext)
+}
+
+// blankTitle is the section title that marks a blank slide: "* .blank"
+const blankTitle = ".blank"
+
+// blankMarkerRe matches the <!-- blank --> marker in a section body
+var blankMarkerRe = regexp.MustCompile(`^\s*<!--\s*blank\s*-->\s*$`)
+
+// isBlankSection reports whether a section is marked as a blank slide, either
+// by the ".blank" title or a <!-- blank --> line in its body
+func isBlankSection(section present.Section) bool {
+	if strings.TrimSpace(section.Title) == blankTitle {
+		return true
+	}
+	for _, elem := range section.Elem {
+		switch e := elem.(type) {
+		case present.Text:
+			for _, line := range e.Lines {
+				if blankMarkerRe.MatchString(line) {
+					return true
+				}
+			}
+		case present.HTML:
+			for _, line := range strings.Split(string(e.HTML), "\n") {
+				if blankMarkerRe.MatchString(line) {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
 
 // renderSlide renders a single slide
 func (c *Converter) renderSlide(section present.Section) {
 	c.currentSlideTitle = section.Title
-	c.pdf.AddPage()
+	c.titleOverflow, c.untitled = 0, false
+	c.beginSlide()
 
 	// Background
-	c.pdf.SetFillColor(c.theme.SlideBackground.R, c.theme.SlideBackground.G, c.theme.SlideBackground.B)
-	c.pdf.Rect(0, 0, 297, 210, "F")
-
-	// Title
-	c.pdf.SetTextColor(c.theme.SlideTitle.R, c.theme.SlideTitle.G, c.theme.SlideTitle.B)
-	c.setTextFont("B", 29)
-	c.pdf.SetXY(20, 15)
-	c.pdf.MultiCell(257, 12, c.translator(section.Title), "", "L", false)
+	c.fillBackground(c.theme.SlideBackground)
+	background := c.backgroundImage(section)
+	c.renderBackgroundImage(background)
 
-	// Draw a line under the title
-	c.pdf.SetDrawColor(c.theme.SlideTitleLine.R, c.theme.SlideTitleLine.G, c.theme.SlideTitleLine.B)
-	c.pdf.SetLineWidth(0.5)
-	c.pdf.Line(20, 36, 277, 36)
+	// A blank slide shows only the background, e.g. for a Q&A pause
+	if isBlankSection(section) {
+		c.debugf("blank slide")
+		return
+	}
+	c.addBookmarks(section)
+	c.renderLogo()
+	c.footnotes = nil
+	c.renderSlideChrome(section.Title)
+	c.slideNotes = c.layoutSlideNotes(section.Notes)
+	defer func() { c.slideNotes = nil }()
+	c.renderSlideNotes()
 
 	// Content
 	c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
-	y := 45.0
+	y := c.contentTop()
+	defer func() { c.renderFootnotes(y) }()
 
-	for _, elem := range section.Elem {
+	defer c.autoFitSlide(section.Elem)()
+
+	if c.continuation {
+		c.continueSlide = func(y float64) float64 { return c.continuePage(section.Title, background, y) }
+		defer func() { c.continueSlide = nil }()
+	}
+
+	defer func() { c.captionBelow = "" }()
+	for i, elem := range section.Elem {
+		c.captionBelow = captionAfter(section.Elem, i)
+
+		// With WithContinuation, an element that would overflow starts a
+		// continuation page
+		if c.continues() && y > c.contentTop() && c.dryRender(elem, y) > c.contentBottom() {
+			y = c.continueSlide(y)
+		}
+		startY := y
 		y = c.renderElement(elem, y)
-		if y > 190 {
-			if !c.quiet {
-				fmt.Fprintf(os.Stderr, "Warning: slide %d \"%s\" does not fit - content overflow (y=%.0f), some elements cut off\n", c.currentSlideNumber, section.Title, y)
+		c.debugf("%s #%d: y %.1f -> %.1f (height %.1f)", elem.TemplateName(), i, startY, y, y-startY)
+		if y > c.contentBottom() {
+			dropped := droppedElements(section.Elem, i+1)
+			msg := fmt.Sprintf("does not fit - content overflow (y=%.0f)", y)
+			if len(dropped) > 0 {
+				msg += ", dropped: " + formatDropped(dropped)
 			}
-			break // Avoid content overflow
+			c.addWarning(Warning{Message: msg, Dropped: dropped})
+			return // Avoid content overflow
 		}
 	}
+
+	c.debugf("content ends at y=%.1f (bottom %.1f)", y, c.contentBottom())
+	c.checkFill(y)
+}
+
+// captionAfter returns the text of the .caption that follows element i of
+// elems, or "" if the next element is not a caption
+func captionAfter(elems []present.Elem, i int) string {
+	if i+1 < len(elems) {
+		if caption, ok := elems[i+1].(present.Caption); ok {
+			return caption.Text
+		}
+	}
+	return ""
+}
+
+// renderSlideChrome draws what every page of a content slide repeats: the
+// title with the line under it, the footer and the slide numbers. An empty
+// title is left out with its line, and the content moves up.
+func (c *Converter) renderSlideChrome(title string) {
+	c.untitled = strings.TrimSpace(title) == ""
+	if !c.untitled {
+		c.pdf.SetTextColor(c.theme.SlideTitle.R, c.theme.SlideTitle.G, c.theme.SlideTitle.B)
+		c.setHeadingFont("B", 29)
+		n := c.drawText(c.contentX(), c.region.Y+titleTop, c.contentWidth(), titleLine, c.smarten(title), c.textAlign())
+
+		// Draw a line under the title; a wrapped title pushes it and the
+		// content down by its extra lines
+		c.titleOverflow = float64(max(n-1, 0)) * titleLine
+		lineY := c.region.Y + titleLineTop + c.titleOverflow
+		c.pdf.SetDrawColor(c.theme.SlideTitleLine.R, c.theme.SlideTitleLine.G, c.theme.SlideTitleLine.B)
+		c.pdf.SetLineWidth(0.5)
+		c.pdf.Line(c.contentX(), lineY, c.contentX()+c.contentWidth(), lineY)
+	}
+
+	c.renderFooter()
+	c.renderPageNumber()
+	c.renderCornerNumber()
+	c.renderNavDots()
+	c.renderGenerationStamp(c.theme.Footer)
+}
+
+// titleLines selects the slide title font and returns the number of lines
+// title wraps to
+func (c *Converter) titleLines(title string) int {
+	c.setHeadingFont("B", 29)
+	n, _ := c.measureText(title, 29, c.contentWidth()-2*c.pdf.GetCellMargin())
+	return n
+}
+
+// checkFill warns when content that fits ends past the WithOverflowWarnThreshold
+// fraction of the content height
+func (c *Converter) checkFill(y float64) {
+	if c.fillThreshold <= 0 {
+		return
+	}
+	height := c.contentBottom() - c.contentTop()
+	used := (y - c.contentTop()) / height
+	if used > c.fillThreshold {
+		c.warnf("nearly full - content uses %.0f%% of the slide (threshold %.0f%%)", used*100, c.fillThreshold*100)
+	}
 }
 
 // renderElement renders a single element
@@ -98,19 +257,82 @@ func (c *Converter) renderElement(elem present.Elem, y float64) float64 {
 	case present.List:
 		return c.renderList(e, y)
 	case present.Code:
-		return c.renderCode(e, y)
+		return c.renderCode(e, "", y)
+	case codeWithLanguage:
+		return c.renderCode(e.Code, e.Language, y)
 	case present.HTML:
 		return c.renderHTML(e, y)
 	case present.Link:
 		return c.renderLink(e, y)
 	case present.Image:
 		return c.renderImage(e, y)
+	case present.Iframe:
+		return c.renderIframe(e, y)
+	case present.Caption:
+		return c.renderCaption(e.Text, y)
+	case present.Section:
+		return c.renderSubsection(e, y)
 	default:
 		// Skip unsupported elements
 		return y
 	}
 }
 
+const (
+	subsectionFontSize = 24.0 // between the 29pt slide title and 21pt body text
+	subsectionLine     = 10.0 // line height of a subsection heading (mm)
+	subsectionGap      = 3.0  // space below a subsection heading (mm)
+	subsectionStep     = 6.0  // indent of a subsection's content per nesting level (mm)
+)
+
+// renderSubsection renders a subsection (### heading) of a slide: its
+// heading, then its elements indented a little, including any deeper
+// subsections. It stops early once the content overflows the slide, leaving
+// the warning to renderSlide.
+func (c *Converter) renderSubsection(section present.Section, y float64) float64 {
+	if title := strings.TrimSpace(section.Title); title != "" {
+		c.pdf.SetTextColor(c.theme.SlideTitle.R, c.theme.SlideTitle.G, c.theme.SlideTitle.B)
+		c.setHeadingFont("B", subsectionFontSize)
+		n := c.drawText(c.contentX(), y, c.contentWidth(), subsectionLine, c.smarten(title), c.textAlign())
+		c.pdf.SetTextColor(c.theme.SlideText.R, c.theme.SlideText.G, c.theme.SlideText.B)
+		y += float64(n)*subsectionLine + subsectionGap
+	}
+
+	c.subsectionIndent += subsectionStep
+	defer func() { c.subsectionIndent -= subsectionStep }()
+	for i, elem := range section.Elem {
+		c.captionBelow = captionAfter(section.Elem, i)
+		startY := y
+		y = c.renderElement(elem, y)
+		c.debugf("  %s #%d in %q: y %.1f -> %.1f", elem.TemplateName(), i, section.Title, startY, y)
+		if y > c.contentBottom() {
+			break
+		}
+	}
+	return y
+}
+
+// renderAuthorContacts draws the links of an author (email, website, ...)
+// centered below each other as clickable labels, and returns the Y below them
+func (c *Converter) renderAuthorContacts(author present.Author, y float64) float64 {
+	c.setTextFont("", 18)
+	c.pdf.SetTextColor(c.theme.LinkColor.R, c.theme.LinkColor.G, c.theme.LinkColor.B)
+	for _, elem := range author.Elem {
+		link, ok := elem.(present.Link)
+		if !ok || link.URL == nil {
+			continue
+		}
+		label := c.translator(link.Label)
+		labelWidth := c.pdf.GetStringWidth(label)
+		c.pdf.SetXY(c.contentX()+(c.contentWidth()-labelWidth)/2, y)
+		c.linkCell(labelWidth, 9, label, link.URL.String())
+		y += 11
+	}
+	c.pdf.SetTextColor(c.theme.TitleSubtext.R, c.theme.TitleSubtext.G, c.theme.TitleSubtext.B)
+	c.setTextFont("", 21)
+	return y
+}
+
 // extractAuthorText extracts text from author element
 func (c *Converter) extractAuthorText(author present.Author) string {
 	var buf bytes.Buffer
@@ -122,3 +344,57 @@ func (c *Converter) extractAuthorText(author present.Author) string {
 	}
 	return strings.TrimSpace(buf.String())
 }
+
+// titleAuthors returns the author lines of the slide header, leaving out
+// authors with only contact details
+func (c *Converter) titleAuthors(doc *present.Doc) []string {
+	var authors []string
+	for _, author := range doc.Authors {
+		if text := c.extractAuthorText(author); text != "" {
+			authors = append(authors, text)
+		}
+	}
+	return authors
+}
+
+// setMetadata fills the PDF document information from the slide header, so
+// viewers show the deck title instead of the file name. The strings are
+// passed as UTF-8 and stored as UTF-16, which keeps Cyrillic intact.
+func (c *Converter) setMetadata(doc *present.Doc) {
+	authors := c.titleAuthors(doc)
+	creator := "present2pdf"
+	if c.toolVersion != "" {
+		creator += " " + c.toolVersion
+	}
+
+	c.pdf.SetTitle(doc.Title, true)
+	c.pdf.SetAuthor(strings.Join(authors, ", "), true)
+	c.pdf.SetSubject(doc.Subtitle, true)
+	c.pdf.SetCreator(creator, true)
+}
+
+// timingSummarySection builds the WithTimingSummary slide: one bullet per
+// timed section and the total
+func timingSummarySection(doc *present.Doc, timings map[int]time.Duration) present.Section {
+	var bullets []string
+	var total time.Duration
+	for i, section := range doc.Sections {
+		d, ok := timings[i]
+		if !ok {
+			continue
+		}
+		total += d
+		bullets = append(bullets, fmt.Sprintf("%s — %s", section.Title, formatDuration(d)))
+	}
+	bullets = append(bullets, "Total — "+formatDuration(total))
+	return present.Section{
+		Title: "Timing",
+		Elem:  []present.Elem{present.List{Bullet: bullets}},
+	}
+}
+
+// formatDuration formats a duration as m:ss
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
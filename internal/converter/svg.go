@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+const (
+	svgDPI        = 150  // resolution SVG images are rasterized at
+	svgUserDPI    = 96   // resolution of SVG user units (CSS pixels)
+	maxSVGPixels  = 4096 // longest side of a rasterized SVG (px)
+	defaultSVGDim = 300  // size of an SVG without a viewBox or size (user units)
+)
+
+// rasterizeSVG renders an SVG file to a PNG temp file at svgDPI (less for
+// very large drawings), keeping its aspect ratio, and returns the PNG path.
+// Elements the renderer does not support, such as text, are left out.
+func (c *Converter) rasterizeSVG(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	icon, err := oksvg.ReadIconStream(f, oksvg.IgnoreErrorMode)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	vw, vh := icon.ViewBox.W, icon.ViewBox.H
+	if vw <= 0 || vh <= 0 {
+		vw, vh = defaultSVGDim, defaultSVGDim
+	}
+	scale := min(float64(svgDPI)/svgUserDPI, maxSVGPixels/max(vw, vh))
+	w, h := int(math.Ceil(vw*scale)), int(math.Ceil(vh*scale))
+	if w <= 0 || h <= 0 {
+		return "", errors.New("empty drawing")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	icon.SetTarget(0, 0, float64(w), float64(h))
+	scanner := rasterx.NewScannerGV(w, h, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(w, h, scanner), 1)
+
+	out, err := c.imageTempFile("svg-*.png")
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	if err := png.Encode(out, img); err != nil {
+		return "", err
+	}
+	c.debugf("SVG %s rasterized to %dx%dpx", path, w, h)
+	return out.Name(), nil
+}
@@ -0,0 +1,116 @@
+package converter
+
+import _ "embed"
+
+// DejaVu Sans Mono provides monochrome glyphs for symbols and emoji that the
+// cp1251 text fonts lack. It is registered as a UTF-8 font, so only the glyphs
+// actually used are embedded.
+//
+//go:embed font/dejavusansmono.z
+var dejavuSansMonoZ []byte
+
+// symbolFontFamily is the family name of the symbol fallback font
+const symbolFontFamily = "Symbols"
+
+// isSymbolRune reports whether r should be drawn with the symbol fallback
+// font: arrows, technical symbols, enclosed numbers, geometric shapes,
+// miscellaneous symbols and dingbats (which include most BMP emoji).
+func isSymbolRune(r rune) bool {
+	switch {
+	case r >= 0x2190 && r <= 0x21FF, // Arrows
+		r >= 0x2300 && r <= 0x23FF, // Miscellaneous Technical
+		r >= 0x2460 && r <= 0x24FF, // Enclosed Alphanumerics
+		r >= 0x25A0 && r <= 0x25FF, // Geometric Shapes
+		r >= 0x2600 && r <= 0x27BF, // Miscellaneous Symbols, Dingbats
+		r >= 0x2B00 && r <= 0x2BFF: // Miscellaneous Symbols and Arrows
+		return true
+	}
+	return false
+}
+
+// textRun is a piece of text drawn with a single font
+type textRun struct {
+	Text string
+	Font string // fallback font family drawing the run, "" for the text font
+}
+
+// splitSymbolRuns splits s into runs of regular text, symbols and emoji.
+// Emoji outside the Basic Multilingual Plane, which gofpdf cannot encode, are
+// replaced with their emoji font icons; those without one are dropped and
+// returned. The invisible variation selectors, joiners and skin tones are
+// dropped silently.
+func splitSymbolRuns(s string) (runs []textRun, dropped []rune) {
+	for _, r := range s {
+		if (r >= 0xFE00 && r <= 0xFE0F) || r == 0x200D || isEmojiModifier(r) {
+			continue
+		}
+		font := ""
+		switch {
+		case r > 0xFFFF:
+			glyph, ok := emojiGlyphs[r]
+			if !ok {
+				dropped = append(dropped, r)
+				continue
+			}
+			r, font = glyph, emojiFontFamily
+		case isSymbolRune(r):
+			font = symbolFontFamily
+		}
+		if n := len(runs); n > 0 && runs[n-1].Font == font {
+			runs[n-1].Text += string(r)
+			continue
+		}
+		runs = append(runs, textRun{Text: string(r), Font: font})
+	}
+	return runs, dropped
+}
+
+// symbolRuns splits s with splitSymbolRuns and warns about each character
+// that cannot be drawn
+func (c *Converter) symbolRuns(s string) []textRun {
+	runs, dropped := splitSymbolRuns(s)
+	for _, r := range dropped {
+		c.warnf("no glyph for %q (U+%04X) in the emoji font, dropped", string(r), r)
+	}
+	return runs
+}
+
+// measureRuns returns the total width of runs. setFont selects the regular
+// font of the surrounding text and is restored before returning.
+func (c *Converter) measureRuns(runs []textRun, setFont func()) float64 {
+	setFont()
+	size, _ := c.pdf.GetFontSize()
+	width := 0.0
+	for _, run := range runs {
+		if run.Font != "" {
+			c.pdf.SetFont(run.Font, "", size)
+			width += c.pdf.GetStringWidth(run.Text)
+			setFont()
+		} else {
+			width += c.pdf.GetStringWidth(c.translator(run.Text))
+		}
+	}
+	return width
+}
+
+// drawRuns draws runs left to right at (x, y) in cells of height h, switching
+// to the symbol and emoji fonts where needed. A non-empty link makes every run clickable.
+func (c *Converter) drawRuns(runs []textRun, x, y, h float64, link string, setFont func()) {
+	setFont()
+	size, _ := c.pdf.GetFontSize()
+	for _, run := range runs {
+		text := run.Text
+		if run.Font != "" {
+			c.pdf.SetFont(run.Font, "", size)
+		} else {
+			text = c.translator(text)
+		}
+		w := c.pdf.GetStringWidth(text)
+		c.pdf.SetXY(x, y)
+		c.linkCell(w, h, text, link)
+		if run.Font != "" {
+			setFont()
+		}
+		x += w
+	}
+}
@@ -0,0 +1,132 @@
+package converter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// wrapText breaks text into lines that fit width in the current font. Lines
+// break at spaces and explicit newlines; a word wider than the line is split
+// between characters. Widths are measured on the encoded text, so wrapping is
+// right for both the cp1251 and the UTF-8 fonts.
+func (c *Converter) wrapText(text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, wrapParagraph(paragraph, width, c.textWidth)...)
+	}
+	return lines
+}
+
+// wrapParagraph wraps a single line of text at word boundaries, measuring
+// with measure
+func wrapParagraph(text string, width float64, measure func(string) float64) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if measure(candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for measure(word) > width {
+			n := fitPrefix(word, width, measure)
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		line = word
+	}
+	return append(lines, line)
+}
+
+// fitPrefix returns the byte length of the longest prefix of s that fits
+// width as measured by measure, but at least one rune so wrapping always
+// makes progress
+func fitPrefix(s string, width float64, measure func(string) float64) int {
+	n := 0
+	for i, r := range s {
+		end := i + utf8.RuneLen(r)
+		if n > 0 && measure(s[:end]) > width {
+			break
+		}
+		n = end
+	}
+	return n
+}
+
+// textLeading is the line height per point of font size (mm) measureText
+// assumes: 9mm lines at 18pt, as in lists
+const textLeading = 0.5
+
+// measureText returns how many lines s wraps to in width at font size (pt)
+// and their height, without drawing. It measures in the active font family
+// and style through the translator, like wrapText; callers with their own
+// line height use lines. The active font size is left unchanged.
+func (c *Converter) measureText(s string, size, width float64) (lines int, height float64) {
+	prev, _ := c.pdf.GetFontSize()
+	c.pdf.SetFontSize(size)
+	defer c.pdf.SetFontSize(prev)
+
+	lines = len(c.wrapText(s, width))
+	return lines, float64(lines) * size * textLeading
+}
+
+// measureFormattedText is measureText for text laid out by
+// renderFormattedText, which keeps room for a space after every word, the
+// last one on a line included
+func (c *Converter) measureFormattedText(s string, size, width float64) (lines int, height float64) {
+	prev, _ := c.pdf.GetFontSize()
+	c.pdf.SetFontSize(size)
+	space := c.textWidth(" ")
+	c.pdf.SetFontSize(prev)
+	return c.measureText(s, size, width-space)
+}
+
+// textWidth measures text in the current font
+func (c *Converter) textWidth(s string) float64 {
+	return c.pdf.GetStringWidth(c.translator(s))
+}
+
+// drawText draws text wrapped to a box of width w at (x, y) with the given
+// line height and alignment ("L", "C" or "R"), and returns the number of
+// lines drawn. It replaces MultiCell, which wraps on encoded bytes and would
+// reorder right-to-left text across line breaks. Symbols are drawn with the
+// symbol font, like in formatted text.
+func (c *Converter) drawText(x, y, w, lineHeight float64, text, align string) int {
+	margin := c.pdf.GetCellMargin()
+	lines := c.wrapText(text, w-2*margin)
+	setFont := c.currentFont()
+	for i, line := range lines {
+		lineY := y + float64(i)*lineHeight
+		runs := c.symbolRuns(c.displayText(line))
+		if len(runs) == 0 {
+			continue
+		}
+		if len(runs) == 1 && runs[0].Font == "" {
+			c.pdf.SetXY(x, lineY)
+			c.pdf.CellFormat(w, lineHeight, c.translator(runs[0].Text), "", 0, align, false, 0, "")
+			continue
+		}
+
+		// drawRuns places text a cell margin right of where it starts
+		runsX := x
+		switch align {
+		case "C":
+			runsX += (w-c.measureRuns(runs, setFont))/2 - margin
+		case "R":
+			runsX += w - 2*margin - c.measureRuns(runs, setFont)
+		}
+		c.drawRuns(runs, runsX, lineY, lineHeight, "", setFont)
+	}
+	return len(lines)
+}
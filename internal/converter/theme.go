@@ -1,6 +1,11 @@
 package converter
 
-import "github.com/alecthomas/chroma/v2/styles"
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/styles"
+)
 
 // RGB represents an RGB color
 type RGB struct {
@@ -36,6 +41,20 @@ type Theme struct {
 	// Inline code colors
 	InlineCodeBackground RGB
 	InlineCodeText       RGB
+
+	// Page area outside the slide region (see WithContentAspect)
+	Letterbox RGB
+
+	// Footer text (see WithFooter)
+	Footer RGB
+
+	// Highlighted text (<mark>)
+	MarkBackground RGB
+	MarkText       RGB
+
+	// Keyboard keys (<kbd>)
+	KbdBackground RGB
+	KbdBorder     RGB
 }
 
 // Predefined themes
@@ -58,6 +77,12 @@ var (
 		BlockquoteBorder:     RGB{41, 128, 185},  // Blue (same as title)
 		InlineCodeBackground: RGB{235, 237, 240}, // Light gray
 		InlineCodeText:       RGB{40, 44, 52},    // Dark (matches code block background)
+		Letterbox:            RGB{30, 30, 30},    // Near black
+		Footer:               RGB{128, 128, 128}, // Gray
+		MarkBackground:       RGB{255, 235, 59},  // Yellow
+		MarkText:             RGB{0, 0, 0},       // Black
+		KbdBackground:        RGB{245, 245, 245}, // Near white
+		KbdBorder:            RGB{160, 160, 160}, // Gray
 	}
 
 	// DarkTheme is a dark theme
@@ -78,6 +103,12 @@ var (
 		BlockquoteBorder:     RGB{137, 180, 250}, // Light blue (same as title)
 		InlineCodeBackground: RGB{48, 52, 72},    // Slightly lighter than slide bg
 		InlineCodeText:       RGB{205, 214, 244}, // Light gray (same as slide text)
+		Letterbox:            RGB{17, 17, 27},    // Darkest blue-gray
+		Footer:               RGB{147, 153, 178}, // Medium gray
+		MarkBackground:       RGB{249, 226, 175}, // Soft yellow
+		MarkText:             RGB{30, 30, 46},    // Dark blue-gray
+		KbdBackground:        RGB{48, 52, 72},    // Slightly lighter than slide bg
+		KbdBorder:            RGB{108, 112, 134}, // Medium gray
 	}
 
 	// availableThemes maps theme names to themes
@@ -87,11 +118,43 @@ var (
 	}
 )
 
+// autoThemeName selects the PDF theme matching the code theme
+const autoThemeName = "auto"
+
+// themeForCodeStyle returns DarkTheme for code styles with a dark background
+// and LightTheme otherwise
+func themeForCodeStyle(name string) Theme {
+	bg := styles.Get(name).Get(chroma.Background).Background
+	if !bg.IsSet() {
+		return LightTheme
+	}
+	col := RGB{int(bg.Red()), int(bg.Green()), int(bg.Blue())}
+	if contrastRatio(col, RGB{255, 255, 255}) > contrastRatio(col, RGB{0, 0, 0}) {
+		return DarkTheme
+	}
+	return LightTheme
+}
+
 // GetAvailableStyles returns a list of available syntax highlighting styles
 func GetAvailableStyles() []string {
 	return styles.Names()
 }
 
+// resolveCodeTheme replaces an unknown code theme by the WithCodeThemeFallback
+// one (or chroma's fallback if that is unknown too), warning about it, so a
+// typo in a theme name does not go unnoticed
+func (c *Converter) resolveCodeTheme() {
+	if _, ok := styles.Registry[strings.ToLower(c.codeTheme)]; ok {
+		return
+	}
+	fallback := c.codeThemeFallback
+	if _, ok := styles.Registry[strings.ToLower(fallback)]; !ok {
+		fallback = styles.Fallback.Name
+	}
+	c.warnf("unknown code theme %q, using %q", c.codeTheme, fallback)
+	c.codeTheme = fallback
+}
+
 // GetAvailableThemes returns a list of available PDF themes
 func GetAvailableThemes() []string {
 	themes := make([]string, 0, len(availableThemes))
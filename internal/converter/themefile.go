@@ -0,0 +1,156 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// optionalThemeFields may be left out of a theme file; they are taken from the
+// light or dark theme, whichever matches the slide background
+var optionalThemeFields = map[string]bool{
+	"Letterbox":      true,
+	"Footer":         true,
+	"MarkBackground": true,
+	"MarkText":       true,
+	"KbdBackground":  true,
+	"KbdBorder":      true,
+}
+
+// LoadTheme reads a theme from a JSON object whose keys are the Theme field
+// names, each color given as "#RRGGBB" or [r, g, b]:
+//
+//	{"TitleBackground": "#2980B9", "SlideText": [0, 0, 0], ...}
+//
+// With "extends" naming a predefined theme, every field is optional and
+// those left out are taken from that theme:
+//
+//	{"extends": "dark", "LinkColor": "#FFB000"}
+//
+// The error lists every missing required field and every unknown key.
+func LoadTheme(r io.Reader) (Theme, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme JSON: %w", err)
+	}
+
+	var extends *Theme
+	if data, ok := raw["extends"]; ok {
+		delete(raw, "extends")
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return Theme{}, fmt.Errorf("theme extends %s: not a theme name", data)
+		}
+		base, ok := availableThemes[strings.ToLower(name)]
+		if !ok {
+			themes := GetAvailableThemes()
+			sort.Strings(themes)
+			return Theme{}, fmt.Errorf("theme extends unknown theme %q (available: %s)", name, strings.Join(themes, ", "))
+		}
+		extends = &base
+	}
+
+	var t Theme
+	if extends != nil {
+		t = *extends
+	}
+	v := reflect.ValueOf(&t).Elem()
+	var missing, unset []string
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		data, ok := raw[name]
+		if !ok {
+			switch {
+			case extends != nil:
+			case optionalThemeFields[name]:
+				unset = append(unset, name)
+			default:
+				missing = append(missing, name)
+			}
+			continue
+		}
+		delete(raw, name)
+		col, err := parseThemeColor(data)
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme field %s: %w", name, err)
+		}
+		v.Field(i).Set(reflect.ValueOf(col))
+	}
+
+	var errs []error
+	if len(raw) > 0 {
+		unknown := make([]string, 0, len(raw))
+		for name := range raw {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		errs = append(errs, fmt.Errorf("unknown theme fields: %s", strings.Join(unknown, ", ")))
+	}
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("missing theme fields: %s", strings.Join(missing, ", ")))
+	}
+	if len(errs) > 0 {
+		return Theme{}, errors.Join(errs...)
+	}
+
+	base := LightTheme
+	if relativeLuminance(t.SlideBackground) < 0.5 {
+		base = DarkTheme
+	}
+	b := reflect.ValueOf(base)
+	for _, name := range unset {
+		v.FieldByName(name).Set(b.FieldByName(name))
+	}
+	return t, nil
+}
+
+// parseThemeColor parses a theme file color: "#RRGGBB" or [r, g, b]
+func parseThemeColor(data json.RawMessage) (RGB, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		hex, ok := strings.CutPrefix(s, "#")
+		if !ok || len(hex) != 6 {
+			return RGB{}, fmt.Errorf("color %q is not #RRGGBB", s)
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return RGB{}, fmt.Errorf("color %q is not #RRGGBB", s)
+		}
+		return RGB{int(n >> 16), int(n >> 8 & 0xFF), int(n & 0xFF)}, nil
+	}
+
+	var rgb []int
+	if err := json.Unmarshal(data, &rgb); err != nil || len(rgb) != 3 {
+		return RGB{}, fmt.Errorf("color %s is neither \"#RRGGBB\" nor [r, g, b]", data)
+	}
+	for _, c := range rgb {
+		if c < 0 || c > 255 {
+			return RGB{}, fmt.Errorf("color %s has a component outside 0-255", data)
+		}
+	}
+	return RGB{rgb[0], rgb[1], rgb[2]}, nil
+}
+
+// loadThemeFile applies the WithThemeFile theme
+func (c *Converter) loadThemeFile() error {
+	if c.themeFile == "" {
+		return nil
+	}
+	f, err := os.Open(c.themeFile)
+	if err != nil {
+		return fmt.Errorf("failed to open theme file: %w", err)
+	}
+	defer f.Close()
+	theme, err := LoadTheme(f)
+	if err != nil {
+		return fmt.Errorf("failed to load theme file %s: %w", c.themeFile, err)
+	}
+	c.theme = theme
+	return nil
+}
@@ -0,0 +1,195 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// ExportThumbnail renders the title slide of a .slide file to a PNG width
+// pixels wide, its height following the page aspect ratio, for link previews
+// and galleries. The slide is drawn with the theme and fonts of a
+// conversion; the logo and the generation stamp are left out.
+func (c *Converter) ExportThumbnail(input, outputPNG string, width int) error {
+	if width <= 0 {
+		return fmt.Errorf("invalid thumbnail width %d", width)
+	}
+	original, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	c.warnings = nil
+	content, err := c.prepareSource(original)
+	if err != nil {
+		return err
+	}
+	doc, err := parseDoc(content, input)
+	if err != nil {
+		return newParseError(original, content, input, err)
+	}
+	c.filterAudience(doc)
+	c.region = computeRegion(c.pageWidth, c.pageHeight, c.contentAspect)
+	date := ""
+	if !doc.Time.IsZero() {
+		date = c.formatDate(doc.Time)
+	}
+
+	scale := float64(width) / c.pageWidth
+	height := max(1, int(math.Round(c.pageHeight*scale)))
+	t := &thumbnail{c: c, img: image.NewRGBA(image.Rect(0, 0, width, height)), scale: scale}
+	if err := t.drawCover(doc.Title, doc.Subtitle, c.titleAuthors(doc), date); err != nil {
+		return err
+	}
+
+	out, err := os.Create(outputPNG)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := png.Encode(out, t.img); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	c.debugf("thumbnail %s written at %dx%dpx", outputPNG, width, height)
+	return nil
+}
+
+// thumbnail draws a page onto an image, with positions in page millimeters
+// as laid out for the PDF
+type thumbnail struct {
+	c     *Converter
+	img   *image.RGBA
+	scale float64 // pixels per millimeter
+}
+
+// drawCover draws the title slide the way renderCoverSlide lays it out
+func (t *thumbnail) drawCover(title, subtitle string, authors []string, date string) error {
+	c := t.c
+	if c.gradient != nil {
+		t.fillBackground(c.gradient[0])
+		top, bottom := t.px(c.region.Y), t.px(c.region.Y+c.region.H)
+		for y := top; y < bottom; y++ {
+			col := blendRGB(c.gradient[0], c.gradient[1], float64(y-top)/float64(max(1, bottom-top-1)))
+			t.fill(image.Rect(t.px(c.region.X), y, t.px(c.region.X+c.region.W), y+1), col)
+		}
+	} else {
+		t.fillBackground(c.theme.TitleBackground)
+	}
+
+	x, w := c.contentX(), c.contentWidth()
+	shift := 0.0
+	if strings.TrimSpace(title) == "" {
+		shift = coverTitleStep / 2
+	} else if err := t.drawText(x, c.titleSlideY(70), w, 23, c.smarten(title), true, "B", 54, c.theme.TitleText); err != nil {
+		return err
+	}
+	if subtitle != "" {
+		if err := t.drawText(x, c.titleSlideY(95-shift), w, 15, c.smarten(subtitle), false, "", 30, c.theme.TitleSubtext); err != nil {
+			return err
+		}
+	}
+	y := c.titleSlideY(130 - shift)
+	for _, author := range authors {
+		if err := t.drawText(x, y, w, 12, author, false, "", 21, c.theme.TitleSubtext); err != nil {
+			return err
+		}
+		y += 15
+	}
+	if date != "" {
+		return t.drawText(x, c.titleSlideY(180-shift), w, 9, date, false, "I", 18, c.theme.TitleDate)
+	}
+	return nil
+}
+
+// px converts a page position in millimeters to pixels
+func (t *thumbnail) px(mm float64) int {
+	return int(math.Round(mm * t.scale))
+}
+
+// fill paints r in col
+func (t *thumbnail) fill(r image.Rectangle, col RGB) {
+	src := image.NewUniform(color.RGBA{uint8(col.R), uint8(col.G), uint8(col.B), 0xff})
+	draw.Draw(t.img, r, src, image.Point{}, draw.Src)
+}
+
+// fillBackground paints the page like Converter.fillBackground: letterbox
+// margins in the theme's letterbox color and the region in bg
+func (t *thumbnail) fillBackground(bg RGB) {
+	c := t.c
+	t.fill(t.img.Bounds(), c.theme.Letterbox)
+	t.fill(image.Rect(t.px(c.region.X), t.px(c.region.Y), t.px(c.region.X+c.region.W), t.px(c.region.Y+c.region.H)), bg)
+}
+
+// drawText draws text centered in lines of lineHeight like
+// Converter.drawText, wrapped to width w less the cell margins, in the
+// heading or text font with the given style and size (pt)
+func (t *thumbnail) drawText(x, y, w, lineHeight float64, text string, heading bool, style string, size float64, col RGB) error {
+	face, err := t.face(heading, style, size)
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+
+	d := font.Drawer{
+		Dst:  t.img,
+		Src:  image.NewUniform(color.RGBA{uint8(col.R), uint8(col.G), uint8(col.B), 0xff}),
+		Face: face,
+	}
+	width := func(s string) float64 { return float64(d.MeasureString(s)) / 64 / t.scale }
+	for i, line := range wrapParagraph(text, w-2*thumbnailCellMargin, width) {
+		// gofpdf puts the baseline of a cell 0.3 of the font size below
+		// its middle
+		baseline := y + float64(i)*lineHeight + lineHeight/2 + 0.3*size*mmPerPt
+		left := x + (w-width(line))/2
+		d.Dot = fixed.Point26_6{X: fixed.Int26_6(left * t.scale * 64), Y: fixed.Int26_6(baseline * t.scale * 64)}
+		d.DrawString(line)
+	}
+	return nil
+}
+
+const (
+	mmPerPt             = 25.4 / 72 // millimeters per point
+	thumbnailCellMargin = 1.0       // gofpdf's default cell margin (mm)
+)
+
+// face returns the heading or text font with the given style, at size
+// points on the thumbnail's scale, choosing fonts as setHeadingFont and
+// setTextFont do
+func (t *thumbnail) face(heading bool, style string, size float64) (font.Face, error) {
+	c := t.c
+	var ttf []byte
+	switch {
+	case heading && c.headingTTF != nil:
+		ttf = c.headingTTF
+	case c.bodyTTF != nil:
+		ttf = c.bodyTTF
+	default:
+		if !c.realTextStyles() {
+			style = ""
+		}
+		name := textFonts[c.bodyFont][style]
+		z, err := fontFS.ReadFile("font/" + name + ".z")
+		if err == nil {
+			ttf, err = inflateFont(z)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load font %s: %w", name, err)
+		}
+	}
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load font: %w", err)
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 25.4 * t.scale, Hinting: font.HintingNone})
+}